@@ -29,7 +29,16 @@ const (
 	NephioIPAllocactionNameKey  = "nephio.org/allocation-name"
 	NephioPoolKey               = "nephio.org/pool"
 	NephioGatewayKey            = "nephio.org/gateway"
+	NephioGatewayRoleKey        = "nephio.org/gateway-role"
 	NephioInterfaceKey          = "nephio.org/interface"
+	NephioNodeKey               = "nephio.org/node"
+	NephioPriorityKey           = "nephio.org/priority"
+	NephioDynamicKey            = "nephio.org/dynamic"
+	NephioSourceNIKey           = "nephio.org/source-network-instance"
+	NephioImportedFromKey       = "nephio.org/imported-from"
+	NephioNamespaceKey          = "nephio.org/namespace"
+	NephioClientKey             = "nephio.org/client"
 	NephioApplicationPartOfKey  = "app.kubernetes.io/part-of"
 	NephioOriginKey             = "nephio.org/origin"
+	NephioLeaseExpiryKey        = "nephio.org/lease-expiry"
 )
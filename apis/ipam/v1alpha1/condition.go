@@ -42,6 +42,34 @@ const (
 	ConditionReasonReady   ConditionReason = "Ready"
 	ConditionReasonFailed  ConditionReason = "Failed"
 	ConditionReasonUnknown ConditionReason = "Unknown"
+	// ConditionReasonQuotaExceeded indicates the allocation exceeds a quota
+	ConditionReasonQuotaExceeded ConditionReason = "QuotaExceeded"
+	// ConditionReasonTerminating indicates the network instance waits for its
+	// prefixes and allocations to be deleted
+	ConditionReasonTerminating ConditionReason = "Terminating"
+	// ConditionReasonNotReady indicates the network instance of the resource
+	// is terminating or the resource pool of the resource is deleted
+	ConditionReasonNotReady ConditionReason = "NotReady"
+	// ConditionReasonValidationFailed indicates the allocation is not valid
+	ConditionReasonValidationFailed ConditionReason = "ValidationFailed"
+	// ConditionReasonPoolExhausted indicates no prefix is free for the
+	// allocation
+	ConditionReasonPoolExhausted ConditionReason = "PoolExhausted"
+	// ConditionReasonOverlap indicates the allocation overlaps with another
+	// prefix
+	ConditionReasonOverlap ConditionReason = "Overlap"
+	// ConditionReasonInvalidNesting indicates the allocation violates the
+	// nesting rules
+	ConditionReasonInvalidNesting ConditionReason = "InvalidNesting"
+	// ConditionReasonNetworkInstanceNotReady indicates the network instance of
+	// the allocation is not ready
+	ConditionReasonNetworkInstanceNotReady ConditionReason = "NetworkInstanceNotReady"
+	// ConditionReasonNotFound indicates the allocation or its parent prefix
+	// does not exist
+	ConditionReasonNotFound ConditionReason = "NotFound"
+	// ConditionReasonConflict indicates the allocation conflicts with an
+	// existing allocation
+	ConditionReasonConflict ConditionReason = "Conflict"
 )
 
 // Reasons a resource is or is not synced.
@@ -202,6 +230,54 @@ func Failed(msg string) Condition {
 	}
 }
 
+// QuotaExceeded returns a condition that indicates the resource
+// failed to get instantiated since it exceeds a quota.
+func QuotaExceeded(msg string) Condition {
+	return Condition{
+		Kind:               ConditionKindReady,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonQuotaExceeded,
+		Message:            msg,
+	}
+}
+
+// FailedWithReason returns a condition that indicates the resource
+// failed to get instantiated for the supplied reason.
+func FailedWithReason(reason ConditionReason, msg string) Condition {
+	return Condition{
+		Kind:               ConditionKindReady,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            msg,
+	}
+}
+
+// Terminating returns a condition that indicates the network instance
+// is being deleted and waits for its prefixes and allocations.
+func Terminating(msg string) Condition {
+	return Condition{
+		Kind:               ConditionKindReady,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonTerminating,
+		Message:            msg,
+	}
+}
+
+// NotReady returns a condition that indicates the network instance
+// of the resource is terminating or its resource pool is deleted.
+func NotReady(msg string) Condition {
+	return Condition{
+		Kind:               ConditionKindReady,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonNotReady,
+		Message:            msg,
+	}
+}
+
 // ReconcileSuccess returns a condition indicating that ndd successfully
 // completed the most recent reconciliation of the resource.
 func ReconcileSuccess() Condition {
@@ -25,7 +25,8 @@ import (
 
 // IPAllocationSpec defines the desired state of IPAllocation
 type IPAllocationSpec struct {
-	// +kubebuilder:validation:Enum=`network`;`loopback`;`pool`;`aggregate`
+	// PrefixKind is network, loopback, pool, aggregate or a prefix kind registered with the ipam
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
 	// +kubebuilder:default=network
 	PrefixKind string `json:"kind"`
 	// +kubebuilder:validation:Enum=`ipv4`;`ipv6`
@@ -37,6 +38,13 @@ type IPAllocationSpec struct {
 	PrefixLength uint8 `json:"prefixLength,omitempty"`
 	// Label selector for selecting the context from which the IP prefix/address gets allocated
 	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Endpoints identify both sides of a link, only relevant for prefix kind link
+	// +kubebuilder:validation:MaxItems=2
+	Endpoints []LinkEndpoint `json:"endpoints,omitempty"`
+	// LeaseDuration identifies how long the allocation is held after it was last allocated, the allocation
+	// is renewed every time it is reconciled and released when the lease expires; without a lease duration
+	// the allocation is held until the IPAllocation is deleted
+	LeaseDuration *metav1.Duration `json:"leaseDuration,omitempty"`
 }
 
 // IPAllocationStatus defines the observed state of IPAllocation
@@ -44,8 +52,37 @@ type IPAllocationStatus struct {
 	ConditionedStatus `json:",inline"`
 	// AllocatedPrefix identifies the prefix that was allocated by the IPAM system
 	AllocatedPrefix string `json:"prefix,omitempty"`
-	// Gateway identifies the gatway IP for the network
+	// ParentPrefix identifies the prefix the allocated prefix was allocated from
+	ParentPrefix string `json:"parentPrefix,omitempty"`
+	// Address identifies the address of the allocated prefix without prefix length
+	Address string `json:"address,omitempty"`
+	// PrefixLength identifies the prefix length of the allocated prefix
+	PrefixLength uint8 `json:"prefixLength,omitempty"`
+	// AddressFamily identifies the address family of the allocated prefix
+	AddressFamily string `json:"addressFamily,omitempty"`
+	// PrefixKind identifies the prefix kind of the allocation
+	PrefixKind string `json:"kind,omitempty"`
+	// NetworkInstance identifies the network instance the prefix is allocated in
+	NetworkInstance string `json:"networkInstance,omitempty"`
+	// NetworkName identifies the network the prefix is allocated in, only relevant for prefix kind network
+	NetworkName string `json:"networkName,omitempty"`
+	// LeaseExpiry identifies when the lease of the allocation expires, only set when the allocation has a lease duration
+	LeaseExpiry *metav1.Time `json:"leaseExpiry,omitempty"`
+	// ObservedGeneration identifies the generation of the spec the status reflects
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Gateway identifies the gatway IP for the network, the virtual gateway
+	// if the network has one, else the primary or secondary gateway
 	Gateway string `json:"gateway,omitempty"`
+	// Gateways identifies all gateways of the network in the address family of the allocation
+	Gateways []Gateway `json:"gateways,omitempty"`
+	// RouteDistinguisher identifies the route distinguisher of the network instance
+	RouteDistinguisher string `json:"routeDistinguisher,omitempty"`
+	// ImportRouteTargets identifies the route targets imported by the network instance
+	ImportRouteTargets []string `json:"importRouteTargets,omitempty"`
+	// ExportRouteTargets identifies the route targets exported by the network instance
+	ExportRouteTargets []string `json:"exportRouteTargets,omitempty"`
+	// Endpoints identify the addresses allocated to both sides of a link
+	Endpoints []AllocatedLinkEndpoint `json:"endpoints,omitempty"`
 }
 
 // +kubebuilder:object:root=true
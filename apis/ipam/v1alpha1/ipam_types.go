@@ -49,4 +49,63 @@ const (
 	PrefixKindLoopback  PrefixKind = "loopback"
 	PrefixKindPool      PrefixKind = "pool"
 	PrefixKindAggregate PrefixKind = "aggregate"
+	// PrefixKindLink is a point-to-point link (/31, /127) with an address per endpoint
+	PrefixKindLink PrefixKind = "link"
+)
+
+// AllocationStrategy defines how a dynamic allocation selects the free block
+// it is allocated from
+type AllocationStrategy string
+
+const (
+	// AllocationStrategyFirstFit allocates from the first prefix with a free
+	// block that fits
+	AllocationStrategyFirstFit AllocationStrategy = "first-fit"
+	// AllocationStrategyBestFit allocates from the smallest free block that fits
+	// across the prefixes, which keeps large blocks available
+	AllocationStrategyBestFit AllocationStrategy = "best-fit"
+)
+
+// GatewayRole is the role of a gateway in a network
+type GatewayRole string
+
+const (
+	// GatewayRoleVirtual is a virtual router address, e.g. VRRP, shared by the routers of the network
+	GatewayRoleVirtual GatewayRole = "virtual"
+	// GatewayRolePrimary is the address of the primary router of the network
+	GatewayRolePrimary GatewayRole = "primary"
+	// GatewayRoleSecondary is the address of the secondary router of the network
+	GatewayRoleSecondary GatewayRole = "secondary"
+)
+
+// Gateway is a gateway address of a network with its role
+type Gateway struct {
+	// Address of the gateway
+	Address string `json:"address"`
+	// Role of the gateway: virtual, primary or secondary
+	Role GatewayRole `json:"role,omitempty"`
+}
+
+// LinkEndpoint identifies one side of a point-to-point link
+type LinkEndpoint struct {
+	// Node the endpoint belongs to
+	Node string `json:"node"`
+	// Interface of the node the endpoint belongs to
+	Interface string `json:"interface"`
+}
+
+// AllocatedLinkEndpoint is a link endpoint with the address allocated to it
+type AllocatedLinkEndpoint struct {
+	LinkEndpoint `json:",inline"`
+	// Address allocated to the endpoint
+	Address string `json:"address,omitempty"`
+}
+
+// ResourceKind is the kind of the integer resources in a resource pool
+type ResourceKind string
+
+const (
+	ResourceKindVLAN ResourceKind = "vlan"
+	ResourceKindVNI  ResourceKind = "vni"
+	ResourceKindASN  ResourceKind = "asn"
 )
@@ -17,12 +17,26 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"reflect"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+// IPPrefixCascadeDeleteAnnotation releases the prefixes and allocations nested
+// in the prefix when the IPPrefix is deleted, without it the deletion of an
+// IPPrefix is blocked as long as prefixes or allocations are nested in it
+const IPPrefixCascadeDeleteAnnotation = "ipam.nephio.org/cascade-delete"
+
+// IPPrefixMigrateAllocationsAnnotation releases the dynamic allocations the
+// prefix no longer covers after a change of the prefix, such that they are
+// allocated again in the prefixes they select
+const IPPrefixMigrateAllocationsAnnotation = "ipam.nephio.org/migrate-allocations"
+
 // IPPrefixSpec defines the desired state of IPPrefix
 type IPPrefixSpec struct {
-	// +kubebuilder:validation:Enum=`network`;`loopback`;`pool`;`aggregate`
+	// PrefixKind is network, loopback, pool, aggregate or a prefix kind registered with the ipam
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
 	// +kubebuilder:default=network
 	PrefixKind string `json:"kind"`
 	// Network is only relevant for prefix kind network. it is the unique name to reference all prefixes together within a network
@@ -41,6 +55,25 @@ type IPPrefixStatus struct {
 	AllocatedPrefix string `json:"prefix,omitempty"`
 	// AllocatedNetwork identifies the network that was allocated by the IPAM system
 	AllocatedNetwork string `json:"network,omitempty"`
+	// Dependents identify the prefixes and allocations nested in the prefix
+	// that block the deletion of the IPPrefix
+	Dependents []PrefixDependent `json:"dependents,omitempty"`
+	// Allocations identify the prefixes and allocations nested in the prefix
+	Allocations []PrefixDependent `json:"allocations,omitempty"`
+}
+
+// PrefixDependent is a prefix or allocation nested in a prefix
+type PrefixDependent struct {
+	// Name of the prefix or allocation
+	Name string `json:"name"`
+	// Namespace of the prefix or allocation
+	Namespace string `json:"namespace,omitempty"`
+	// Prefix that is allocated
+	Prefix string `json:"prefix"`
+	// PrefixKind of the prefix
+	PrefixKind string `json:"kind,omitempty"`
+	// NetworkInstance the prefix is allocated in
+	NetworkInstance string `json:"networkInstance,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -76,3 +109,10 @@ type IPPrefixList struct {
 func init() {
 	SchemeBuilder.Register(&IPPrefix{}, &IPPrefixList{})
 }
+
+var (
+	IPPrefixKind             = reflect.TypeOf(IPPrefix{}).Name()
+	IPPrefixGroupKind        = schema.GroupKind{Group: GroupVersion.Group, Kind: IPPrefixKind}.String()
+	IPPrefixKindAPIVersion   = IPPrefixKind + "." + GroupVersion.String()
+	IPPrefixGroupVersionKind = GroupVersion.WithKind(IPPrefixKind)
+)
@@ -0,0 +1,110 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// QuotaTenantKind defines what identifies the tenant an allocation is counted for
+type QuotaTenantKind string
+
+const (
+	// QuotaTenantKindNamespace counts the allocations per namespace
+	QuotaTenantKindNamespace QuotaTenantKind = "namespace"
+	// QuotaTenantKindLabel counts the allocations per value of a label
+	QuotaTenantKindLabel QuotaTenantKind = "label"
+	// QuotaTenantKindClient counts the allocations per gRPC client
+	QuotaTenantKindClient QuotaTenantKind = "client"
+)
+
+// IPQuotaSpec defines the desired state of IPQuota
+type IPQuotaSpec struct {
+	// NetworkInstance the quota applies to
+	NetworkInstance string `json:"networkInstance"`
+	// Prefix limits the quota to the allocations nested in the prefix, e.g. a pool,
+	// all allocations of the network instance are counted when not set
+	Prefix string `json:"prefix,omitempty"`
+	// TenantKind identifies what the allocations are counted per
+	// +kubebuilder:validation:Enum=`namespace`;`label`;`client`
+	TenantKind QuotaTenantKind `json:"tenantKind"`
+	// LabelKey is the label that identifies the tenant when the tenant kind is label,
+	// e.g. app.kubernetes.io/part-of
+	LabelKey string `json:"labelKey,omitempty"`
+	// Tenant limits the quota to a single tenant, every tenant gets the quota when not set
+	Tenant string `json:"tenant,omitempty"`
+	// MaxAllocations is the number of prefixes and addresses a tenant can hold
+	// +kubebuilder:validation:Minimum=0
+	MaxAllocations int32 `json:"maxAllocations"`
+}
+
+// IPQuotaStatus defines the observed state of IPQuota
+type IPQuotaStatus struct {
+	ConditionedStatus `json:",inline"`
+	// Usage identifies the number of allocations per tenant
+	Usage []QuotaUsage `json:"usage,omitempty"`
+}
+
+// QuotaUsage is the number of allocations a tenant holds
+type QuotaUsage struct {
+	// Tenant holding the allocations
+	Tenant string `json:"tenant"`
+	// Allocations is the number of prefixes and addresses the tenant holds
+	Allocations int32 `json:"allocations"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="SYNC",type="string",JSONPath=".status.conditions[?(@.kind=='Synced')].status"
+// +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".status.conditions[?(@.kind=='Ready')].status"
+// +kubebuilder:printcolumn:name="NETWORK-INSTANCE",type="string",JSONPath=".spec.networkInstance"
+// +kubebuilder:printcolumn:name="TENANT-KIND",type="string",JSONPath=".spec.tenantKind"
+// +kubebuilder:printcolumn:name="MAX",type="integer",JSONPath=".spec.maxAllocations"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:categories={nephio,ipam}
+
+// IPQuota is the Schema for the ipquotas API
+type IPQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IPQuotaSpec   `json:"spec,omitempty"`
+	Status IPQuotaStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// IPQuotaList contains a list of IPQuota
+type IPQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IPQuota `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IPQuota{}, &IPQuotaList{})
+}
+
+var (
+	IPQuotaKind             = reflect.TypeOf(IPQuota{}).Name()
+	IPQuotaGroupKind        = schema.GroupKind{Group: GroupVersion.Group, Kind: IPQuotaKind}.String()
+	IPQuotaKindAPIVersion   = IPQuotaKind + "." + GroupVersion.String()
+	IPQuotaGroupVersionKind = GroupVersion.WithKind(IPQuotaKind)
+)
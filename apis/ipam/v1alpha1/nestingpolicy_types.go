@@ -0,0 +1,98 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// NestingPolicyDefaultName is the name of the nesting policy the ipam applies,
+// nesting policies with another name are not applied
+const NestingPolicyDefaultName = "default"
+
+// NestingPolicySpec defines the desired state of NestingPolicy
+type NestingPolicySpec struct {
+	// Rules define the nesting rules per prefix kind, prefix kinds without a rule
+	// use the default rules
+	Rules []NestingRule `json:"rules,omitempty"`
+}
+
+// NestingRule defines how prefixes of a prefix kind can be nested
+type NestingRule struct {
+	// PrefixKind the rule applies to
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	PrefixKind string `json:"kind"`
+	// ParentKinds are the prefix kinds a prefix of this kind can be nested in
+	ParentKinds []string `json:"parentKinds,omitempty"`
+	// ChildKinds are the prefix kinds that can be nested in a prefix of this kind
+	ChildKinds []string `json:"childKinds,omitempty"`
+	// AllowAddress allows a prefix of this kind to be an address (/32, /128)
+	AllowAddress bool `json:"allowAddress,omitempty"`
+	// AllowAddressInNet allows a prefix of this kind to have an address that differs
+	// from its net, e.g. 10.0.0.1/24
+	AllowAddressInNet bool `json:"allowAddressInNet,omitempty"`
+	// NestedAddressOnly restricts a prefix of this kind that is nested in a prefix
+	// of the same kind to an address (/32, /128)
+	NestedAddressOnly bool `json:"nestedAddressOnly,omitempty"`
+	// MaxNestingDepth limits the number of prefixes of the same kind a prefix of
+	// this kind can be nested in, 0 means no limit
+	MaxNestingDepth uint32 `json:"maxNestingDepth,omitempty"`
+}
+
+// NestingPolicyStatus defines the observed state of NestingPolicy
+type NestingPolicyStatus struct {
+	ConditionedStatus `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="SYNC",type="string",JSONPath=".status.conditions[?(@.kind=='Synced')].status"
+// +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".status.conditions[?(@.kind=='Ready')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories={nephio,ipam}
+
+// NestingPolicy is the Schema for the nestingpolicies API
+type NestingPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NestingPolicySpec   `json:"spec,omitempty"`
+	Status NestingPolicyStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NestingPolicyList contains a list of NestingPolicy
+type NestingPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NestingPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NestingPolicy{}, &NestingPolicyList{})
+}
+
+var (
+	NestingPolicyKind             = reflect.TypeOf(NestingPolicy{}).Name()
+	NestingPolicyGroupKind        = schema.GroupKind{Group: GroupVersion.Group, Kind: NestingPolicyKind}.String()
+	NestingPolicyKindAPIVersion   = NestingPolicyKind + "." + GroupVersion.String()
+	NestingPolicyGroupVersionKind = GroupVersion.WithKind(NestingPolicyKind)
+)
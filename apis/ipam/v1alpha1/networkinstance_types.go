@@ -21,8 +21,69 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 )
 
+// NetworkInstanceForceDeleteAnnotation deletes the network instance without
+// waiting for its IPPrefix and IPAllocation resources to be deleted
+const NetworkInstanceForceDeleteAnnotation = "ipam.nephio.org/force-delete"
+
 // NetworkInstanceSpec defines the desired state of NetworkInstance
 type NetworkInstanceSpec struct {
+	// Description of the network instance
+	Description string `json:"description,omitempty"`
+	// AddressFamilies identifies the address families the network instance allows, all when not set
+	AddressFamilies []AddressFamily `json:"addressFamilies,omitempty"`
+	// DefaultPrefixLengths identify the prefix length of dynamic allocations that do not request one
+	DefaultPrefixLengths []DefaultPrefixLength `json:"defaultPrefixLengths,omitempty"`
+	// AllocationStrategy selects the free block a dynamic allocation is allocated from, the strategy
+	// of the ipam when not set
+	// +kubebuilder:validation:Enum=`first-fit`;`best-fit`
+	AllocationStrategy AllocationStrategy `json:"allocationStrategy,omitempty"`
+	// MaxAllocations is the maximum number of prefixes and allocations in the network instance, unlimited when not set
+	// +kubebuilder:validation:Minimum=0
+	MaxAllocations *int32 `json:"maxAllocations,omitempty"`
+	// RouteDistinguisher identifies the pool the route distinguisher of the network instance is allocated from
+	RouteDistinguisher *VPNIdentifierPool `json:"routeDistinguisher,omitempty"`
+	// RouteTarget identifies the pool the import and the export route target of the network instance are allocated from, each separately
+	RouteTarget *VPNIdentifierPool `json:"routeTarget,omitempty"`
+	// Exports select the prefixes of the network instance that other network instances can import
+	Exports []metav1.LabelSelector `json:"exports,omitempty"`
+	// Imports identify the prefixes that are imported from other network instances
+	Imports []PrefixImport `json:"imports,omitempty"`
+}
+
+// DefaultPrefixLength defines the prefix length of the dynamic allocations of
+// a prefix kind and address family
+type DefaultPrefixLength struct {
+	// PrefixKind the prefix length applies to, loopback, pool or a prefix kind registered with the ipam
+	PrefixKind PrefixKind `json:"kind"`
+	// AddressFamily the prefix length applies to
+	// +kubebuilder:validation:Enum=`ipv4`;`ipv6`
+	AddressFamily AddressFamily `json:"addressFamily"`
+	// PrefixLength of the dynamic allocations
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=128
+	PrefixLength uint8 `json:"prefixLength"`
+}
+
+// PrefixImport imports the exported prefixes of another network instance
+type PrefixImport struct {
+	// NetworkInstance the prefixes are imported from
+	NetworkInstance string `json:"networkInstance"`
+	// Selector selects the exported prefixes that are imported, all exported prefixes when not set
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// VPNIdentifierPool defines a pool of route distinguishers or route targets
+// of the form <administrator>:<assigned number>
+type VPNIdentifierPool struct {
+	// Administrator is the administrator subfield, either an ASN or an IPv4 address
+	// +kubebuilder:validation:Pattern=`^([0-9]+|(([0-9]|[1-9][0-9]|1[0-9][0-9]|2[0-4][0-9]|25[0-5])\.){3}([0-9]|[1-9][0-9]|1[0-9][0-9]|2[0-4][0-9]|25[0-5]))$`
+	Administrator string `json:"administrator"`
+	// Start is the first assigned number of the pool
+	// +kubebuilder:validation:Minimum=1
+	Start uint32 `json:"start"`
+	// End is the last assigned number of the pool
+	// +kubebuilder:validation:Minimum=1
+	End uint32 `json:"end"`
 }
 
 // NetworkInstanceStatus defines the observed state of NetworkInstance
@@ -30,12 +91,48 @@ type NetworkInstanceStatus struct {
 	ConditionedStatus `json:",inline"`
 	// Allocations list the available alocations
 	Allocations map[string]labels.Set `json:"allocations,omitempty"`
+	// RouteDistinguisher identifies the route distinguisher allocated to the network instance
+	RouteDistinguisher string `json:"routeDistinguisher,omitempty"`
+	// ImportRouteTargets identifies the route targets imported by the network instance
+	ImportRouteTargets []string `json:"importRouteTargets,omitempty"`
+	// ExportRouteTargets identifies the route targets exported by the network instance
+	ExportRouteTargets []string `json:"exportRouteTargets,omitempty"`
+	// ImportedPrefixes identifies the prefixes imported from other network instances
+	ImportedPrefixes []ImportedPrefix `json:"importedPrefixes,omitempty"`
+	// Fragmentation identifies the free space of the aggregate and pool prefixes of the network instance
+	Fragmentation []PrefixFragmentation `json:"fragmentation,omitempty"`
+	// Dependents identifies the IPPrefix and IPAllocation resources, as kind/name, that
+	// the deletion of the network instance waits for
+	Dependents []string `json:"dependents,omitempty"`
+}
+
+// ImportedPrefix is a prefix imported from another network instance
+type ImportedPrefix struct {
+	// Prefix that is imported
+	Prefix string `json:"prefix"`
+	// NetworkInstance that owns the prefix
+	NetworkInstance string `json:"networkInstance"`
+}
+
+// PrefixFragmentation reports the free space of an aggregate or pool prefix
+type PrefixFragmentation struct {
+	// Prefix of the aggregate or pool
+	Prefix string `json:"prefix"`
+	// PrefixKind of the prefix
+	PrefixKind string `json:"kind"`
+	// LargestFreePrefixLength is the prefix length of the largest block that can
+	// be allocated from the prefix, only set when the prefix has free space
+	LargestFreePrefixLength uint8 `json:"largestFreePrefixLength,omitempty"`
+	// FreeBlocks is the number of free blocks of the prefix, the more blocks
+	// the more fragmented the prefix is
+	FreeBlocks int `json:"freeBlocks"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="SYNC",type="string",JSONPath=".status.conditions[?(@.kind=='Synced')].status"
 // +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".status.conditions[?(@.kind=='Ready')].status"
+// +kubebuilder:printcolumn:name="RD",type="string",JSONPath=".status.routeDistinguisher"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:resource:categories={nephio,ipam}
 // NetworkInstance is the Schema for the networkinstances API
@@ -0,0 +1,36 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import "k8s.io/apimachinery/pkg/types"
+
+// GetCondition of this resource
+func (x *ResourceClaim) GetCondition(ck ConditionKind) Condition {
+	return x.Status.GetCondition(ck)
+}
+
+// SetConditions of the ResourceClaim.
+func (x *ResourceClaim) SetConditions(c ...Condition) {
+	x.Status.SetConditions(c...)
+}
+
+func (x *ResourceClaim) GetNamespacedName() types.NamespacedName {
+	return types.NamespacedName{
+		Name:      x.Name,
+		Namespace: x.Namespace,
+	}
+}
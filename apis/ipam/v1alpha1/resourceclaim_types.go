@@ -0,0 +1,85 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ResourceClaimSpec defines the desired state of ResourceClaim
+type ResourceClaimSpec struct {
+	// Kind of the resource that is claimed
+	// +kubebuilder:validation:Enum=`vlan`;`vni`;`asn`
+	Kind string `json:"kind"`
+	// ID allows the client to claim a specific id, when not specified the
+	// first free id of a selected pool is allocated
+	ID uint32 `json:"id,omitempty"`
+	// Label selector for selecting the pools from which the id gets allocated
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// ResourceClaimStatus defines the observed state of ResourceClaim
+type ResourceClaimStatus struct {
+	ConditionedStatus `json:",inline"`
+	// AllocatedID identifies the id that was allocated by the IPAM system
+	AllocatedID uint32 `json:"id,omitempty"`
+	// Pool identifies the pool the id was allocated from
+	Pool string `json:"pool,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="SYNC",type="string",JSONPath=".status.conditions[?(@.kind=='Synced')].status"
+// +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".status.conditions[?(@.kind=='Ready')].status"
+// +kubebuilder:printcolumn:name="KIND",type="string",JSONPath=".spec.kind"
+// +kubebuilder:printcolumn:name="ID-REQ",type="integer",JSONPath=".spec.id"
+// +kubebuilder:printcolumn:name="ID-ALLOC",type="integer",JSONPath=".status.id"
+// +kubebuilder:printcolumn:name="POOL",type="string",JSONPath=".status.pool"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:categories={nephio,ipam}
+
+// ResourceClaim is the Schema for the resourceclaims API
+type ResourceClaim struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ResourceClaimSpec   `json:"spec,omitempty"`
+	Status ResourceClaimStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ResourceClaimList contains a list of ResourceClaim
+type ResourceClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ResourceClaim `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ResourceClaim{}, &ResourceClaimList{})
+}
+
+var (
+	ResourceClaimKind             = reflect.TypeOf(ResourceClaim{}).Name()
+	ResourceClaimGroupKind        = schema.GroupKind{Group: GroupVersion.Group, Kind: ResourceClaimKind}.String()
+	ResourceClaimKindAPIVersion   = ResourceClaimKind + "." + GroupVersion.String()
+	ResourceClaimGroupVersionKind = GroupVersion.WithKind(ResourceClaimKind)
+)
@@ -0,0 +1,81 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ResourcePoolSpec defines the desired state of ResourcePool
+type ResourcePoolSpec struct {
+	// Kind of the resources in the pool
+	// +kubebuilder:validation:Enum=`vlan`;`vni`;`asn`
+	Kind string `json:"kind"`
+	// Start is the first id of the pool
+	Start uint32 `json:"start"`
+	// End is the last id of the pool
+	End uint32 `json:"end"`
+}
+
+// ResourcePoolStatus defines the observed state of ResourcePool
+type ResourcePoolStatus struct {
+	ConditionedStatus `json:",inline"`
+	// Allocations maps the allocated ids to the claims they are allocated to
+	Allocations map[string]string `json:"allocations,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="SYNC",type="string",JSONPath=".status.conditions[?(@.kind=='Synced')].status"
+// +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".status.conditions[?(@.kind=='Ready')].status"
+// +kubebuilder:printcolumn:name="KIND",type="string",JSONPath=".spec.kind"
+// +kubebuilder:printcolumn:name="START",type="integer",JSONPath=".spec.start"
+// +kubebuilder:printcolumn:name="END",type="integer",JSONPath=".spec.end"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:categories={nephio,ipam}
+
+// ResourcePool is the Schema for the resourcepools API
+type ResourcePool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ResourcePoolSpec   `json:"spec,omitempty"`
+	Status ResourcePoolStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ResourcePoolList contains a list of ResourcePool
+type ResourcePoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ResourcePool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ResourcePool{}, &ResourcePoolList{})
+}
+
+var (
+	ResourcePoolKind             = reflect.TypeOf(ResourcePool{}).Name()
+	ResourcePoolGroupKind        = schema.GroupKind{Group: GroupVersion.Group, Kind: ResourcePoolKind}.String()
+	ResourcePoolKindAPIVersion   = ResourcePoolKind + "." + GroupVersion.String()
+	ResourcePoolGroupVersionKind = GroupVersion.WithKind(ResourcePoolKind)
+)
@@ -0,0 +1,32 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// ipam-fn is a kpt function that allocates the IP(s) of the IPAllocation
+// resources in a package using the allocation gRPC service of the IPAM
+package main
+
+import (
+	"os"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	"github.com/nokia/k8s-ipam/internal/allocfn"
+)
+
+func main() {
+	if err := fn.AsMain(allocfn.New(&allocfn.Options{})); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/pkg/alloc/allocpb"
+	"github.com/spf13/cobra"
+)
+
+// allocOptions are the flags that identify an allocation
+type allocOptions struct {
+	namespace       string
+	networkInstance string
+	network         string
+	prefixKind      string
+	prefix          string
+	prefixLength    uint32
+	addressFamily   string
+	selector        map[string]string
+	labels          map[string]string
+	endpoints       []string
+}
+
+func (a *allocOptions) addIdentityFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&a.namespace, "namespace", "n", defaultNamespace, "namespace of the allocation")
+	cmd.Flags().StringVar(&a.networkInstance, "network-instance", "", "network instance of the allocation")
+	_ = cmd.MarkFlagRequired("network-instance")
+}
+
+func (a *allocOptions) addSpecFlags(cmd *cobra.Command) {
+	a.addIdentityFlags(cmd)
+	cmd.Flags().StringVar(&a.network, "network", "", "network name, used for prefix kind network")
+	cmd.Flags().StringVar(&a.prefixKind, "kind", string(ipamv1alpha1.PrefixKindNetwork), "prefix kind: network, loopback, pool, aggregate or link")
+	cmd.Flags().StringVar(&a.prefix, "prefix", "", "static prefix to allocate")
+	cmd.Flags().Uint32Var(&a.prefixLength, "prefix-length", 0, "prefix length of a dynamic allocation")
+	cmd.Flags().StringVar(&a.addressFamily, "address-family", "", "address family of a dynamic allocation: ipv4 or ipv6")
+	cmd.Flags().StringToStringVar(&a.selector, "selector", nil, "additional selector labels, e.g. key1=value1,key2=value2")
+	cmd.Flags().StringToStringVar(&a.labels, "labels", nil, "labels of the allocation, e.g. key1=value1,key2=value2")
+	cmd.Flags().StringArrayVar(&a.endpoints, "endpoint", nil, "endpoint of a link as node/interface, repeat for both endpoints")
+}
+
+func (a *allocOptions) buildRequest(name string) *allocpb.Request {
+	selector := map[string]string{}
+	for k, v := range a.selector {
+		selector[k] = v
+	}
+	selector[ipamv1alpha1.NephioNetworkInstanceKey] = a.networkInstance
+	if a.network != "" {
+		selector[ipamv1alpha1.NephioNetworkNameKey] = a.network
+	}
+	var endpoints []*allocpb.Endpoint
+	for _, ep := range a.endpoints {
+		// the interface can contain a /, e.g. ethernet-1/1
+		node, itfce, _ := strings.Cut(ep, "/")
+		endpoints = append(endpoints, &allocpb.Endpoint{
+			Node:      node,
+			Interface: itfce,
+		})
+	}
+	return &allocpb.Request{
+		Namespace: a.namespace,
+		Name:      name,
+		Kind:      "ipam",
+		Labels:    a.labels,
+		Spec: &allocpb.Spec{
+			Prefixkind:    a.prefixKind,
+			Prefix:        a.prefix,
+			PrefixLength:  a.prefixLength,
+			Network:       a.network,
+			AddressFamily: a.addressFamily,
+			Selector:      selector,
+			Endpoints:     endpoints,
+		},
+	}
+}
+
+func newAllocateCmd(o *rootOptions) *cobra.Command {
+	a := &allocOptions{}
+	cmd := &cobra.Command{
+		Use:   "allocate NAME",
+		Short: "allocate a prefix in a network instance",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(func(ctx context.Context, c allocpb.AllocationClient) error {
+				resp, err := c.Allocation(ctx, a.buildRequest(args[0]))
+				if err != nil {
+					return fmt.Errorf("cannot allocate %s: %w", args[0], err)
+				}
+				return printAllocation(cmd.OutOrStdout(), o.output, args[0], resp)
+			})
+		},
+	}
+	a.addSpecFlags(cmd)
+	return cmd
+}
+
+func newDeallocateCmd(o *rootOptions) *cobra.Command {
+	a := &allocOptions{}
+	cmd := &cobra.Command{
+		Use:   "deallocate NAME",
+		Short: "release an allocation from a network instance",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(func(ctx context.Context, c allocpb.AllocationClient) error {
+				if _, err := c.DeAllocation(ctx, a.buildRequest(args[0])); err != nil {
+					return fmt.Errorf("cannot deallocate %s: %w", args[0], err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "allocation %s deallocated\n", args[0])
+				return nil
+			})
+		},
+	}
+	a.addSpecFlags(cmd)
+	return cmd
+}
+
+func newGetCmd(o *rootOptions) *cobra.Command {
+	a := &allocOptions{}
+	cmd := &cobra.Command{
+		Use:   "get NAME",
+		Short: "show the prefix and gateway of an allocation",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(func(ctx context.Context, c allocpb.AllocationClient) error {
+				resp, err := c.GetAllocation(ctx, a.buildRequest(args[0]))
+				if err != nil {
+					return fmt.Errorf("cannot get allocation %s: %w", args[0], err)
+				}
+				return printAllocation(cmd.OutOrStdout(), o.output, args[0], resp)
+			})
+		},
+	}
+	a.addIdentityFlags(cmd)
+	return cmd
+}
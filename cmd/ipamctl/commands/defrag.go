@@ -0,0 +1,53 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nokia/k8s-ipam/pkg/alloc/allocpb"
+	"github.com/spf13/cobra"
+)
+
+func newDefragCmd(o *rootOptions) *cobra.Command {
+	var networkInstance, prefix string
+	var execute bool
+	cmd := &cobra.Command{
+		Use:   "defrag",
+		Short: "plan the compaction of the dynamic allocations of a network instance, optionally executing the plan",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(func(ctx context.Context, c allocpb.AllocationClient) error {
+				resp, err := c.Defrag(ctx, &allocpb.DefragRequest{
+					NetworkInstance: networkInstance,
+					Prefix:          prefix,
+					Execute:         execute,
+				})
+				if err != nil {
+					return fmt.Errorf("cannot defrag network instance %s: %w", networkInstance, err)
+				}
+				return printDefrag(cmd.OutOrStdout(), o.output, networkInstance, resp)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&networkInstance, "network-instance", "", "network instance to compact")
+	cmd.Flags().StringVar(&prefix, "prefix", "", "aggregate or pool prefix to compact, all aggregate and pool prefixes when empty")
+	cmd.Flags().BoolVar(&execute, "execute", false, "renumber the allocations, otherwise the plan is only reported")
+	_ = cmd.MarkFlagRequired("network-instance")
+	return cmd
+}
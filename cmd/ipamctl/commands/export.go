@@ -0,0 +1,51 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nokia/k8s-ipam/pkg/alloc/allocpb"
+	"github.com/spf13/cobra"
+)
+
+func newExportCmd(o *rootOptions) *cobra.Command {
+	var networkInstance, format string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "export the prefix hierarchy of a network instance as json, yaml or graphviz dot",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(func(ctx context.Context, c allocpb.AllocationClient) error {
+				resp, err := c.Export(ctx, &allocpb.ExportRequest{
+					NetworkInstance: networkInstance,
+					Format:          format,
+				})
+				if err != nil {
+					return fmt.Errorf("cannot export network instance %s: %w", networkInstance, err)
+				}
+				_, err = cmd.OutOrStdout().Write(resp.GetData())
+				return err
+			})
+		},
+	}
+	cmd.Flags().StringVar(&networkInstance, "network-instance", "", "network instance to export")
+	cmd.Flags().StringVar(&format, "format", "json", "export format: json, yaml or dot")
+	_ = cmd.MarkFlagRequired("network-instance")
+	return cmd
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/internal/importer"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+type importOptions struct {
+	file   string
+	format string
+	apply  bool
+	importer.Options
+}
+
+func newImportCmd() *cobra.Command {
+	i := &importOptions{}
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "import prefixes from a csv, netbox or phpipam export as IPPrefix and IPAllocation resources",
+		Long: "import validates the hierarchy of the exported prefixes offline and reports all conflicts " +
+			"before anything is created. Without --apply the resources are printed as yaml manifests.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return i.run(cmd.Context(), cmd.OutOrStdout(), cmd.ErrOrStderr())
+		},
+	}
+	cmd.Flags().StringVarP(&i.file, "file", "f", "", "export file to import, - reads from stdin")
+	cmd.Flags().StringVar(&i.format, "format", string(importer.FormatCSV), "format of the export: csv, netbox or phpipam")
+	cmd.Flags().BoolVar(&i.apply, "apply", false, "create the resources in the cluster of the current kubeconfig context")
+	cmd.Flags().StringVarP(&i.Namespace, "namespace", "n", defaultNamespace, "namespace of the resources")
+	cmd.Flags().StringVar(&i.NetworkInstance, "network-instance", "", "network instance of the prefixes that have no vrf")
+	cmd.Flags().StringToStringVar(&i.Roles, "role-map", nil, "maps roles of the export to prefix kinds, e.g. infra=aggregate,customer=network")
+	_ = cmd.MarkFlagRequired("file")
+	return cmd
+}
+
+func (i *importOptions) run(ctx context.Context, out, errOut io.Writer) error {
+	r := os.Stdin
+	if i.file != "-" {
+		f, err := os.Open(i.file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	records, err := importer.Read(importer.Format(i.format), r, &i.Options)
+	if err != nil {
+		return err
+	}
+	if conflicts := importer.Validate(ctx, records); len(conflicts) > 0 {
+		for _, c := range conflicts {
+			fmt.Fprintln(errOut, c.String())
+		}
+		return fmt.Errorf("%d of %d prefixes cannot be imported, nothing was created", len(conflicts), len(records))
+	}
+	objs := importer.Objects(records, i.Namespace)
+
+	if !i.apply {
+		for _, obj := range objs {
+			b, err := yaml.Marshal(obj)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "---\n%s", b)
+		}
+		return nil
+	}
+
+	scheme := runtime.NewScheme()
+	if err := ipamv1alpha1.AddToScheme(scheme); err != nil {
+		return err
+	}
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return err
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		if err := c.Create(ctx, obj); err != nil {
+			return fmt.Errorf("cannot create %s %s: %w", kind, obj.GetName(), err)
+		}
+		fmt.Fprintf(out, "%s/%s created\n", kind, obj.GetName())
+	}
+	return nil
+}
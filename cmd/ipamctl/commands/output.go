@@ -0,0 +1,307 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/pkg/alloc/allocpb"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+func validateOutput(output string) error {
+	switch output {
+	case outputTable, outputJSON, outputYAML:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q, supported: %s, %s, %s", output, outputTable, outputJSON, outputYAML)
+	}
+}
+
+// printStructured prints obj as json or yaml and reports if it did so
+func printStructured(w io.Writer, output string, obj interface{}) (bool, error) {
+	switch output {
+	case outputJSON:
+		b, err := json.MarshalIndent(obj, "", "  ")
+		if err != nil {
+			return true, err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return true, err
+	case outputYAML:
+		b, err := yaml.Marshal(obj)
+		if err != nil {
+			return true, err
+		}
+		_, err = w.Write(b)
+		return true, err
+	}
+	return false, nil
+}
+
+type allocation struct {
+	Name               string     `json:"name"`
+	AllocatedPrefix    string     `json:"allocatedPrefix,omitempty"`
+	ParentPrefix       string     `json:"parentPrefix,omitempty"`
+	Gateway            string     `json:"gateway,omitempty"`
+	Gateways           []gateway  `json:"gateways,omitempty"`
+	RouteDistinguisher string     `json:"routeDistinguisher,omitempty"`
+	ImportRouteTargets []string   `json:"importRouteTargets,omitempty"`
+	ExportRouteTargets []string   `json:"exportRouteTargets,omitempty"`
+	Endpoints          []endpoint `json:"endpoints,omitempty"`
+}
+
+type gateway struct {
+	Address string `json:"address"`
+	Role    string `json:"role,omitempty"`
+}
+
+type endpoint struct {
+	Node      string `json:"node"`
+	Interface string `json:"interface"`
+	Address   string `json:"address,omitempty"`
+}
+
+func printAllocation(w io.Writer, output, name string, resp *allocpb.Response) error {
+	a := &allocation{
+		Name:               name,
+		AllocatedPrefix:    resp.GetAllocatedPrefix(),
+		ParentPrefix:       resp.GetParentPrefix(),
+		Gateway:            resp.GetGateway(),
+		RouteDistinguisher: resp.GetRouteDistinguisher(),
+		ImportRouteTargets: resp.GetImportRouteTargets(),
+		ExportRouteTargets: resp.GetExportRouteTargets(),
+	}
+	for _, gw := range resp.GetGateways() {
+		a.Gateways = append(a.Gateways, gateway{
+			Address: gw.GetAddress(),
+			Role:    gw.GetRole(),
+		})
+	}
+	for _, ep := range resp.GetEndpoints() {
+		a.Endpoints = append(a.Endpoints, endpoint{
+			Node:      ep.GetNode(),
+			Interface: ep.GetInterface(),
+			Address:   ep.GetAddress(),
+		})
+	}
+	if ok, err := printStructured(w, output, a); ok {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tPREFIX\tPARENT\tGATEWAY\tRD\tEXPORT-RT")
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", a.Name, a.AllocatedPrefix, a.ParentPrefix, a.Gateway, a.RouteDistinguisher, strings.Join(a.ExportRouteTargets, ","))
+	if len(a.Gateways) > 1 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "ROLE\tGATEWAY")
+		for _, gw := range a.Gateways {
+			fmt.Fprintf(tw, "%s\t%s\n", gw.Role, gw.Address)
+		}
+	}
+	if len(a.Endpoints) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "NODE\tINTERFACE\tADDRESS")
+		for _, ep := range a.Endpoints {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", ep.Node, ep.Interface, ep.Address)
+		}
+	}
+	return tw.Flush()
+}
+
+type route struct {
+	Prefix string            `json:"prefix"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+func printRoutes(w io.Writer, output string, routes []*allocpb.Route) error {
+	rs := make([]*route, 0, len(routes))
+	for _, r := range routes {
+		rs = append(rs, &route{Prefix: r.GetPrefix(), Labels: r.GetLabels()})
+	}
+	if ok, err := printStructured(w, output, rs); ok {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "PREFIX\tKIND\tNAME\tLABELS")
+	for _, r := range rs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n",
+			r.Prefix,
+			r.Labels[ipamv1alpha1.NephioPrefixKindKey],
+			r.Labels[ipamv1alpha1.NephioIPAllocactionNameKey],
+			formatLabels(r.Labels),
+		)
+	}
+	return tw.Flush()
+}
+
+type defragPlan struct {
+	NetworkInstance string          `json:"networkInstance"`
+	Executed        bool            `json:"executed"`
+	Prefixes        []*defragPrefix `json:"prefixes"`
+}
+
+type defragPrefix struct {
+	Prefix                         string       `json:"prefix"`
+	Kind                           string       `json:"kind"`
+	LargestFreePrefixLength        uint32       `json:"largestFreePrefixLength,omitempty"`
+	FreeBlocks                     int32        `json:"freeBlocks"`
+	PlannedLargestFreePrefixLength uint32       `json:"plannedLargestFreePrefixLength,omitempty"`
+	PlannedFreeBlocks              int32        `json:"plannedFreeBlocks"`
+	Pinned                         []string     `json:"pinned,omitempty"`
+	Moves                          []defragMove `json:"moves,omitempty"`
+}
+
+type defragMove struct {
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Kind      string `json:"kind,omitempty"`
+	OldPrefix string `json:"oldPrefix"`
+	NewPrefix string `json:"newPrefix"`
+	Signalled bool   `json:"signalled,omitempty"`
+}
+
+func printDefrag(w io.Writer, output, networkInstance string, resp *allocpb.DefragResponse) error {
+	plan := &defragPlan{
+		NetworkInstance: networkInstance,
+		Executed:        resp.GetExecuted(),
+		Prefixes:        make([]*defragPrefix, 0, len(resp.GetPrefixes())),
+	}
+	for _, p := range resp.GetPrefixes() {
+		dp := &defragPrefix{
+			Prefix:                         p.GetPrefix(),
+			Kind:                           p.GetKind(),
+			LargestFreePrefixLength:        p.GetLargestFreePrefixLength(),
+			FreeBlocks:                     p.GetFreeBlocks(),
+			PlannedLargestFreePrefixLength: p.GetPlannedLargestFreePrefixLength(),
+			PlannedFreeBlocks:              p.GetPlannedFreeBlocks(),
+			Pinned:                         p.GetPinned(),
+		}
+		for _, m := range p.GetMoves() {
+			dp.Moves = append(dp.Moves, defragMove{
+				Namespace: m.GetNamespace(),
+				Name:      m.GetName(),
+				Kind:      m.GetKind(),
+				OldPrefix: m.GetOldPrefix(),
+				NewPrefix: m.GetNewPrefix(),
+				Signalled: m.GetSignalled(),
+			})
+		}
+		plan.Prefixes = append(plan.Prefixes, dp)
+	}
+	if ok, err := printStructured(w, output, plan); ok {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "PREFIX\tKIND\tLARGEST-FREE\tFREE-BLOCKS\tMOVES\tPINNED")
+	for _, dp := range plan.Prefixes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\n",
+			dp.Prefix,
+			dp.Kind,
+			formatChange(formatPrefixLength(dp.LargestFreePrefixLength), formatPrefixLength(dp.PlannedLargestFreePrefixLength)),
+			formatChange(fmt.Sprint(dp.FreeBlocks), fmt.Sprint(dp.PlannedFreeBlocks)),
+			len(dp.Moves),
+			len(dp.Pinned),
+		)
+	}
+	moves, unsignalled := false, 0
+	for _, dp := range plan.Prefixes {
+		for _, m := range dp.Moves {
+			if !moves {
+				fmt.Fprintln(tw)
+				fmt.Fprintln(tw, "NAMESPACE\tNAME\tKIND\tOLD-PREFIX\tNEW-PREFIX")
+				moves = true
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", m.Namespace, m.Name, m.Kind, m.OldPrefix, m.NewPrefix)
+			if !m.Signalled {
+				unsignalled++
+			}
+		}
+	}
+	if moves && !plan.Executed {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "plan not executed, use --execute to renumber the allocations")
+	}
+	if plan.Executed && unsignalled > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintf(tw, "%d moved allocations have no IPAllocation, their clients get the new prefix with GetAllocation\n", unsignalled)
+	}
+	return tw.Flush()
+}
+
+func formatPrefixLength(l uint32) string {
+	if l == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("/%d", l)
+}
+
+func formatChange(before, after string) string {
+	if before == after {
+		return before
+	}
+	return before + " -> " + after
+}
+
+func printTree(w io.Writer, output string, roots []*treeNode) error {
+	if ok, err := printStructured(w, output, roots); ok {
+		return err
+	}
+	for _, n := range roots {
+		fmt.Fprintln(w, formatNode(n))
+		printChildren(w, n.Children, "")
+	}
+	return nil
+}
+
+func printChildren(w io.Writer, children []*treeNode, indent string) {
+	for i, n := range children {
+		branch, next := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, next = "└── ", "    "
+		}
+		fmt.Fprintln(w, indent+branch+formatNode(n))
+		printChildren(w, n.Children, indent+next)
+	}
+}
+
+func formatNode(n *treeNode) string {
+	if n.PrefixKind == "" && n.Name == "" {
+		return n.Prefix
+	}
+	return fmt.Sprintf("%s (%s %s)", n.Prefix, n.PrefixKind, n.Name)
+}
+
+func formatLabels(l map[string]string) string {
+	kv := make([]string, 0, len(l))
+	for k, v := range l {
+		kv = append(kv, k+"="+v)
+	}
+	sort.Strings(kv)
+	return strings.Join(kv, ",")
+}
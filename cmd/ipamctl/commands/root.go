@@ -0,0 +1,91 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nokia/k8s-ipam/pkg/alloc/alloc"
+	"github.com/nokia/k8s-ipam/pkg/alloc/allocpb"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultAddress   = "127.0.0.1:9999"
+	defaultNamespace = "default"
+	defaultTimeout   = 10 * time.Second
+)
+
+type rootOptions struct {
+	address    string
+	insecure   bool
+	skipVerify bool
+	clientID   string
+	timeout    time.Duration
+	output     string
+}
+
+// NewRootCmd returns the ipamctl root command with all subcommands
+func NewRootCmd() *cobra.Command {
+	o := &rootOptions{}
+	cmd := &cobra.Command{
+		Use:           "ipamctl",
+		Short:         "ipamctl interacts with the allocation service of the ipam",
+		SilenceUsage:  true,
+		SilenceErrors: false,
+	}
+	cmd.PersistentFlags().StringVar(&o.address, "address", defaultAddress, "address of the ipam allocation service")
+	cmd.PersistentFlags().BoolVar(&o.insecure, "insecure", true, "connect without TLS")
+	cmd.PersistentFlags().BoolVar(&o.skipVerify, "skip-verify", false, "skip verification of the server certificate")
+	cmd.PersistentFlags().StringVar(&o.clientID, "client-id", "", "identifies the client, allocations are counted per client by quotas")
+	cmd.PersistentFlags().DurationVar(&o.timeout, "timeout", defaultTimeout, "timeout of a request")
+	cmd.PersistentFlags().StringVarP(&o.output, "output", "o", outputTable, "output format: table, json or yaml")
+
+	cmd.AddCommand(
+		newAllocateCmd(o),
+		newDeallocateCmd(o),
+		newGetCmd(o),
+		newRoutesCmd(o),
+		newTreeCmd(o),
+		newExportCmd(o),
+		newDefragCmd(o),
+		newImportCmd(),
+	)
+	return cmd
+}
+
+// run connects to the allocation service and calls fn with a context
+// bounded by the request timeout
+func (o *rootOptions) run(fn func(ctx context.Context, c allocpb.AllocationClient) error) error {
+	if err := validateOutput(o.output); err != nil {
+		return err
+	}
+	c, err := alloc.CreateClient(&alloc.Config{
+		Address:    o.address,
+		Insecure:   o.insecure,
+		SkipVerify: o.skipVerify,
+		ClientID:   o.clientID,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot connect to %s: %w", o.address, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+	defer cancel()
+	return fn(ctx, c)
+}
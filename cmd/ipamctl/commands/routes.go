@@ -0,0 +1,91 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nokia/k8s-ipam/pkg/alloc/allocpb"
+	"github.com/spf13/cobra"
+)
+
+type routesOptions struct {
+	networkInstance string
+	selector        map[string]string
+}
+
+func (r *routesOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&r.networkInstance, "network-instance", "", "network instance to query")
+	cmd.Flags().StringToStringVarP(&r.selector, "selector", "l", nil, "only return routes matching the labels, e.g. key1=value1,key2=value2")
+	_ = cmd.MarkFlagRequired("network-instance")
+}
+
+func (r *routesOptions) list(ctx context.Context, c allocpb.AllocationClient) ([]*allocpb.Route, error) {
+	resp, err := c.ListRoutes(ctx, &allocpb.ListRequest{
+		NetworkInstance: r.networkInstance,
+		Selector:        r.selector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list routes of network instance %s: %w", r.networkInstance, err)
+	}
+	return resp.GetRoutes(), nil
+}
+
+func newRoutesCmd(o *rootOptions) *cobra.Command {
+	r := &routesOptions{}
+	cmd := &cobra.Command{
+		Use:   "routes",
+		Short: "list the routes of a network instance",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(func(ctx context.Context, c allocpb.AllocationClient) error {
+				routes, err := r.list(ctx, c)
+				if err != nil {
+					return err
+				}
+				return printRoutes(cmd.OutOrStdout(), o.output, routes)
+			})
+		},
+	}
+	r.addFlags(cmd)
+	return cmd
+}
+
+func newTreeCmd(o *rootOptions) *cobra.Command {
+	r := &routesOptions{}
+	cmd := &cobra.Command{
+		Use:   "tree",
+		Short: "print the prefix hierarchy of a network instance",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(func(ctx context.Context, c allocpb.AllocationClient) error {
+				routes, err := r.list(ctx, c)
+				if err != nil {
+					return err
+				}
+				tree, err := buildTree(routes)
+				if err != nil {
+					return err
+				}
+				return printTree(cmd.OutOrStdout(), o.output, tree)
+			})
+		},
+	}
+	r.addFlags(cmd)
+	return cmd
+}
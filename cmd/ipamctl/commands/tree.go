@@ -0,0 +1,77 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"sort"
+
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/pkg/alloc/allocpb"
+	"inet.af/netaddr"
+)
+
+// treeNode is a prefix in the hierarchy of a network instance
+type treeNode struct {
+	Prefix     string      `json:"prefix"`
+	PrefixKind string      `json:"prefixKind,omitempty"`
+	Name       string      `json:"name,omitempty"`
+	Children   []*treeNode `json:"children,omitempty"`
+
+	p netaddr.IPPrefix
+}
+
+// buildTree nests every route under the most specific route that contains it
+func buildTree(routes []*allocpb.Route) ([]*treeNode, error) {
+	nodes := make([]*treeNode, 0, len(routes))
+	for _, route := range routes {
+		p, err := netaddr.ParseIPPrefix(route.GetPrefix())
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse route %s: %w", route.GetPrefix(), err)
+		}
+		nodes = append(nodes, &treeNode{
+			Prefix:     route.GetPrefix(),
+			PrefixKind: route.GetLabels()[ipamv1alpha1.NephioPrefixKindKey],
+			Name:       route.GetLabels()[ipamv1alpha1.NephioIPAllocactionNameKey],
+			p:          p,
+		})
+	}
+	// parents always have a shorter prefix length than their children
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].p.Bits() != nodes[j].p.Bits() {
+			return nodes[i].p.Bits() < nodes[j].p.Bits()
+		}
+		return nodes[i].p.IP().Less(nodes[j].p.IP())
+	})
+
+	roots := []*treeNode{}
+	for i, n := range nodes {
+		var parent *treeNode
+		for _, candidate := range nodes[:i] {
+			if candidate.p.Bits() < n.p.Bits() && candidate.p.Contains(n.p.IP()) {
+				// later candidates are more specific
+				parent = candidate
+			}
+		}
+		if parent == nil {
+			roots = append(roots, n)
+			continue
+		}
+		parent.Children = append(parent.Children, n)
+	}
+	return roots, nil
+}
@@ -18,22 +18,27 @@ package allocation
 
 import (
 	"context"
-	"strings"
 	"time"
 
+	"inet.af/netaddr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/go-logr/logr"
 	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/internal/event"
 	"github.com/nokia/k8s-ipam/internal/ipam"
 	"github.com/nokia/k8s-ipam/internal/meta"
 	"github.com/nokia/k8s-ipam/internal/resource"
 	"github.com/nokia/k8s-ipam/internal/shared"
+	"github.com/nokia/k8s-ipam/internal/utils/iputil"
+	"github.com/nokia/k8s-ipam/internal/vrf"
 	"github.com/pkg/errors"
 )
 
@@ -49,6 +54,7 @@ const (
 //+kubebuilder:rbac:groups=ipam.nephio.org,resources=ipallocations,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=ipam.nephio.org,resources=ipallocations/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=ipam.nephio.org,resources=ipallocations/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=*,resources=networkinstances,verbs=get;list;watch
 
 // SetupWithManager sets up the controller with the Manager.
@@ -57,20 +63,30 @@ func Setup(mgr ctrl.Manager, options *shared.Options) error {
 		Client:       mgr.GetClient(),
 		Scheme:       mgr.GetScheme(),
 		Ipam:         options.Ipam,
+		Vrf:          options.Vrf,
 		pollInterval: options.Poll,
 		finalizer:    resource.NewAPIFinalizer(mgr.GetClient(), finalizer),
+		recorder:     event.NewRecorder(mgr.GetEventRecorderFor("ipallocation-controller")),
 	}
 
-	/*
-		niHandler := &EnqueueRequestForAllNetworkInstances{
-			client: mgr.GetClient(),
-			ctx:    context.Background(),
-		}
-	*/
+	niHandler := &EnqueueRequestForAllNetworkInstances{
+		client: mgr.GetClient(),
+		ctx:    context.Background(),
+	}
+	prefixHandler := &EnqueueRequestForPrefixAllocations{
+		client: mgr.GetClient(),
+		ctx:    context.Background(),
+	}
+	allocHandler := &EnqueueRequestForPendingAllocations{
+		client: mgr.GetClient(),
+		ctx:    context.Background(),
+	}
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&ipamv1alpha1.IPAllocation{}).
-		//Watches(&source.Kind{Type: &ipamv1alpha1.NetworkInstance{}}, niHandler).
+		Watches(&source.Kind{Type: &ipamv1alpha1.NetworkInstance{}}, niHandler).
+		Watches(&source.Kind{Type: &ipamv1alpha1.IPPrefix{}}, prefixHandler).
+		Watches(&source.Kind{Type: &ipamv1alpha1.IPAllocation{}}, allocHandler).
 		Complete(r)
 }
 
@@ -79,8 +95,10 @@ type reconciler struct {
 	client.Client
 	Scheme       *runtime.Scheme
 	Ipam         ipam.Ipam
+	Vrf          vrf.Allocator
 	pollInterval time.Duration
 	finalizer    *resource.APIFinalizer
+	recorder     event.Recorder
 
 	l logr.Logger
 }
@@ -103,12 +121,15 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if meta.WasDeleted(cr) {
 		// TBD remove finalizer
 		if err := r.Ipam.DeAllocateIPPrefix(ctx, ipam.BuildAllocationFromIPAllocation(cr)); err != nil {
-			if !strings.Contains(err.Error(), "not ready") || !strings.Contains(err.Error(), "not found") {
+			if !errors.Is(err, ipam.ErrNetworkInstanceNotReady) && !errors.Is(err, ipam.ErrNotFound) {
 				r.l.Error(err, "cannot delete resource")
 				cr.SetConditions(ipamv1alpha1.ReconcileError(err), ipamv1alpha1.Unknown())
 				return reconcile.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 			}
 		}
+		if cr.Status.AllocatedPrefix != "" {
+			r.recorder.Normal(cr, event.ReasonReleased, "released prefix %s", cr.Status.AllocatedPrefix)
+		}
 
 		if err := r.finalizer.RemoveFinalizer(ctx, cr); err != nil {
 			r.l.Error(err, "cannot remove finalizer")
@@ -116,6 +137,7 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			return reconcile.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 		}
 
+		r.recorder.Forget(cr)
 		r.l.Info("Successfully deleted resource")
 		return reconcile.Result{Requeue: false}, nil
 	}
@@ -129,12 +151,14 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return reconcile.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
+	cr.Status.ObservedGeneration = cr.GetGeneration()
+
 	// check if the network instance exists in the allocation request
 	niName, ok := cr.Spec.Selector.MatchLabels[ipamv1alpha1.NephioNetworkInstanceKey]
 	if !ok {
 		r.l.Info("cannot allocate prefix, network-intance not found in cr")
 		cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.Failed("network-instance not found in cr"))
-		return ctrl.Result{RequeueAfter: 5 * time.Second}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
 	// check the network instance existance, to ensure we update the condition in the cr
@@ -147,16 +171,20 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		// There's no need to requeue if we no longer exist. Otherwise we'll be
 		// requeued implicitly because we return an error.
 		r.l.Info("cannot allocate prefix, network-intance not found")
+		r.recorder.Warning(cr, event.ReasonNetworkInstanceNotReady, "network instance %s not found", niName)
 		cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.Failed("network-instance not found"))
-		return ctrl.Result{RequeueAfter: 5 * time.Second}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		// the network instance watch enqueues the allocation once the network instance is ready
+		return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
 	// check the network instance existance, to ensure we update the condition in the cr
 	// when a network instance get deleted
 	if meta.WasDeleted(ni) {
 		r.l.Info("cannot allocate prefix, network-intance not ready")
-		cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.Failed("network-instance not ready"))
-		return ctrl.Result{RequeueAfter: 5 * time.Second}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		r.recorder.Warning(cr, event.ReasonNetworkInstanceNotReady, "network instance %s is being deleted", niName)
+		cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.NotReady("network-instance terminating"))
+		// the network instance watch enqueues the allocation once the network instance is deleted
+		return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
 	// for prefixKind network validate if the label exists
@@ -165,7 +193,7 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		if !ok {
 			r.l.Info("cannot allocate prefix, matchLabels must contain a network key")
 			cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.Failed("cannot allocate prefix, matchLabels must contain a network key"))
-			return ctrl.Result{RequeueAfter: 5 * time.Second}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+			return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 		}
 	}
 
@@ -178,8 +206,20 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	allocatedPrefix, err := r.Ipam.AllocateIPPrefix(ctx, ipam.BuildAllocationFromIPAllocation(cr))
 	if err != nil {
 		r.l.Info("cannot allocate prefix", "err", err)
-		cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.Failed(err.Error()))
-		return reconcile.Result{RequeueAfter: 5 * time.Second}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		r.recorder.Warning(cr, event.GetFailureReason(err), "cannot allocate prefix in network instance %s: %s", niName, err.Error())
+		if errors.Is(err, ipam.ErrQuotaExceeded) {
+			cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.QuotaExceeded(err.Error()))
+			return reconcile.Result{RequeueAfter: r.pollInterval}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		}
+		cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.FailedWithReason(ipam.GetConditionReason(err), err.Error()))
+		// the prefix and allocation watches enqueue the allocation once a prefix it
+		// selects is allocated or changed, or another allocation is released; an
+		// exhausted pool is still polled since prefixes released over grpc or by
+		// an expired lease do not enqueue it
+		if errors.Is(err, ipam.ErrPoolExhausted) {
+			return reconcile.Result{RequeueAfter: r.pollInterval}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		}
+		return reconcile.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 	// if the prefix is allocated in the spec, we need to ensure we get the same allocation
 	if cr.Spec.Prefix != "" {
@@ -187,12 +227,41 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			// we got a different prefix than requested
 			r.l.Error(err, "prefix allocation failed", "requested", cr.Spec.Prefix, "allocated", *allocatedPrefix)
 			cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.Unknown())
-			return ctrl.Result{RequeueAfter: 5 * time.Second}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+			return ctrl.Result{RequeueAfter: r.pollInterval}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 		}
 	}
 	cr.Status.Gateway = allocatedPrefix.Gateway
+	cr.Status.Gateways = allocatedPrefix.Gateways
 	cr.Status.AllocatedPrefix = allocatedPrefix.AllocatedPrefix
+	cr.Status.ParentPrefix = allocatedPrefix.ParentPrefix
+	if p, err := netaddr.ParseIPPrefix(allocatedPrefix.AllocatedPrefix); err == nil {
+		cr.Status.Address = p.IP().String()
+		cr.Status.PrefixLength = p.Bits()
+		cr.Status.AddressFamily = string(iputil.GetAddressFamily(p))
+	}
+	cr.Status.PrefixKind = cr.Spec.PrefixKind
+	cr.Status.NetworkInstance = niName
+	cr.Status.NetworkName = cr.Spec.Selector.MatchLabels[ipamv1alpha1.NephioNetworkNameKey]
+	cr.Status.Endpoints = allocatedPrefix.Endpoints
+	cr.Status.LeaseExpiry = nil
+	if !allocatedPrefix.LeaseExpiry.IsZero() {
+		cr.Status.LeaseExpiry = &metav1.Time{Time: allocatedPrefix.LeaseExpiry}
+	}
+	// reflect the vpn identifiers of the network instance
+	cr.Status.RouteDistinguisher = ""
+	cr.Status.ImportRouteTargets = nil
+	cr.Status.ExportRouteTargets = nil
+	if ids, ok := r.Vrf.Get(cr.Spec.Selector.MatchLabels[ipamv1alpha1.NephioNetworkInstanceKey]); ok {
+		cr.Status.RouteDistinguisher = ids.RouteDistinguisher
+		cr.Status.ImportRouteTargets = ids.ImportRouteTargets
+		cr.Status.ExportRouteTargets = ids.ExportRouteTargets
+	}
 	r.l.Info("Successfully reconciled resource", "allocatedPrefix", *allocatedPrefix)
+	r.recorder.Normal(cr, event.ReasonAllocated, "allocated prefix %s in network instance %s", allocatedPrefix.AllocatedPrefix, niName)
 	cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.Ready())
+	// renew the lease halfway through, allocating again extends it
+	if cr.Spec.LeaseDuration != nil && cr.Spec.LeaseDuration.Duration > 0 {
+		return ctrl.Result{RequeueAfter: cr.Spec.LeaseDuration.Duration / 2}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
 	return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 }
@@ -0,0 +1,94 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package allocation
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// EnqueueRequestForPendingAllocations enqueues the allocations of a network
+// instance that are not ready when another allocation of the network instance
+// releases its prefix
+type EnqueueRequestForPendingAllocations struct {
+	client client.Client
+	l      logr.Logger
+	ctx    context.Context
+}
+
+// Create does not enqueue, a new allocation does not release a prefix
+func (e *EnqueueRequestForPendingAllocations) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+}
+
+// Update enqueues the pending allocations when the allocated prefix changed
+func (e *EnqueueRequestForPendingAllocations) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	oldCr, ok := evt.ObjectOld.(*ipamv1alpha1.IPAllocation)
+	if !ok {
+		return
+	}
+	newCr, ok := evt.ObjectNew.(*ipamv1alpha1.IPAllocation)
+	if !ok {
+		return
+	}
+	if oldCr.Status.AllocatedPrefix != "" && oldCr.Status.AllocatedPrefix != newCr.Status.AllocatedPrefix {
+		e.add(newCr, q)
+	}
+}
+
+// Delete enqueues the pending allocations
+func (e *EnqueueRequestForPendingAllocations) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	cr, ok := evt.Object.(*ipamv1alpha1.IPAllocation)
+	if !ok {
+		return
+	}
+	e.add(cr, q)
+}
+
+// Generic does not enqueue
+func (e *EnqueueRequestForPendingAllocations) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+}
+
+func (e *EnqueueRequestForPendingAllocations) add(cr *ipamv1alpha1.IPAllocation, queue adder) {
+	e.l = log.FromContext(e.ctx)
+	if cr.Spec.Selector == nil {
+		return
+	}
+
+	allocs, err := listPendingAllocations(e.ctx, e.client, cr.GetNamespace(), cr.Spec.Selector.MatchLabels[ipamv1alpha1.NephioNetworkInstanceKey])
+	if err != nil {
+		e.l.Error(err, "cannot list allocations")
+		return
+	}
+
+	for _, alloc := range allocs {
+		if alloc.GetName() == cr.GetName() {
+			continue
+		}
+		e.l.Info("event requeue pending allocation", "name", alloc.GetName(), "trigger", cr.GetName())
+		queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: alloc.GetNamespace(),
+			Name:      alloc.GetName()}})
+	}
+}
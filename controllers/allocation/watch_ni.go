@@ -21,6 +21,7 @@ import (
 	//ndddvrv1 "github.com/yndd/ndd-core/apis/dvr/v1"
 	"github.com/go-logr/logr"
 	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/internal/shared"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
@@ -45,10 +46,21 @@ func (e *EnqueueRequestForAllNetworkInstances) Create(evt event.CreateEvent, q w
 	e.add(evt.Object, q)
 }
 
-// Create enqueues a request for all ip allocation within the ipam
+// Update enqueues a request for all ip allocation within the ipam when the
+// readiness of the network instance changes or the network instance is deleted
 func (e *EnqueueRequestForAllNetworkInstances) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
-	e.add(evt.ObjectOld, q)
-	e.add(evt.ObjectNew, q)
+	oldNi, ok := evt.ObjectOld.(*ipamv1alpha1.NetworkInstance)
+	if !ok {
+		return
+	}
+	newNi, ok := evt.ObjectNew.(*ipamv1alpha1.NetworkInstance)
+	if !ok {
+		return
+	}
+	if shared.ReadyChanged(oldNi, newNi) ||
+		oldNi.GetDeletionTimestamp().IsZero() != newNi.GetDeletionTimestamp().IsZero() {
+		e.add(evt.ObjectNew, q)
+	}
 }
 
 // Create enqueues a request for all ip allocation within the ipam
@@ -70,20 +82,17 @@ func (e *EnqueueRequestForAllNetworkInstances) add(obj runtime.Object, queue add
 	e.l.Info("event", "kind", obj.GetObjectKind(), "name", ni.GetName())
 
 	d := &ipamv1alpha1.IPAllocationList{}
-	if err := e.client.List(e.ctx, d); err != nil {
+	if err := e.client.List(e.ctx, d,
+		client.InNamespace(ni.GetNamespace()),
+		client.MatchingFields{shared.NetworkInstanceIndex: ni.GetName()}); err != nil {
+		e.l.Error(err, "cannot list allocations")
 		return
 	}
 
 	for _, alloc := range d.Items {
-		// only enqueue if the network-instance matches
-		niName, ok := alloc.Spec.Selector.MatchLabels[ipamv1alpha1.NephioNetworkInstanceKey]
-		if ok {
-			if ni.GetName() == niName {
-				e.l.Info("event requeue allocation", "name", alloc.GetName())
-				queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{
-					Namespace: alloc.GetNamespace(),
-					Name:      alloc.GetName()}})
-			}
-		}
+		e.l.Info("event requeue allocation", "name", alloc.GetName())
+		queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: alloc.GetNamespace(),
+			Name:      alloc.GetName()}})
 	}
 }
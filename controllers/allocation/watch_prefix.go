@@ -0,0 +1,144 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package allocation
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/internal/shared"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// EnqueueRequestForPrefixAllocations enqueues the allocations that are not
+// ready and select a prefix, when the prefix gets allocated, changed or released
+type EnqueueRequestForPrefixAllocations struct {
+	client client.Client
+	l      logr.Logger
+	ctx    context.Context
+}
+
+// Create does not enqueue, the prefix is not allocated yet
+func (e *EnqueueRequestForPrefixAllocations) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+}
+
+// Update enqueues the pending allocations when the prefix got allocated,
+// changed or is being deleted
+func (e *EnqueueRequestForPrefixAllocations) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	oldCr, ok := evt.ObjectOld.(*ipamv1alpha1.IPPrefix)
+	if !ok {
+		return
+	}
+	newCr, ok := evt.ObjectNew.(*ipamv1alpha1.IPPrefix)
+	if !ok {
+		return
+	}
+	if shared.ReadyChanged(oldCr, newCr) ||
+		oldCr.Status.AllocatedPrefix != newCr.Status.AllocatedPrefix ||
+		oldCr.GetDeletionTimestamp().IsZero() != newCr.GetDeletionTimestamp().IsZero() {
+		e.add(newCr, q)
+	}
+}
+
+// Delete enqueues the pending allocations
+func (e *EnqueueRequestForPrefixAllocations) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	cr, ok := evt.Object.(*ipamv1alpha1.IPPrefix)
+	if !ok {
+		return
+	}
+	e.add(cr, q)
+}
+
+// Generic does not enqueue
+func (e *EnqueueRequestForPrefixAllocations) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+}
+
+func (e *EnqueueRequestForPrefixAllocations) add(cr *ipamv1alpha1.IPPrefix, queue adder) {
+	e.l = log.FromContext(e.ctx)
+
+	allocs, err := listPendingAllocations(e.ctx, e.client, cr.GetNamespace(), cr.Spec.NetworkInstance)
+	if err != nil {
+		e.l.Error(err, "cannot list allocations")
+		return
+	}
+
+	l := getPrefixLabels(cr)
+	for _, alloc := range allocs {
+		if !selectsPrefix(&alloc, l) {
+			continue
+		}
+		e.l.Info("event requeue pending allocation", "name", alloc.GetName(), "prefix", cr.GetName())
+		queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: alloc.GetNamespace(),
+			Name:      alloc.GetName()}})
+	}
+}
+
+// listPendingAllocations returns the allocations of the network instance that
+// are not ready
+func listPendingAllocations(ctx context.Context, c client.Client, namespace, niName string) ([]ipamv1alpha1.IPAllocation, error) {
+	d := &ipamv1alpha1.IPAllocationList{}
+	if err := c.List(ctx, d,
+		client.InNamespace(namespace),
+		client.MatchingFields{shared.NetworkInstanceIndex: niName}); err != nil {
+		return nil, err
+	}
+	allocs := []ipamv1alpha1.IPAllocation{}
+	for _, alloc := range d.Items {
+		if !shared.IsReady(&alloc) {
+			allocs = append(allocs, alloc)
+		}
+	}
+	return allocs, nil
+}
+
+// getPrefixLabels returns the labels the routes of the prefix are known to
+// carry in the ipam
+func getPrefixLabels(cr *ipamv1alpha1.IPPrefix) map[string]string {
+	l := map[string]string{}
+	for k, v := range cr.GetLabels() {
+		l[k] = v
+	}
+	l[ipamv1alpha1.NephioNetworkInstanceKey] = cr.Spec.NetworkInstance
+	l[ipamv1alpha1.NephioIPPrefixNameKey] = cr.GetName()
+	l[ipamv1alpha1.NephioPrefixKindKey] = cr.Spec.PrefixKind
+	if cr.Spec.Network != "" {
+		l[ipamv1alpha1.NephioNetworkNameKey] = cr.Spec.Network
+	}
+	return l
+}
+
+// selectsPrefix returns false when a label the allocation selects on has
+// another value on the prefix; labels the prefix is not known to carry are
+// not considered, such that an allocation is rather enqueued once too often
+func selectsPrefix(alloc *ipamv1alpha1.IPAllocation, l map[string]string) bool {
+	if alloc.Spec.Selector == nil {
+		return false
+	}
+	for k, v := range alloc.Spec.Selector.MatchLabels {
+		if pv, ok := l[k]; ok && pv != v {
+			return false
+		}
+	}
+	return true
+}
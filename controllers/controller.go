@@ -17,22 +17,36 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
+
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/nokia/k8s-ipam/controllers/allocation"
 	"github.com/nokia/k8s-ipam/controllers/injector"
+	"github.com/nokia/k8s-ipam/controllers/ipquota"
+	"github.com/nokia/k8s-ipam/controllers/nestingpolicy"
 	"github.com/nokia/k8s-ipam/controllers/networkinstance"
 	"github.com/nokia/k8s-ipam/controllers/prefix"
+	"github.com/nokia/k8s-ipam/controllers/resourceclaim"
+	"github.com/nokia/k8s-ipam/controllers/resourcepool"
 	"github.com/nokia/k8s-ipam/internal/shared"
 )
 
 // Setup package controllers.
 func Setup(mgr ctrl.Manager, opts *shared.Options) error {
+	if err := shared.SetupIndexes(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
+
 	for _, setup := range []func(ctrl.Manager, *shared.Options) error{
+		nestingpolicy.Setup,
+		ipquota.Setup,
 		networkinstance.Setup,
 		prefix.Setup,
 		allocation.Setup,
 		injector.Setup,
+		resourcepool.Setup,
+		resourceclaim.Setup,
 	} {
 		if err := setup(mgr, opts); err != nil {
 			return err
@@ -29,6 +29,7 @@ import (
 	"github.com/henderiw-nephio/nf-injector-controller/pkg/ipam"
 	"github.com/nephio-project/nephio-controller-poc/pkg/porch"
 	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/internal/injector"
 	"github.com/nokia/k8s-ipam/internal/injectors"
 	"github.com/nokia/k8s-ipam/internal/resource"
 	"github.com/nokia/k8s-ipam/internal/shared"
@@ -103,68 +104,92 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			r.l.Error(err, "cannot get resource")
 			return ctrl.Result{}, errors.Wrap(resource.IgnoreNotFound(err), "cannot get resource")
 		}
+		// the package revision is gone, stop the injector if it was running
+		r.injectors.Stop(injector.New(&injector.Config{NamespacedName: req.NamespacedName}))
 		return ctrl.Result{}, nil
 	}
 
-	// we just check for ipam conditions and we dont care if it is satisfied already
-	// this allows us to refresh the ipam allocation status
-	ipamConditions := unsatisfiedConditions(cr.Status.Conditions, ipamConditionType)
-
-	if len(ipamConditions) > 0 {
-		crName := types.NamespacedName{
-			Namespace: cr.Namespace,
-			Name:      cr.Name,
-		}
+	i := injector.New(&injector.Config{
+		InjectorHandler: r.injectIPs,
+		NamespacedName:  req.NamespacedName,
+		Client:          r.Client,
+		PollInterval:    r.pollInterval,
+	})
+
+	// if no IPAM readiness gate, delete the injector if it existed or not
+	// we can stop the reconciliation in this case since there is nothing more to do
+	// a published package revision can no longer be changed, so injection is also stopped
+	if !hasReadinessGate(cr.Spec.ReadinessGates, ipamConditionType) ||
+		cr.Spec.Lifecycle == porchv1alpha1.PackageRevisionLifecyclePublished {
+		r.injectors.Stop(i)
+		r.l.Info("injector stopped", "pr", cr.GetName())
+		return ctrl.Result{}, nil
+	}
 
-		r.l.Info("injector running", "pr", cr.GetName())
-		if err := r.injectIPs(ctx, crName); err != nil {
-			r.l.Error(err, "injection error")
+	// when the readiness gate and all ip allocations are satisfied there is nothing to do,
+	// ip allocations added to the package later have no condition yet and are injected
+	if isConditionSatisfied(cr.Status.Conditions, ipamConditionType) &&
+		len(unsatisfiedConditions(cr.Status.Conditions, ipamConditionType)) == 0 {
+		injected, err := r.allocationsInjected(ctx, cr)
+		if err != nil {
+			r.l.Error(err, "cannot get package revision resources")
 			return ctrl.Result{}, err
 		}
+		if injected {
+			r.l.Info("injector satisfied", "pr", cr.GetName())
+			return ctrl.Result{}, nil
+		}
 	}
 
-	/*
-		crName := types.NamespacedName{
-			Namespace: cr.Namespace,
-			Name:      cr.Name,
-		}
-		i := injector.New(&injector.Config{
-			InjectorHandler: r.injectIPs,
-			NamespacedName:  crName,
-			Client:          r.Client,
-		})
+	// run the injector when the ipam readiness gate is set
+	r.l.Info("injector running", "pr", cr.GetName())
+	r.injectors.Run(i)
 
-		hasIpamReadinessGate := hasReadinessGate(cr.Spec.ReadinessGates, r.kind)
-		// if no IPAM readiness gate, delete the injector if it existed or not
-		// we can stop the reconciliation in this case since there is nothing more to do
-		if !hasIpamReadinessGate {
-			r.injectors.Stop(i)
-			r.l.Info("injector stopped", "pr", cr.GetName())
-			return ctrl.Result{}, nil
+	return ctrl.Result{}, nil
+}
+
+// allocationsInjected returns true when every ip allocation in the package has a
+// satisfied injection condition
+func (r *reconciler) allocationsInjected(ctx context.Context, cr *porchv1alpha1.PackageRevision) (bool, error) {
+	prResources := &porchv1alpha1.PackageRevisionResources{}
+	if err := r.porchClient.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: cr.GetName()}, prResources); err != nil {
+		return false, err
+	}
+	pkgBuf, err := ResourcesToPackageBuffer(prResources.Spec.Resources)
+	if err != nil {
+		return false, err
+	}
+	for _, rn := range pkgBuf.Nodes {
+		if !isIPAllocation(rn) {
+			continue
 		}
+		if !isConditionSatisfied(cr.Status.Conditions, getConditionType(rn)) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
 
-		// run the injector when the ipam readiness gate is set
-		r.l.Info("injector running", "pr", cr.GetName())
-		r.injectors.Run(i)
-	*/
+func isIPAllocation(rn *kyaml.RNode) bool {
+	return rn.GetApiVersion() == "ipam.nephio.org/v1alpha1" && rn.GetKind() == "IPAllocation"
+}
 
-	return ctrl.Result{}, nil
+// getConditionType returns the condition type of the injection of an ip allocation
+func getConditionType(rn *kyaml.RNode) string {
+	namespace := "default"
+	if rn.GetNamespace() != "" {
+		namespace = rn.GetNamespace()
+	}
+	return fmt.Sprintf("%s.%s.%s.Injected", ipamConditionType, rn.GetName(), namespace)
 }
 
 func unsatisfiedConditions(conditions []porchv1alpha1.Condition, conditionType string) []porchv1alpha1.Condition {
 	var uc []porchv1alpha1.Condition
 	for _, c := range conditions {
-
 		// TODO: make this smarter
 		// for now, just check if it is True. It means we won't re-inject if some input changes,
 		// unless someone flips the state
-		/*
-			if c.Status != porchv1alpha1.ConditionTrue && strings.HasPrefix(c.Type, conditionType+".") {
-				uc = append(uc, c)
-			}
-		*/
-		// TBD: for now it is good to refresh the ipam status since it allows to refresh the status of the ipam
-		if strings.HasPrefix(c.Type, conditionType+".") {
+		if c.Status != porchv1alpha1.ConditionTrue && strings.HasPrefix(c.Type, conditionType+".") {
 			uc = append(uc, c)
 		}
 	}
@@ -172,7 +197,6 @@ func unsatisfiedConditions(conditions []porchv1alpha1.Condition, conditionType s
 	return uc
 }
 
-/*
 func hasReadinessGate(gates []porchv1alpha1.ReadinessGate, gate string) bool {
 	for i := range gates {
 		g := gates[i]
@@ -183,20 +207,20 @@ func hasReadinessGate(gates []porchv1alpha1.ReadinessGate, gate string) bool {
 	return false
 }
 
-func hasCondition(conditions []porchv1alpha1.Condition, conditionType string) (*porchv1alpha1.Condition, bool) {
-	for i := range conditions {
-		c := conditions[i]
-		if c.Type == conditionType {
-			return &c, true
+func isConditionSatisfied(conditions []porchv1alpha1.Condition, ct string) bool {
+	for _, c := range conditions {
+		if c.Type == ct {
+			return c.Status == porchv1alpha1.ConditionTrue
 		}
 	}
-	return nil, false
+	return false
 }
-*/
 
 func (r *reconciler) injectIPs(ctx context.Context, namespacedName types.NamespacedName) error {
-	r.l = log.FromContext(ctx)
-	r.l.Info("injector function", "name", namespacedName.String())
+	// the injector function runs in a goroutine per package revision, such
+	// that it does not share the logger of the reconciler
+	l := log.FromContext(ctx)
+	l.Info("injector function", "name", namespacedName.String())
 
 	origPr := &porchv1alpha1.PackageRevision{}
 	if err := r.porchClient.Get(ctx, namespacedName, origPr); err != nil {
@@ -205,13 +229,13 @@ func (r *reconciler) injectIPs(ctx context.Context, namespacedName types.Namespa
 
 	pr := origPr.DeepCopy()
 
-	r.l.Info("injector function", "name", namespacedName.String(), "pr spec", pr.Spec)
+	l.Info("injector function", "name", namespacedName.String(), "pr spec", pr.Spec)
 
 	prConditions := convertConditions(pr.Status.Conditions)
 
 	prResources, pkgBuf, err := r.injectAllocatedIPs(ctx, namespacedName, prConditions, pr)
 	if err != nil {
-		r.l.Error(err, "error allocating or injecting IP(s)")
+		l.Error(err, "error allocating or injecting IP(s)")
 		if pkgBuf == nil {
 			return err
 		}
@@ -228,6 +252,22 @@ func (r *reconciler) injectIPs(ctx context.Context, namespacedName types.Namespa
 		}
 	}
 
+	// the readiness gate is only satisfied when every ip allocation in the package is injected,
+	// this blocks the approval of the package revision until all ip(s) are in place
+	injectErr := err
+	satisfied := injectErr == nil && allocationsSatisfied(*prConditions, ipamConditionType)
+	if satisfied {
+		meta.SetStatusCondition(prConditions, metav1.Condition{Type: ipamConditionType, Status: metav1.ConditionTrue,
+			Reason: "AllocationsSatisfied", Message: "all IP allocations are injected"})
+	} else {
+		msg := "not all IP allocations are injected"
+		if injectErr != nil {
+			msg = injectErr.Error()
+		}
+		meta.SetStatusCondition(prConditions, metav1.Condition{Type: ipamConditionType, Status: metav1.ConditionFalse,
+			Reason: "AllocationsPending", Message: msg})
+	}
+
 	pr.Status.Conditions = unconvertConditions(prConditions)
 
 	// conditions are stored in the Kptfile right now
@@ -255,16 +295,34 @@ func (r *reconciler) injectIPs(ctx context.Context, namespacedName types.Namespa
 		return errors.Wrap(err, "cannot update package revision resources")
 	}
 	prResources.Spec.Resources = newResources
-	if err = r.porchClient.Update(ctx, prResources); err != nil {
+	if err := r.porchClient.Update(ctx, prResources); err != nil {
 		return err
 	}
 
+	// returning an error ensures the injector retries until all ip allocations are satisfied
+	if injectErr != nil {
+		return injectErr
+	}
+	if !satisfied {
+		return fmt.Errorf("ip allocations not satisfied for %s", namespacedName.String())
+	}
 	return nil
 }
 
+// allocationsSatisfied returns true if all ip allocation conditions are true
+func allocationsSatisfied(conditions []metav1.Condition, conditionType string) bool {
+	for _, c := range conditions {
+		if strings.HasPrefix(c.Type, conditionType+".") && c.Status != metav1.ConditionTrue {
+			return false
+		}
+	}
+	return true
+}
+
 func (r *reconciler) injectAllocatedIPs(ctx context.Context, namespacedName types.NamespacedName,
 	prConditions *[]metav1.Condition,
 	pr *porchv1alpha1.PackageRevision) (*porchv1alpha1.PackageRevisionResources, *kio.PackageBuffer, error) {
+	l := log.FromContext(ctx)
 
 	prResources := &porchv1alpha1.PackageRevisionResources{}
 	if err := r.porchClient.Get(ctx, namespacedName, prResources); err != nil {
@@ -272,7 +330,7 @@ func (r *reconciler) injectAllocatedIPs(ctx context.Context, namespacedName type
 	}
 
 	for res, resdata := range prResources.Spec.Resources {
-		r.l.Info("inject and allocate IP(s)", "resource", res, "data", resdata)
+		l.Info("inject and allocate IP(s)", "resource", res, "data", resdata)
 	}
 
 	// need to fix back to when the PR is fixed
@@ -282,8 +340,8 @@ func (r *reconciler) injectAllocatedIPs(ctx context.Context, namespacedName type
 	}
 
 	for i, rn := range pkgBuf.Nodes {
-		r.l.Info("resource", "apiVersion", rn.GetApiVersion(), "kind", rn.GetKind())
-		if rn.GetApiVersion() == "ipam.nephio.org/v1alpha1" && rn.GetKind() == "IPAllocation" {
+		l.Info("resource", "apiVersion", rn.GetApiVersion(), "kind", rn.GetKind())
+		if isIPAllocation(rn) {
 
 			namespace := "default"
 			if rn.GetNamespace() != "" {
@@ -301,7 +359,9 @@ func (r *reconciler) injectAllocatedIPs(ctx context.Context, namespacedName type
 			if err != nil {
 				return prResources, pkgBuf, err
 			}
-			r.l.Info("grpc ipam allocation request", "Name", rn.GetName(), "Labels", rn.GetLabels(), "Spec", grpcAllocSpec)
+			l.Info("grpc ipam allocation request", "Name", rn.GetName(), "Labels", rn.GetLabels(), "Spec", grpcAllocSpec)
+
+			conditionType := getConditionType(rn)
 
 			// grpc allocation request
 			// we always refresh the ipallocation even if it was already satisfied,
@@ -314,11 +374,18 @@ func (r *reconciler) injectAllocatedIPs(ctx context.Context, namespacedName type
 				Spec:      grpcAllocSpec,
 			})
 			if err != nil {
-				r.l.Error(err, "grpc ipam allocation request error")
+				l.Error(err, "grpc ipam allocation request error")
+				meta.SetStatusCondition(prConditions, metav1.Condition{Type: conditionType, Status: metav1.ConditionFalse,
+					Reason: "AllocationFailed", Message: err.Error()})
 				return prResources, pkgBuf, errors.Wrap(err, "cannot allocate ip")
 			}
 
-			r.l.Info("grpc ipam allocation response", "Name", rn.GetName(), "resp", resp)
+			l.Info("grpc ipam allocation response", "Name", rn.GetName(), "resp", resp)
+			if resp.GetAllocatedPrefix() == "" {
+				meta.SetStatusCondition(prConditions, metav1.Condition{Type: conditionType, Status: metav1.ConditionFalse,
+					Reason: "AllocationPending", Message: "no prefix allocated"})
+				continue
+			}
 
 			// update Allocation
 			ipAllocation, err := GetUpdatedAllocation(resp, ipamv1alpha1.PrefixKind(ipAllocSpec.PrefixKind))
@@ -328,10 +395,9 @@ func (r *reconciler) injectAllocatedIPs(ctx context.Context, namespacedName type
 
 			// update only the status in the allocation
 			n := pkgBuf.Nodes[i]
-			conditionType := fmt.Sprintf("%s.%s.%s.Injected", ipamConditionType, rn.GetName(), namespace)
 			field := ipAllocation.Field("status")
 			if err := n.SetMapField(field.Value, "status"); err != nil {
-				r.l.Error(err, "could not set IPAllocation.status")
+				l.Error(err, "could not set IPAllocation.status")
 				meta.SetStatusCondition(prConditions, metav1.Condition{Type: conditionType, Status: metav1.ConditionFalse,
 					Reason: "ResourceSpecErr", Message: err.Error()})
 				return prResources, pkgBuf, err
@@ -340,7 +406,7 @@ func (r *reconciler) injectAllocatedIPs(ctx context.Context, namespacedName type
 			pkgBuf.Nodes[i] = n
 
 			// we always update the status to reflect the latest allocations
-			r.l.Info("setting condition", "conditionType", conditionType)
+			l.Info("setting condition", "conditionType", conditionType)
 			meta.SetStatusCondition(prConditions, metav1.Condition{Type: conditionType, Status: metav1.ConditionTrue,
 				Reason: "ResourceInjected", Message: "Injected IP allocation"})
 		}
@@ -391,17 +457,6 @@ func unconvertConditions(conditions *[]metav1.Condition) []porchv1alpha1.Conditi
 	return prConditions
 }
 
-/*
-func isConditionSatisfied(conditions []porchv1alpha1.Condition, ct string) bool {
-	for _, c := range conditions {
-		if c.Type == ct {
-			return c.Status == porchv1alpha1.ConditionTrue
-		}
-	}
-	return false
-}
-*/
-
 func getIpAllocationSpec(rn *kyaml.RNode) (*ipamv1alpha1.IPAllocationSpec, error) {
 	o, err := fn.ParseKubeObject([]byte(rn.MustString()))
 	if err != nil {
@@ -425,8 +480,17 @@ func getGrpcAllocationSpec(ipAllocSpec *ipamv1alpha1.IPAllocationSpec) (*allocpb
 	case string(ipamv1alpha1.PrefixKindNetwork):
 	case string(ipamv1alpha1.PrefixKindPool):
 		allocSpec.PrefixLength = uint32(ipAllocSpec.PrefixLength)
+	case string(ipamv1alpha1.PrefixKindLink):
+		allocSpec.PrefixLength = uint32(ipAllocSpec.PrefixLength)
+		for _, ep := range ipAllocSpec.Endpoints {
+			allocSpec.Endpoints = append(allocSpec.Endpoints, &allocpb.Endpoint{
+				Node:      ep.Node,
+				Interface: ep.Interface,
+			})
+		}
 	default:
-		return nil, fmt.Errorf("unknown prefixkind: %s", ipAllocSpec.PrefixKind)
+		// prefix kinds registered with the ipam are validated by the ipam
+		allocSpec.PrefixLength = uint32(ipAllocSpec.PrefixLength)
 	}
 	return allocSpec, nil
 }
@@ -435,7 +499,11 @@ func GetUpdatedAllocation(resp *allocpb.Response, prefixKind ipamv1alpha1.Prefix
 	// update prefix status with the allocated prefix
 	ipAlloc := &ipamv1alpha1.IPAllocation{
 		Status: ipamv1alpha1.IPAllocationStatus{
-			AllocatedPrefix: resp.GetAllocatedPrefix(),
+			AllocatedPrefix:    resp.GetAllocatedPrefix(),
+			ParentPrefix:       resp.GetParentPrefix(),
+			RouteDistinguisher: resp.GetRouteDistinguisher(),
+			ImportRouteTargets: resp.GetImportRouteTargets(),
+			ExportRouteTargets: resp.GetExportRouteTargets(),
 		},
 	}
 
@@ -446,7 +514,24 @@ func GetUpdatedAllocation(resp *allocpb.Response, prefixKind ipamv1alpha1.Prefix
 		// update gateway status with the allocated gateway
 		// only relevant for prefixkind = network
 		ipAlloc.Status.Gateway = resp.GetGateway()
+		for _, gw := range resp.GetGateways() {
+			ipAlloc.Status.Gateways = append(ipAlloc.Status.Gateways, ipamv1alpha1.Gateway{
+				Address: gw.GetAddress(),
+				Role:    ipamv1alpha1.GatewayRole(gw.GetRole()),
+			})
+		}
 	case ipamv1alpha1.PrefixKindPool:
+	case ipamv1alpha1.PrefixKindLink:
+		// update the endpoints with the allocated addresses
+		for _, ep := range resp.GetEndpoints() {
+			ipAlloc.Status.Endpoints = append(ipAlloc.Status.Endpoints, ipamv1alpha1.AllocatedLinkEndpoint{
+				LinkEndpoint: ipamv1alpha1.LinkEndpoint{
+					Node:      ep.GetNode(),
+					Interface: ep.GetInterface(),
+				},
+				Address: ep.GetAddress(),
+			})
+		}
 	}
 
 	b, err := kyaml.Marshal(ipAlloc)
@@ -0,0 +1,133 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nestingpolicy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/go-logr/logr"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/internal/ipam"
+	"github.com/nokia/k8s-ipam/internal/meta"
+	"github.com/nokia/k8s-ipam/internal/resource"
+	"github.com/nokia/k8s-ipam/internal/shared"
+	"github.com/pkg/errors"
+)
+
+const (
+	finalizer = "ipam.nephio.org/finalizer"
+	// errors
+	errGetCr        = "cannot get resource"
+	errUpdateStatus = "cannot update status"
+)
+
+//+kubebuilder:rbac:groups=ipam.nephio.org,resources=nestingpolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=ipam.nephio.org,resources=nestingpolicies/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=ipam.nephio.org,resources=nestingpolicies/finalizers,verbs=update
+
+// SetupWithManager sets up the controller with the Manager.
+func Setup(mgr ctrl.Manager, options *shared.Options) error {
+	r := &reconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Ipam:         options.Ipam,
+		pollInterval: options.Poll,
+		finalizer:    resource.NewAPIFinalizer(mgr.GetClient(), finalizer),
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&ipamv1alpha1.NestingPolicy{}).
+		Complete(r)
+}
+
+// reconciler reconciles a NestingPolicy object
+type reconciler struct {
+	client.Client
+	Scheme       *runtime.Scheme
+	Ipam         ipam.Ipam
+	pollInterval time.Duration
+	finalizer    *resource.APIFinalizer
+
+	l logr.Logger
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.l = log.FromContext(ctx)
+	r.l.Info("reconcile", "req", req)
+
+	cr := &ipamv1alpha1.NestingPolicy{}
+	if err := r.Get(ctx, req.NamespacedName, cr); err != nil {
+		// There's no need to requeue if we no longer exist. Otherwise we'll be
+		// requeued implicitly because we return an error.
+		if resource.IgnoreNotFound(err) != nil {
+			r.l.Error(err, errGetCr)
+			return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetCr)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// only the default nesting policy is applied to the ipam
+	if cr.GetName() != ipamv1alpha1.NestingPolicyDefaultName {
+		cr.SetConditions(ipamv1alpha1.ReconcileSuccess(),
+			ipamv1alpha1.Failed(fmt.Sprintf("only the nesting policy named %s is applied", ipamv1alpha1.NestingPolicyDefaultName)))
+		return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+
+	if meta.WasDeleted(cr) {
+		// When the nesting policy is deleted the ipam falls back to the default nesting rules
+		if err := r.Ipam.SetNestingPolicy(nil); err != nil {
+			r.l.Error(err, "cannot restore default nesting policy")
+		}
+
+		if err := r.finalizer.RemoveFinalizer(ctx, cr); err != nil {
+			r.l.Error(err, "cannot remove finalizer")
+			cr.SetConditions(ipamv1alpha1.ReconcileError(err), ipamv1alpha1.Unknown())
+			return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		}
+
+		r.l.Info("Successfully deleted resource")
+		return ctrl.Result{Requeue: false}, nil
+	}
+
+	if err := r.finalizer.AddFinalizer(ctx, cr); err != nil {
+		// If this is the first time we encounter this issue we'll be requeued
+		// implicitly when we update our status with the new error condition. If
+		// not, we requeue explicitly, which will trigger backoff.
+		r.l.Error(err, "cannot add finalizer")
+		cr.SetConditions(ipamv1alpha1.ReconcileError(err), ipamv1alpha1.Unknown())
+		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+
+	// apply the nesting rules, an invalid policy leaves the current rules in place
+	if err := r.Ipam.SetNestingPolicy(cr.Spec.Rules); err != nil {
+		r.l.Info("cannot apply nesting policy", "err", err)
+		cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.Failed(err.Error()))
+		return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+
+	// Update the status of the CR and end the reconciliation loop
+	cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.Ready())
+	return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+}
@@ -18,6 +18,7 @@ package networkinstance
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -25,13 +26,16 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/go-logr/logr"
 	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/internal/event"
 	"github.com/nokia/k8s-ipam/internal/ipam"
 	"github.com/nokia/k8s-ipam/internal/meta"
 	"github.com/nokia/k8s-ipam/internal/resource"
 	"github.com/nokia/k8s-ipam/internal/shared"
+	"github.com/nokia/k8s-ipam/internal/vrf"
 	"github.com/pkg/errors"
 )
 
@@ -47,6 +51,11 @@ const (
 //+kubebuilder:rbac:groups=ipam.nephio.org,resources=networkinstances,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=ipam.nephio.org,resources=networkinstances/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=ipam.nephio.org,resources=networkinstances/finalizers,verbs=update
+//+kubebuilder:rbac:groups=ipam.nephio.org,resources=ipprefixes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=ipam.nephio.org,resources=ipprefixes/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=ipam.nephio.org,resources=ipallocations,verbs=get;list;watch
+//+kubebuilder:rbac:groups=ipam.nephio.org,resources=ipallocations/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=*,resources=networkinstances,verbs=get;list;watch
 
 // SetupWithManager sets up the controller with the Manager.
@@ -55,12 +64,21 @@ func Setup(mgr ctrl.Manager, options *shared.Options) error {
 		Client:       mgr.GetClient(),
 		Scheme:       mgr.GetScheme(),
 		Ipam:         options.Ipam,
+		Vrf:          options.Vrf,
 		pollInterval: options.Poll,
 		finalizer:    resource.NewAPIFinalizer(mgr.GetClient(), finalizer),
+		recorder:     event.NewRecorder(mgr.GetEventRecorderFor("networkinstance-controller")),
+	}
+
+	dependentHandler := &EnqueueRequestForDependents{
+		client: mgr.GetClient(),
+		ctx:    context.Background(),
 	}
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&ipamv1alpha1.NetworkInstance{}).
+		Watches(&source.Kind{Type: &ipamv1alpha1.IPPrefix{}}, dependentHandler).
+		Watches(&source.Kind{Type: &ipamv1alpha1.IPAllocation{}}, dependentHandler).
 		Complete(r)
 }
 
@@ -69,8 +87,10 @@ type reconciler struct {
 	client.Client
 	Scheme       *runtime.Scheme
 	Ipam         ipam.Ipam
+	Vrf          vrf.Allocator
 	pollInterval time.Duration
 	finalizer    *resource.APIFinalizer
+	recorder     event.Recorder
 
 	l logr.Logger
 }
@@ -91,9 +111,33 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 
 	if meta.WasDeleted(cr) {
+		// the network instance is only released once its prefixes and allocations
+		// are deleted, unless the force annotation is set
+		dependents, err := r.getDependents(ctx, cr)
+		if err != nil {
+			r.l.Error(err, "cannot get dependents")
+			cr.SetConditions(ipamv1alpha1.ReconcileError(err), ipamv1alpha1.Unknown())
+			return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		}
+		if err := r.setDependentsNotReady(ctx, dependents); err != nil {
+			r.l.Error(err, "cannot update dependents")
+			cr.SetConditions(ipamv1alpha1.ReconcileError(err), ipamv1alpha1.Unknown())
+			return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		}
+		cr.Status.Dependents = getDependentNames(dependents)
+		if len(dependents) > 0 && cr.GetAnnotations()[ipamv1alpha1.NetworkInstanceForceDeleteAnnotation] != "true" {
+			r.l.Info("network instance terminating", "dependents", len(dependents))
+			r.recorder.Normal(cr, event.ReasonTerminating, "waiting for %d dependents to be deleted", len(dependents))
+			cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.Terminating(fmt.Sprintf("waiting for %d dependents", len(dependents))))
+			return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		}
+
 		// When the network instance is deleted we can remove the network instance entry
 		// from th IPAM table
-		r.Ipam.Delete(req.NamespacedName.String())
+		r.Ipam.Delete(cr.GetName())
+		// release the route distinguisher and route targets for other network instances
+		r.Vrf.Release(cr.GetName())
+		r.recorder.Normal(cr, event.ReasonReleased, "released network instance %s", cr.GetName())
 
 		if err := r.finalizer.RemoveFinalizer(ctx, cr); err != nil {
 			r.l.Error(err, "cannot remove finalizer")
@@ -101,6 +145,7 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 		}
 
+		r.recorder.Forget(cr)
 		r.l.Info("Successfully deleted resource")
 		return ctrl.Result{Requeue: false}, nil
 	}
@@ -117,10 +162,27 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	// create and initialize the IPAM with the network instance if it does not exist
 	if err := r.Ipam.Init(ctx, cr); err != nil {
 		r.l.Error(err, "cannot initialize finalizer")
+		r.recorder.Warning(cr, event.ReasonNetworkInstanceNotReady, "cannot initialize network instance: %s", err.Error())
 		cr.SetConditions(ipamv1alpha1.ReconcileError(err), ipamv1alpha1.Unknown())
 		return ctrl.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
+	// allocate the route distinguisher and route targets, which are recorded in the status
+	if _, err := r.Vrf.Allocate(ctx, cr); err != nil {
+		r.l.Error(err, "cannot allocate route distinguisher and route targets")
+		r.recorder.Warning(cr, event.ReasonNetworkInstanceNotReady, "cannot allocate route distinguisher and route targets: %s", err.Error())
+		cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.Failed(err.Error()))
+		return ctrl.Result{RequeueAfter: r.pollInterval}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+
+	// share the exported prefixes with the network instances that import them
+	if err := r.Ipam.SetImports(ctx, cr); err != nil {
+		r.l.Error(err, "cannot import prefixes")
+		r.recorder.Warning(cr, event.ReasonNetworkInstanceNotReady, "cannot import prefixes: %s", err.Error())
+		cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.Failed(err.Error()))
+		return ctrl.Result{RequeueAfter: r.pollInterval}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+
 	/*
 		DEBUG the routing table
 		rt, ok := r.Ipam.Get(req.NamespacedName.String())
@@ -134,6 +196,10 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		}
 	*/
 
+	if cr.Status.RouteDistinguisher != "" {
+		r.recorder.Normal(cr, event.ReasonAllocated, "allocated route distinguisher %s", cr.Status.RouteDistinguisher)
+	}
+
 	// Update the status of the CR and end the reconciliation loop
 	cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.Ready())
 	return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
@@ -0,0 +1,104 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkinstance
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/internal/meta"
+	"github.com/nokia/k8s-ipam/internal/shared"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	errListDependents     = "cannot list dependents"
+	errUpdateDependents   = "cannot update dependents"
+	msgNetworkTerminating = "network-instance terminating"
+)
+
+// dependent is an IPPrefix or IPAllocation of the network instance
+type dependent interface {
+	client.Object
+	GetCondition(ck ipamv1alpha1.ConditionKind) ipamv1alpha1.Condition
+	SetConditions(c ...ipamv1alpha1.Condition)
+}
+
+// getDependents returns the IPPrefix and IPAllocation resources that are
+// allocated in the network instance
+func (r *reconciler) getDependents(ctx context.Context, cr *ipamv1alpha1.NetworkInstance) ([]dependent, error) {
+	dependents := []dependent{}
+
+	opts := []client.ListOption{
+		client.InNamespace(cr.GetNamespace()),
+		client.MatchingFields{shared.NetworkInstanceIndex: cr.GetName()},
+	}
+
+	prefixes := &ipamv1alpha1.IPPrefixList{}
+	if err := r.List(ctx, prefixes, opts...); err != nil {
+		return nil, errors.Wrap(err, errListDependents)
+	}
+	for i := range prefixes.Items {
+		dependents = append(dependents, &prefixes.Items[i])
+	}
+
+	allocs := &ipamv1alpha1.IPAllocationList{}
+	if err := r.List(ctx, allocs, opts...); err != nil {
+		return nil, errors.Wrap(err, errListDependents)
+	}
+	for i := range allocs.Items {
+		dependents = append(dependents, &allocs.Items[i])
+	}
+	return dependents, nil
+}
+
+// setDependentsNotReady sets the NotReady condition on the dependents, such
+// that they report the teardown of the network instance right away instead of
+// on their next reconciliation
+func (r *reconciler) setDependentsNotReady(ctx context.Context, dependents []dependent) error {
+	for _, d := range dependents {
+		if meta.WasDeleted(d) {
+			continue
+		}
+		c := d.GetCondition(ipamv1alpha1.ConditionKindReady)
+		if c.Reason == ipamv1alpha1.ConditionReasonNotReady {
+			continue
+		}
+		d.SetConditions(ipamv1alpha1.NotReady(msgNetworkTerminating))
+		if err := r.Status().Update(ctx, d); client.IgnoreNotFound(err) != nil {
+			return errors.Wrap(err, errUpdateDependents)
+		}
+	}
+	return nil
+}
+
+// getDependentNames returns the dependents as kind/name
+func getDependentNames(dependents []dependent) []string {
+	names := make([]string, 0, len(dependents))
+	for _, d := range dependents {
+		kind := ipamv1alpha1.IPPrefixKind
+		if _, ok := d.(*ipamv1alpha1.IPAllocation); ok {
+			kind = ipamv1alpha1.IPAllocationKind
+		}
+		names = append(names, strings.Join([]string{kind, d.GetName()}, "/"))
+	}
+	sort.Strings(names)
+	return names
+}
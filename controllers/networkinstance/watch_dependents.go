@@ -0,0 +1,90 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkinstance
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type adder interface {
+	Add(item interface{})
+}
+
+// EnqueueRequestForDependents enqueues the network instance of an IPPrefix or
+// IPAllocation that is deleted, such that a terminating network instance is
+// released once its last prefix or allocation is gone
+type EnqueueRequestForDependents struct {
+	client client.Client
+	l      logr.Logger
+	ctx    context.Context
+}
+
+// Create does not enqueue
+func (e *EnqueueRequestForDependents) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+}
+
+// Update does not enqueue
+func (e *EnqueueRequestForDependents) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+}
+
+// Delete enqueues the network instance of the deleted resource
+func (e *EnqueueRequestForDependents) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Generic does not enqueue
+func (e *EnqueueRequestForDependents) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+}
+
+func (e *EnqueueRequestForDependents) add(obj client.Object, queue adder) {
+	e.l = log.FromContext(e.ctx)
+
+	var niName string
+	switch cr := obj.(type) {
+	case *ipamv1alpha1.IPPrefix:
+		niName = cr.Spec.NetworkInstance
+	case *ipamv1alpha1.IPAllocation:
+		if cr.Spec.Selector != nil {
+			niName = cr.Spec.Selector.MatchLabels[ipamv1alpha1.NephioNetworkInstanceKey]
+		}
+	}
+	if niName == "" {
+		return
+	}
+
+	ni := &ipamv1alpha1.NetworkInstance{}
+	if err := e.client.Get(e.ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: niName}, ni); err != nil {
+		return
+	}
+	// only a terminating network instance waits for its dependents
+	if ni.GetDeletionTimestamp().IsZero() {
+		return
+	}
+	e.l.Info("event requeue network instance", "name", niName, "dependent", obj.GetName())
+	queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{
+		Namespace: ni.GetNamespace(),
+		Name:      ni.GetName()}})
+}
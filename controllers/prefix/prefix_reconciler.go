@@ -18,7 +18,7 @@ package prefix
 
 import (
 	"context"
-	"strings"
+	"fmt"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -28,9 +28,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/go-logr/logr"
 	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/internal/event"
 	"github.com/nokia/k8s-ipam/internal/ipam"
 	"github.com/nokia/k8s-ipam/internal/meta"
 	"github.com/nokia/k8s-ipam/internal/resource"
@@ -50,6 +52,8 @@ const (
 //+kubebuilder:rbac:groups=ipam.nephio.org,resources=ipprefixes,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=ipam.nephio.org,resources=ipprefixes/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=ipam.nephio.org,resources=ipprefixes/finalizers,verbs=update
+//+kubebuilder:rbac:groups=ipam.nephio.org,resources=ipallocations,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=*,resources=networkinstances,verbs=get;list;watch
 
 // SetupWithManager sets up the controller with the Manager.
@@ -60,18 +64,28 @@ func Setup(mgr ctrl.Manager, options *shared.Options) error {
 		Ipam:         options.Ipam,
 		pollInterval: options.Poll,
 		finalizer:    resource.NewAPIFinalizer(mgr.GetClient(), finalizer),
+		recorder:     event.NewRecorder(mgr.GetEventRecorderFor("ipprefix-controller")),
 	}
 
-	/*
-		niHandler := &EnqueueRequestForAllNetworkInstances{
-			client: mgr.GetClient(),
-			ctx:    context.Background(),
-		}
-	*/
+	niHandler := &EnqueueRequestForAllNetworkInstances{
+		client: mgr.GetClient(),
+		ctx:    context.Background(),
+	}
+	prefixHandler := &EnqueueRequestForPendingPrefixes{
+		client: mgr.GetClient(),
+		ctx:    context.Background(),
+	}
+	parentHandler := &EnqueueRequestForParentPrefixes{
+		client: mgr.GetClient(),
+		ctx:    context.Background(),
+	}
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&ipamv1alpha1.IPPrefix{}).
-		//Watches(&source.Kind{Type: &ipamv1alpha1.NetworkInstance{}}, niHandler).
+		Watches(&source.Kind{Type: &ipamv1alpha1.NetworkInstance{}}, niHandler).
+		Watches(&source.Kind{Type: &ipamv1alpha1.IPPrefix{}}, prefixHandler).
+		Watches(&source.Kind{Type: &ipamv1alpha1.IPPrefix{}}, parentHandler).
+		Watches(&source.Kind{Type: &ipamv1alpha1.IPAllocation{}}, parentHandler).
 		Complete(r)
 }
 
@@ -82,6 +96,7 @@ type reconciler struct {
 	Ipam         ipam.Ipam
 	pollInterval time.Duration
 	finalizer    *resource.APIFinalizer
+	recorder     event.Recorder
 
 	l logr.Logger
 }
@@ -109,13 +124,42 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		// if the prefix condition is false it means the prefix was not supplied to the network
 		// we can delete it w/o deleting it from the IPAM
 		if cr.GetCondition(ipamv1alpha1.ConditionKindReady).Status == corev1.ConditionTrue {
+			// the prefix is only released once no prefixes or allocations are nested
+			// in it, unless the cascade annotation asks to release them first
+			dependents, err := r.Ipam.GetDependents(ctx, ipam.BuildAllocationFromIPPrefix(cr))
+			if err != nil {
+				r.l.Error(err, "cannot get dependents")
+				cr.SetConditions(ipamv1alpha1.ReconcileError(err), ipamv1alpha1.Unknown())
+				return reconcile.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+			}
+			cr.Status.Dependents = dependents
+			if len(dependents) > 0 {
+				if cr.GetAnnotations()[ipamv1alpha1.IPPrefixCascadeDeleteAnnotation] != "true" {
+					r.l.Info("deletion blocked by dependents", "dependents", len(dependents))
+					r.recorder.Warning(cr, event.ReasonDeletionBlocked, "deletion blocked by %d dependents", len(dependents))
+					cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.Failed(fmt.Sprintf("deletion blocked by %d dependents", len(dependents))))
+					return reconcile.Result{RequeueAfter: r.pollInterval}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+				}
+				r.recorder.Normal(cr, event.ReasonCascadeDelete, "releasing %d dependents", len(dependents))
+				if err := r.releaseDependents(ctx, cr, dependents); err != nil {
+					r.l.Error(err, "cannot release dependents")
+					cr.SetConditions(ipamv1alpha1.ReconcileError(err), ipamv1alpha1.Unknown())
+					return reconcile.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+				}
+				// the dependents are released by their own reconcilers, we check
+				// again later
+				cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.Failed(fmt.Sprintf("releasing %d dependents", len(dependents))))
+				return reconcile.Result{RequeueAfter: r.pollInterval}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+			}
+
 			if err := r.Ipam.DeAllocateIPPrefix(ctx, ipam.BuildAllocationFromIPPrefix(cr)); err != nil {
-				if !strings.Contains(err.Error(), "not ready") || !strings.Contains(err.Error(), "not found") {
+				if !errors.Is(err, ipam.ErrNetworkInstanceNotReady) && !errors.Is(err, ipam.ErrNotFound) {
 					r.l.Error(err, "cannot delete resource")
 					cr.SetConditions(ipamv1alpha1.ReconcileError(err), ipamv1alpha1.Unknown())
 					return reconcile.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 				}
 			}
+			r.recorder.Normal(cr, event.ReasonReleased, "released prefix %s", cr.Status.AllocatedPrefix)
 		}
 
 		if err := r.finalizer.RemoveFinalizer(ctx, cr); err != nil {
@@ -124,6 +168,7 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			return reconcile.Result{Requeue: true}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 		}
 
+		r.recorder.Forget(cr)
 		r.l.Info("Successfully deleted resource")
 		return reconcile.Result{Requeue: false}, nil
 	}
@@ -144,45 +189,144 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		// There's no need to requeue if we no longer exist. Otherwise we'll be
 		// requeued implicitly because we return an error.
 		r.l.Info("cannot allocate prefix, network-intance not found")
+		r.recorder.Warning(cr, event.ReasonNetworkInstanceNotReady, "network instance %s not found", cr.Spec.NetworkInstance)
 		cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.Failed("network-instance not found"))
-		return ctrl.Result{RequeueAfter: 5 * time.Second}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		// the network instance watch enqueues the prefix once the network instance is ready
+		return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
 	// check deletion timestamp
 	if meta.WasDeleted(ni) {
 		r.l.Info("cannot allocate prefix, network-intance not ready")
-		cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.Failed("network-instance not ready"))
-		return ctrl.Result{RequeueAfter: 5 * time.Second}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		r.recorder.Warning(cr, event.ReasonNetworkInstanceNotReady, "network instance %s is being deleted", cr.Spec.NetworkInstance)
+		cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.NotReady("network-instance terminating"))
+		// the network instance watch enqueues the prefix once the network instance is deleted
+		return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
 	// The spec got changed we check the existing prefix against the status
-	if (cr.Status.AllocatedPrefix != "" && cr.Status.AllocatedPrefix != cr.Spec.Prefix) ||
-		(cr.Status.AllocatedNetwork != "" && cr.Status.AllocatedNetwork != cr.Spec.Network) {
+	var dependents []ipamv1alpha1.PrefixDependent
+	changed := (cr.Status.AllocatedPrefix != "" && cr.Status.AllocatedPrefix != cr.Spec.Prefix) ||
+		(cr.Status.AllocatedNetwork != "" && cr.Status.AllocatedNetwork != cr.Spec.Network)
+	if changed {
+		// the dependents of the existing prefix are validated again once the
+		// prefix is changed
+		oldAlloc := ipam.BuildAllocationFromIPPrefix(cr)
+		if cr.Status.AllocatedPrefix != "" {
+			oldAlloc.Prefix = cr.Status.AllocatedPrefix
+		}
+		oldAlloc.Network = cr.Status.AllocatedNetwork
+		var err error
+		dependents, err = r.Ipam.GetDependents(ctx, oldAlloc)
+		if err != nil {
+			r.l.Error(err, "cannot get dependents")
+			cr.SetConditions(ipamv1alpha1.ReconcileError(err), ipamv1alpha1.Unknown())
+			return reconcile.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		}
 		if err := r.Ipam.DeAllocateIPPrefix(ctx, ipam.BuildAllocationFromIPPrefix(cr)); err != nil {
-			if !strings.Contains(err.Error(), "not ready") || !strings.Contains(err.Error(), "not found") {
+			if !errors.Is(err, ipam.ErrNetworkInstanceNotReady) && !errors.Is(err, ipam.ErrNotFound) {
 				r.l.Error(err, "cannot delete resource")
 				cr.SetConditions(ipamv1alpha1.ReconcileError(err), ipamv1alpha1.Unknown())
 				return reconcile.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 			}
 		}
+		r.recorder.Normal(cr, event.ReasonReleased, "released prefix %s", cr.Status.AllocatedPrefix)
 	}
 	allocatedPrefix, err := r.Ipam.AllocateIPPrefix(ctx, ipam.BuildAllocationFromIPPrefix(cr))
 	if err != nil {
 		r.l.Info("cannot allocate prefix", "err", err)
-		cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.Failed(err.Error()))
-		return reconcile.Result{RequeueAfter: 5 * time.Second}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		r.recorder.Warning(cr, event.GetFailureReason(err), "cannot allocate prefix %s: %s", cr.Spec.Prefix, err.Error())
+		if errors.Is(err, ipam.ErrQuotaExceeded) {
+			cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.QuotaExceeded(err.Error()))
+			return reconcile.Result{RequeueAfter: r.pollInterval}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		}
+		cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.FailedWithReason(ipam.GetConditionReason(err), err.Error()))
+		// the prefix watch enqueues the prefix once another prefix of the network
+		// instance is allocated, changed or released
+		return reconcile.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 	}
 	if allocatedPrefix.AllocatedPrefix != cr.Spec.Prefix {
 		//we got a different prefix than requested
 		r.l.Error(err, "prefix allocation failed", "requested", cr.Spec.Prefix, "allocated", *allocatedPrefix)
 		cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.Unknown())
-		return ctrl.Result{RequeueAfter: 5 * time.Second}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		return ctrl.Result{RequeueAfter: r.pollInterval}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+	}
+
+	if changed {
+		if err := r.revalidateDependents(ctx, cr, dependents); err != nil {
+			r.l.Error(err, "cannot revalidate dependents")
+			cr.SetConditions(ipamv1alpha1.ReconcileError(err), ipamv1alpha1.Unknown())
+			return reconcile.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
+		}
 	}
 
 	r.l.Info("Successfully reconciled resource")
+	r.recorder.Normal(cr, event.ReasonAllocated, "allocated prefix %s in network instance %s", cr.Spec.Prefix, cr.Spec.NetworkInstance)
 	cr.Status.AllocatedPrefix = cr.Spec.Prefix
 	// only relevant for prefixkind network but does not harm
 	cr.Status.AllocatedNetwork = cr.Spec.Network
+	cr.Status.Dependents = nil
+	// list the prefixes and allocations nested in the prefix
+	allocations, err := r.Ipam.GetDependents(ctx, ipam.BuildAllocationFromIPPrefix(cr))
+	if err != nil {
+		r.l.Error(err, "cannot get allocations")
+	}
+	cr.Status.Allocations = allocations
 	cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.Ready())
 	return ctrl.Result{}, errors.Wrap(r.Status().Update(ctx, cr), errUpdateStatus)
 }
+
+// releaseDependents deletes the IPPrefix and IPAllocation resources of the
+// dependents; dependents without a resource, e.g. allocated over grpc, are
+// released in the ipam directly
+func (r *reconciler) releaseDependents(ctx context.Context, cr *ipamv1alpha1.IPPrefix, dependents []ipamv1alpha1.PrefixDependent) error {
+	for _, d := range dependents {
+		nsn := types.NamespacedName{Namespace: d.Namespace, Name: d.Name}
+		if nsn.Namespace == cr.GetNamespace() && nsn.Name == cr.GetName() {
+			continue
+		}
+		if nsn.Namespace != "" {
+			found, err := r.deleteDependent(ctx, nsn, &ipamv1alpha1.IPAllocation{})
+			if err != nil {
+				return err
+			}
+			if found {
+				continue
+			}
+			found, err = r.deleteDependent(ctx, nsn, &ipamv1alpha1.IPPrefix{})
+			if err != nil {
+				return err
+			}
+			if found {
+				continue
+			}
+		}
+		if err := r.Ipam.DeAllocateIPPrefix(ctx, &ipam.Allocation{
+			NamespacedName:  nsn,
+			NetworkInstance: d.NetworkInstance,
+			PrefixKind:      ipamv1alpha1.PrefixKind(d.PrefixKind),
+			Prefix:          d.Prefix,
+		}); err != nil {
+			return errors.Wrapf(err, "cannot release dependent %s", nsn.String())
+		}
+	}
+	return nil
+}
+
+// deleteDependent deletes the resource of a dependent and returns false when
+// the resource does not exist
+func (r *reconciler) deleteDependent(ctx context.Context, nsn types.NamespacedName, o client.Object) (bool, error) {
+	if err := r.Get(ctx, nsn, o); err != nil {
+		if resource.IgnoreNotFound(err) != nil {
+			return false, errors.Wrapf(err, "cannot get dependent %s", nsn.String())
+		}
+		return false, nil
+	}
+	if meta.WasDeleted(o) {
+		return true, nil
+	}
+	if err := r.Delete(ctx, o); resource.IgnoreNotFound(err) != nil {
+		return true, errors.Wrapf(err, "cannot delete dependent %s", nsn.String())
+	}
+	return true, nil
+}
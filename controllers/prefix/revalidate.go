@@ -0,0 +1,117 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"context"
+
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/internal/event"
+	"github.com/nokia/k8s-ipam/internal/ipam"
+	"github.com/nokia/k8s-ipam/internal/resource"
+	"github.com/nokia/k8s-ipam/internal/shared"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// conditionedObject is an IPPrefix or IPAllocation
+type conditionedObject interface {
+	client.Object
+	shared.Conditioned
+	SetConditions(c ...ipamv1alpha1.Condition)
+}
+
+// revalidateDependents validates the dependents of the prefix before and
+// after the change of the prefix, and reflects the outcome in the conditions
+// of their IPPrefix and IPAllocation resources
+func (r *reconciler) revalidateDependents(ctx context.Context, cr *ipamv1alpha1.IPPrefix, dependents []ipamv1alpha1.PrefixDependent) error {
+	alloc := ipam.BuildAllocationFromIPPrefix(cr)
+	newDependents, err := r.Ipam.GetDependents(ctx, alloc)
+	if err != nil {
+		return err
+	}
+	// dependents of the new prefix might have been invalidated by an earlier change
+	seen := map[types.NamespacedName]struct{}{}
+	all := []ipamv1alpha1.PrefixDependent{}
+	for _, d := range append(dependents, newDependents...) {
+		nsn := types.NamespacedName{Namespace: d.Namespace, Name: d.Name}
+		if _, ok := seen[nsn]; ok {
+			continue
+		}
+		seen[nsn] = struct{}{}
+		all = append(all, d)
+	}
+	if len(all) == 0 {
+		return nil
+	}
+
+	migrate := cr.GetAnnotations()[ipamv1alpha1.IPPrefixMigrateAllocationsAnnotation] == "true"
+	results, err := r.Ipam.RevalidateDependents(ctx, alloc, all, migrate)
+	if err != nil {
+		return err
+	}
+
+	invalid, migrated := 0, 0
+	for _, v := range results {
+		switch {
+		case v.Migrated:
+			migrated++
+		case v.Message != "":
+			invalid++
+		}
+		if v.Namespace == "" {
+			// allocated over grpc, the client finds out on its next allocation
+			continue
+		}
+		if err := r.setDependentCondition(ctx, v); err != nil {
+			return err
+		}
+	}
+	if invalid > 0 {
+		r.recorder.Warning(cr, event.ReasonDependentsInvalid, "%d dependents are no longer valid in prefix %s", invalid, cr.Spec.Prefix)
+	}
+	if migrated > 0 {
+		r.recorder.Normal(cr, event.ReasonMigrated, "migrating %d allocations no longer covered by prefix %s", migrated, cr.Spec.Prefix)
+	}
+	return nil
+}
+
+// setDependentCondition reflects the validation of the dependent in the
+// condition of its IPAllocation or IPPrefix; a dependent that is valid again
+// is set to unknown, such that its reconciler allocates it again
+func (r *reconciler) setDependentCondition(ctx context.Context, v ipam.DependentValidation) error {
+	nsn := types.NamespacedName{Namespace: v.Namespace, Name: v.Name}
+	for _, o := range []conditionedObject{&ipamv1alpha1.IPAllocation{}, &ipamv1alpha1.IPPrefix{}} {
+		if err := r.Get(ctx, nsn, o); err != nil {
+			if resource.IgnoreNotFound(err) != nil {
+				return errors.Wrapf(err, "cannot get dependent %s", nsn.String())
+			}
+			continue
+		}
+		switch {
+		case v.Message != "":
+			o.SetConditions(ipamv1alpha1.Failed(v.Message))
+		case !shared.IsReady(o):
+			o.SetConditions(ipamv1alpha1.Unknown())
+		default:
+			return nil
+		}
+		return errors.Wrapf(resource.IgnoreNotFound(r.Status().Update(ctx, o)), "cannot update dependent %s", nsn.String())
+	}
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 	//ndddvrv1 "github.com/yndd/ndd-core/apis/dvr/v1"
 	"github.com/go-logr/logr"
 	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/internal/shared"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
@@ -46,10 +47,21 @@ func (e *EnqueueRequestForAllNetworkInstances) Create(evt event.CreateEvent, q w
 	e.add(evt.Object, q)
 }
 
-// Create enqueues a request for all ip allocation within the ipam
+// Update enqueues a request for all ip allocation within the ipam when the
+// readiness of the network instance changes or the network instance is deleted
 func (e *EnqueueRequestForAllNetworkInstances) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
-	e.add(evt.ObjectOld, q)
-	e.add(evt.ObjectNew, q)
+	oldNi, ok := evt.ObjectOld.(*ipamv1alpha1.NetworkInstance)
+	if !ok {
+		return
+	}
+	newNi, ok := evt.ObjectNew.(*ipamv1alpha1.NetworkInstance)
+	if !ok {
+		return
+	}
+	if shared.ReadyChanged(oldNi, newNi) ||
+		oldNi.GetDeletionTimestamp().IsZero() != newNi.GetDeletionTimestamp().IsZero() {
+		e.add(evt.ObjectNew, q)
+	}
 }
 
 // Create enqueues a request for all ip allocation within the ipam
@@ -71,17 +83,17 @@ func (e *EnqueueRequestForAllNetworkInstances) add(obj runtime.Object, queue add
 	e.l.Info("event", "kind", obj.GetObjectKind(), "name", ni.GetName())
 
 	d := &ipamv1alpha1.IPPrefixList{}
-	if err := e.client.List(e.ctx, d); err != nil {
+	if err := e.client.List(e.ctx, d,
+		client.InNamespace(ni.GetNamespace()),
+		client.MatchingFields{shared.NetworkInstanceIndex: ni.GetName()}); err != nil {
+		e.l.Error(err, "cannot list prefixes")
 		return
 	}
 
 	for _, p := range d.Items {
-		// only enqueue if the network-instance matches
-		if ni.GetName() == p.Spec.NetworkInstance {
-			e.l.Info("event requeue prefix", "name", p.GetName())
-			queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{
-				Namespace: p.GetNamespace(),
-				Name:      p.GetName()}})
-		}
+		e.l.Info("event requeue prefix", "name", p.GetName())
+		queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: p.GetNamespace(),
+			Name:      p.GetName()}})
 	}
 }
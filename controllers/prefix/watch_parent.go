@@ -0,0 +1,117 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/internal/shared"
+	"inet.af/netaddr"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// EnqueueRequestForParentPrefixes enqueues the prefixes an IPPrefix or
+// IPAllocation is nested in when its allocated prefix changes, such that the
+// prefixes update the allocations in their status
+type EnqueueRequestForParentPrefixes struct {
+	client client.Client
+	l      logr.Logger
+	ctx    context.Context
+}
+
+// Create does not enqueue, the prefix is not allocated yet
+func (e *EnqueueRequestForParentPrefixes) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+}
+
+// Update enqueues the parents of the old and the new allocated prefix when
+// the allocated prefix changed
+func (e *EnqueueRequestForParentPrefixes) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	_, oldPrefix := getAllocatedPrefix(evt.ObjectOld)
+	_, newPrefix := getAllocatedPrefix(evt.ObjectNew)
+	if oldPrefix == newPrefix {
+		return
+	}
+	e.add(evt.ObjectOld, q)
+	e.add(evt.ObjectNew, q)
+}
+
+// Delete enqueues the parents of the allocated prefix
+func (e *EnqueueRequestForParentPrefixes) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Generic does not enqueue
+func (e *EnqueueRequestForParentPrefixes) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+}
+
+func (e *EnqueueRequestForParentPrefixes) add(obj client.Object, queue adder) {
+	e.l = log.FromContext(e.ctx)
+
+	niName, prefix := getAllocatedPrefix(obj)
+	p, err := netaddr.ParseIPPrefix(prefix)
+	if err != nil || niName == "" {
+		return
+	}
+
+	d := &ipamv1alpha1.IPPrefixList{}
+	if err := e.client.List(e.ctx, d,
+		client.InNamespace(obj.GetNamespace()),
+		client.MatchingFields{shared.NetworkInstanceIndex: niName}); err != nil {
+		e.l.Error(err, "cannot list prefixes")
+		return
+	}
+
+	for _, parent := range d.Items {
+		if parent.GetName() == obj.GetName() {
+			continue
+		}
+		pp, err := netaddr.ParseIPPrefix(parent.Status.AllocatedPrefix)
+		if err != nil {
+			continue
+		}
+		pp = pp.Masked()
+		if !pp.Contains(p.IP()) || pp.Bits() > p.Bits() {
+			continue
+		}
+		e.l.Info("event requeue parent prefix", "name", parent.GetName(), "child", obj.GetName())
+		queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: parent.GetNamespace(),
+			Name:      parent.GetName()}})
+	}
+}
+
+// getAllocatedPrefix returns the network instance and the allocated prefix of
+// an IPPrefix or IPAllocation
+func getAllocatedPrefix(obj client.Object) (string, string) {
+	switch cr := obj.(type) {
+	case *ipamv1alpha1.IPPrefix:
+		return cr.Spec.NetworkInstance, cr.Status.AllocatedPrefix
+	case *ipamv1alpha1.IPAllocation:
+		if cr.Spec.Selector == nil {
+			return "", cr.Status.AllocatedPrefix
+		}
+		return cr.Spec.Selector.MatchLabels[ipamv1alpha1.NephioNetworkInstanceKey], cr.Status.AllocatedPrefix
+	}
+	return "", ""
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prefix
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/internal/shared"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// EnqueueRequestForPendingPrefixes enqueues the prefixes of a network instance
+// that are not ready when another prefix of the network instance is allocated,
+// changed or released, since the prefix might no longer overlap or conflict
+type EnqueueRequestForPendingPrefixes struct {
+	client client.Client
+	l      logr.Logger
+	ctx    context.Context
+}
+
+// Create does not enqueue, the prefix is not allocated yet
+func (e *EnqueueRequestForPendingPrefixes) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+}
+
+// Update enqueues the pending prefixes when the prefix got allocated, changed
+// or is being deleted
+func (e *EnqueueRequestForPendingPrefixes) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	oldCr, ok := evt.ObjectOld.(*ipamv1alpha1.IPPrefix)
+	if !ok {
+		return
+	}
+	newCr, ok := evt.ObjectNew.(*ipamv1alpha1.IPPrefix)
+	if !ok {
+		return
+	}
+	if shared.ReadyChanged(oldCr, newCr) ||
+		oldCr.Status.AllocatedPrefix != newCr.Status.AllocatedPrefix ||
+		oldCr.GetDeletionTimestamp().IsZero() != newCr.GetDeletionTimestamp().IsZero() {
+		e.add(newCr, q)
+	}
+}
+
+// Delete enqueues the pending prefixes
+func (e *EnqueueRequestForPendingPrefixes) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	cr, ok := evt.Object.(*ipamv1alpha1.IPPrefix)
+	if !ok {
+		return
+	}
+	e.add(cr, q)
+}
+
+// Generic does not enqueue
+func (e *EnqueueRequestForPendingPrefixes) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+}
+
+func (e *EnqueueRequestForPendingPrefixes) add(cr *ipamv1alpha1.IPPrefix, queue adder) {
+	e.l = log.FromContext(e.ctx)
+
+	d := &ipamv1alpha1.IPPrefixList{}
+	if err := e.client.List(e.ctx, d,
+		client.InNamespace(cr.GetNamespace()),
+		client.MatchingFields{shared.NetworkInstanceIndex: cr.Spec.NetworkInstance}); err != nil {
+		e.l.Error(err, "cannot list prefixes")
+		return
+	}
+
+	for _, p := range d.Items {
+		if p.GetName() == cr.GetName() || shared.IsReady(&p) {
+			continue
+		}
+		e.l.Info("event requeue pending prefix", "name", p.GetName(), "trigger", cr.GetName())
+		queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: p.GetNamespace(),
+			Name:      p.GetName()}})
+	}
+}
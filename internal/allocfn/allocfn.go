@@ -0,0 +1,324 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package allocfn
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/GoogleContainerTools/kpt-functions-sdk/go/fn"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/internal/utils/iputil"
+	"github.com/nokia/k8s-ipam/pkg/alloc/alloc"
+	"github.com/nokia/k8s-ipam/pkg/alloc/allocpb"
+	"inet.af/netaddr"
+)
+
+const (
+	defaultNamespace = "default"
+	defaultTimeout   = 10 * time.Second
+
+	// function config keys
+	configAddressKey    = "address"
+	configInsecureKey   = "insecure"
+	configSkipVerifyKey = "skipVerify"
+	configTimeoutKey    = "timeout"
+
+	// addressEnv supplies the address when the function config has none
+	addressEnv = "IPAM_ADDRESS"
+)
+
+// ClientFn creates the allocation client based on the config
+type ClientFn func(c *alloc.Config) (allocpb.AllocationClient, error)
+
+type Options struct {
+	// ClientFn allows to overwrite the client creation, defaults to alloc.CreateClient
+	ClientFn ClientFn
+}
+
+// New returns a kpt function processor that allocates the IP(s) of
+// all IPAllocation resources in the resource list using the allocation gRPC service
+func New(o *Options) fn.ResourceListProcessor {
+	f := &function{
+		clientFn: alloc.CreateClient,
+	}
+	if o != nil && o.ClientFn != nil {
+		f.clientFn = o.ClientFn
+	}
+	return fn.ResourceListProcessorFunc(f.Process)
+}
+
+type function struct {
+	clientFn ClientFn
+}
+
+type config struct {
+	alloc   *alloc.Config
+	timeout time.Duration
+}
+
+func (f *function) Process(rl *fn.ResourceList) (bool, error) {
+	cfg, err := getConfig(rl.FunctionConfig, os.Getenv(addressEnv))
+	if err != nil {
+		rl.Results.ErrorE(err)
+		return false, nil
+	}
+
+	allocs := rl.Items.Where(fn.IsGVK(ipamv1alpha1.GroupVersion.Group, ipamv1alpha1.GroupVersion.Version, ipamv1alpha1.IPAllocationKind))
+	if len(allocs) == 0 {
+		return true, nil
+	}
+
+	c, err := f.clientFn(cfg.alloc)
+	if err != nil {
+		rl.Results.ErrorE(fmt.Errorf("cannot create allocation client: %s", err.Error()))
+		return false, nil
+	}
+
+	success := true
+	for _, o := range allocs {
+		prefix, err := allocate(c, o, cfg.timeout)
+		if err != nil {
+			rl.Results = append(rl.Results, &fn.Result{
+				Message:     err.Error(),
+				Severity:    fn.Error,
+				ResourceRef: getResourceRef(o),
+			})
+			success = false
+			continue
+		}
+		rl.Results = append(rl.Results, &fn.Result{
+			Message:     fmt.Sprintf("allocated prefix %s", prefix),
+			Severity:    fn.Info,
+			ResourceRef: getResourceRef(o),
+		})
+	}
+	return success, nil
+}
+
+// allocate performs the allocation of an individual IPAllocation and updates
+// its status with the allocated prefix, as the reconciler of the IPAllocation does
+func allocate(c allocpb.AllocationClient, o *fn.KubeObject, timeout time.Duration) (string, error) {
+	ipAlloc := &ipamv1alpha1.IPAllocation{}
+	if err := o.As(ipAlloc); err != nil {
+		return "", fmt.Errorf("cannot convert ip allocation to a typed object: %s", err.Error())
+	}
+	req, err := BuildGRPCAllocFromIPAllocation(ipAlloc)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	resp, err := c.Allocation(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("cannot allocate ip for %s: %s", o.GetName(), err.Error())
+	}
+	if resp.GetAllocatedPrefix() == "" {
+		return "", fmt.Errorf("no prefix allocated for %s", o.GetName())
+	}
+
+	if err := o.SetNestedString(resp.GetAllocatedPrefix(), "status", "prefix"); err != nil {
+		return "", err
+	}
+	if resp.GetParentPrefix() != "" {
+		if err := o.SetNestedString(resp.GetParentPrefix(), "status", "parentPrefix"); err != nil {
+			return "", err
+		}
+	}
+	if p, err := netaddr.ParseIPPrefix(resp.GetAllocatedPrefix()); err == nil {
+		if err := o.SetNestedString(p.IP().String(), "status", "address"); err != nil {
+			return "", err
+		}
+		if err := o.SetNestedInt(int(p.Bits()), "status", "prefixLength"); err != nil {
+			return "", err
+		}
+		if err := o.SetNestedString(string(iputil.GetAddressFamily(p)), "status", "addressFamily"); err != nil {
+			return "", err
+		}
+	}
+	if err := o.SetNestedString(ipAlloc.Spec.PrefixKind, "status", "kind"); err != nil {
+		return "", err
+	}
+	if err := o.SetNestedString(req.GetSpec().GetSelector()[ipamv1alpha1.NephioNetworkInstanceKey], "status", "networkInstance"); err != nil {
+		return "", err
+	}
+	if network := req.GetSpec().GetSelector()[ipamv1alpha1.NephioNetworkNameKey]; network != "" {
+		if err := o.SetNestedString(network, "status", "networkName"); err != nil {
+			return "", err
+		}
+	}
+	// the expiry is only set when the allocation has a lease
+	if resp.GetExpiry() != 0 {
+		expiry := time.Unix(resp.GetExpiry(), 0).UTC().Format(time.RFC3339)
+		if err := o.SetNestedString(expiry, "status", "leaseExpiry"); err != nil {
+			return "", err
+		}
+	}
+	// gateways are only relevant for prefixkind = network
+	if resp.GetGateway() != "" {
+		if err := o.SetNestedString(resp.GetGateway(), "status", "gateway"); err != nil {
+			return "", err
+		}
+	}
+	if len(resp.GetGateways()) > 0 {
+		gateways := make([]ipamv1alpha1.Gateway, 0, len(resp.GetGateways()))
+		for _, gw := range resp.GetGateways() {
+			gateways = append(gateways, ipamv1alpha1.Gateway{
+				Address: gw.GetAddress(),
+				Role:    ipamv1alpha1.GatewayRole(gw.GetRole()),
+			})
+		}
+		if err := o.SetNestedField(gateways, "status", "gateways"); err != nil {
+			return "", err
+		}
+	}
+	// endpoints are only relevant for prefixkind = link
+	if len(resp.GetEndpoints()) > 0 {
+		eps := make([]ipamv1alpha1.AllocatedLinkEndpoint, 0, len(resp.GetEndpoints()))
+		for _, ep := range resp.GetEndpoints() {
+			eps = append(eps, ipamv1alpha1.AllocatedLinkEndpoint{
+				LinkEndpoint: ipamv1alpha1.LinkEndpoint{
+					Node:      ep.GetNode(),
+					Interface: ep.GetInterface(),
+				},
+				Address: ep.GetAddress(),
+			})
+		}
+		if err := o.SetNestedField(eps, "status", "endpoints"); err != nil {
+			return "", err
+		}
+	}
+	// vpn identifiers are only set when the network instance has them allocated
+	if resp.GetRouteDistinguisher() != "" {
+		if err := o.SetNestedString(resp.GetRouteDistinguisher(), "status", "routeDistinguisher"); err != nil {
+			return "", err
+		}
+	}
+	if len(resp.GetImportRouteTargets()) > 0 {
+		if err := o.SetNestedStringSlice(resp.GetImportRouteTargets(), "status", "importRouteTargets"); err != nil {
+			return "", err
+		}
+	}
+	if len(resp.GetExportRouteTargets()) > 0 {
+		if err := o.SetNestedStringSlice(resp.GetExportRouteTargets(), "status", "exportRouteTargets"); err != nil {
+			return "", err
+		}
+	}
+	return resp.GetAllocatedPrefix(), nil
+}
+
+// BuildGRPCAllocFromIPAllocation returns the grpc allocation request for an IPAllocation
+func BuildGRPCAllocFromIPAllocation(cr *ipamv1alpha1.IPAllocation) (*allocpb.Request, error) {
+	if cr.Spec.Selector == nil {
+		return nil, fmt.Errorf("ip allocation %s has no selector", cr.GetName())
+	}
+	if _, ok := cr.Spec.Selector.MatchLabels[ipamv1alpha1.NephioNetworkInstanceKey]; !ok {
+		return nil, fmt.Errorf("ip allocation %s has no %s in the selector", cr.GetName(), ipamv1alpha1.NephioNetworkInstanceKey)
+	}
+	namespace := cr.GetNamespace()
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	var endpoints []*allocpb.Endpoint
+	for _, ep := range cr.Spec.Endpoints {
+		endpoints = append(endpoints, &allocpb.Endpoint{
+			Node:      ep.Node,
+			Interface: ep.Interface,
+		})
+	}
+	var leaseDuration uint32
+	if cr.Spec.LeaseDuration != nil {
+		leaseDuration = uint32(cr.Spec.LeaseDuration.Seconds())
+	}
+	return &allocpb.Request{
+		Namespace: namespace,
+		Name:      cr.GetName(),
+		Kind:      "ipam",
+		Labels:    cr.GetLabels(),
+		Spec: &allocpb.Spec{
+			Prefixkind:    cr.Spec.PrefixKind,
+			Prefix:        cr.Spec.Prefix,
+			PrefixLength:  uint32(cr.Spec.PrefixLength),
+			AddressFamily: cr.Spec.AddressFamily,
+			Selector:      cr.Spec.Selector.MatchLabels,
+			Endpoints:     endpoints,
+			LeaseDuration: leaseDuration,
+		},
+	}, nil
+}
+
+// getConfig returns the function config from a ConfigMap, using defaults for
+// the parameters that are not supplied; the address of the IPAM has no default
+// as the function runs in a container, it falls back to the address of the
+// environment
+func getConfig(o *fn.KubeObject, envAddress string) (*config, error) {
+	cfg := &config{
+		alloc: &alloc.Config{
+			Address:  envAddress,
+			Insecure: true,
+		},
+		timeout: defaultTimeout,
+	}
+	data := map[string]string{}
+	if o != nil && !o.IsEmpty() {
+		var err error
+		if data, _, err = o.NestedStringMap("data"); err != nil {
+			return nil, fmt.Errorf("cannot read function config data: %s", err.Error())
+		}
+	}
+	if v, ok := data[configAddressKey]; ok && v != "" {
+		cfg.alloc.Address = v
+	}
+	if cfg.alloc.Address == "" {
+		return nil, fmt.Errorf("no IPAM address, set %s in the function config or the %s environment variable", configAddressKey, addressEnv)
+	}
+	if v, ok := data[configInsecureKey]; ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid function config %s: %s", configInsecureKey, err.Error())
+		}
+		cfg.alloc.Insecure = b
+	}
+	if v, ok := data[configSkipVerifyKey]; ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid function config %s: %s", configSkipVerifyKey, err.Error())
+		}
+		cfg.alloc.SkipVerify = b
+	}
+	if v, ok := data[configTimeoutKey]; ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid function config %s: %s", configTimeoutKey, err.Error())
+		}
+		cfg.timeout = d
+	}
+	return cfg, nil
+}
+
+func getResourceRef(o *fn.KubeObject) *fn.ResourceRef {
+	return &fn.ResourceRef{
+		APIVersion: o.GetAPIVersion(),
+		Kind:       o.GetKind(),
+		Name:       o.GetName(),
+		Namespace:  o.GetNamespace(),
+	}
+}
@@ -18,34 +18,236 @@ package allochandler
 
 import (
 	"context"
+	"fmt"
+	"sort"
 
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
 	"github.com/nokia/k8s-ipam/internal/ipam"
+	"github.com/nokia/k8s-ipam/internal/resourcepool"
+	"github.com/nokia/k8s-ipam/pkg/alloc/alloc"
 	"github.com/nokia/k8s-ipam/pkg/alloc/allocpb"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+const kindIpam = "ipam"
+
+// isIpamKind returns true for ip prefix requests, all other kinds are
+// allocated from resource pools
+func isIpamKind(kind string) bool {
+	return kind == "" || kind == kindIpam
+}
+
 func (s *subServer) Allocation(ctx context.Context, alloc *allocpb.Request) (*allocpb.Response, error) {
 	s.l = log.FromContext(ctx)
 	s.l.Info("allocate", "alloc", alloc)
 
-	prefix, err := s.ipam.AllocateIPPrefix(ctx, ipam.BuildAllocationFromGRPCAlloc(alloc))
+	if !isIpamKind(alloc.GetKind()) {
+		if s.pools == nil {
+			return nil, status.Error(codes.Unimplemented, fmt.Sprintf("unsupported kind %s", alloc.GetKind()))
+		}
+		allocated, err := s.pools.Allocate(ctx, resourcepool.BuildClaimFromGRPCAlloc(alloc))
+		if err != nil {
+			return nil, getStatusError(err)
+		}
+		return &allocpb.Response{Id: allocated.ID}, nil
+	}
+
+	ipamAlloc := ipam.BuildAllocationFromGRPCAlloc(alloc)
+	setClientLabel(ctx, ipamAlloc)
+	prefix, err := s.ipam.AllocateIPPrefix(ctx, ipamAlloc)
 	if err != nil {
-		return nil, err
+		return nil, getStatusError(err)
 	}
-	return &allocpb.Response{
-		AllocatedPrefix: prefix.AllocatedPrefix,
-		Gateway:         prefix.Gateway,
-	}, nil
+	return s.buildIpamResponse(ipamAlloc, prefix), nil
 }
 
 func (s *subServer) DeAllocation(ctx context.Context, alloc *allocpb.Request) (*allocpb.Response, error) {
 	s.l = log.FromContext(ctx)
 	s.l.Info("deallocate", "alloc", alloc)
 
+	if !isIpamKind(alloc.GetKind()) {
+		if s.pools == nil {
+			return nil, status.Error(codes.Unimplemented, fmt.Sprintf("unsupported kind %s", alloc.GetKind()))
+		}
+		if err := s.pools.DeAllocate(ctx, resourcepool.BuildClaimFromGRPCAlloc(alloc)); err != nil {
+			return nil, getStatusError(err)
+		}
+		return &allocpb.Response{}, nil
+	}
+
 	//allocs := []*ipamv1alpha1.IPAllocation{}
 	//allocs = append(allocs, buildAlloc(alloc))
 	if err := s.ipam.DeAllocateIPPrefix(ctx, ipam.BuildAllocationFromGRPCAlloc(alloc)); err != nil {
-		return nil, err
+		return nil, getStatusError(err)
 	}
 	return &allocpb.Response{}, nil
 }
+
+func (s *subServer) GetAllocation(ctx context.Context, alloc *allocpb.Request) (*allocpb.Response, error) {
+	s.l = log.FromContext(ctx)
+	s.l.Info("get allocation", "alloc", alloc)
+
+	if !isIpamKind(alloc.GetKind()) {
+		if s.pools == nil {
+			return nil, status.Error(codes.Unimplemented, fmt.Sprintf("unsupported kind %s", alloc.GetKind()))
+		}
+		allocated, err := s.pools.Get(ctx, resourcepool.BuildClaimFromGRPCAlloc(alloc))
+		if err != nil {
+			return nil, getStatusError(err)
+		}
+		return &allocpb.Response{Id: allocated.ID}, nil
+	}
+
+	ipamAlloc := ipam.BuildAllocationFromGRPCAlloc(alloc)
+	prefix, err := s.ipam.GetAllocatedPrefix(ctx, ipamAlloc)
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+	return s.buildIpamResponse(ipamAlloc, prefix), nil
+}
+
+// getStatusError returns the grpc status of the ipam or resource pool error,
+// errors that are not typed are returned as is
+func getStatusError(err error) error {
+	switch {
+	case errors.Is(err, ipam.ErrQuotaExceeded), errors.Is(err, ipam.ErrPoolExhausted),
+		errors.Is(err, resourcepool.ErrPoolExhausted):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, ipam.ErrConflict), errors.Is(err, resourcepool.ErrConflict):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, ipam.ErrOverlap), errors.Is(err, ipam.ErrInvalidNesting):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, ipam.ErrNetworkInstanceNotReady):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, ipam.ErrNotFound), errors.Is(err, resourcepool.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ipam.ErrValidationFailed):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return err
+}
+
+// setClientLabel labels the allocation with the client id the client sends in
+// the metadata, which counts the allocation against the quotas per client
+func setClientLabel(ctx context.Context, ipamAlloc *ipam.Allocation) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return
+	}
+	if ids := md.Get(alloc.ClientIDKey); len(ids) > 0 && ids[0] != "" {
+		if ipamAlloc.Labels == nil {
+			ipamAlloc.Labels = map[string]string{}
+		}
+		ipamAlloc.Labels[ipamv1alpha1.NephioClientKey] = ids[0]
+	}
+}
+
+// buildIpamResponse returns the allocated prefix together with the vpn
+// identifiers of the network instance it was allocated in
+func (s *subServer) buildIpamResponse(alloc *ipam.Allocation, prefix *ipam.AllocatedPrefix) *allocpb.Response {
+	resp := &allocpb.Response{
+		AllocatedPrefix: prefix.AllocatedPrefix,
+		Gateway:         prefix.Gateway,
+		ParentPrefix:    prefix.ParentPrefix,
+	}
+	if !prefix.LeaseExpiry.IsZero() {
+		resp.Expiry = prefix.LeaseExpiry.Unix()
+	}
+	for _, gw := range prefix.Gateways {
+		resp.Gateways = append(resp.Gateways, &allocpb.Gateway{
+			Address: gw.Address,
+			Role:    string(gw.Role),
+		})
+	}
+	for _, ep := range prefix.Endpoints {
+		resp.Endpoints = append(resp.Endpoints, &allocpb.Endpoint{
+			Node:      ep.Node,
+			Interface: ep.Interface,
+			Address:   ep.Address,
+		})
+	}
+	if s.vrf != nil {
+		if ids, ok := s.vrf.Get(alloc.GetNetworkInstance()); ok {
+			resp.RouteDistinguisher = ids.RouteDistinguisher
+			resp.ImportRouteTargets = ids.ImportRouteTargets
+			resp.ExportRouteTargets = ids.ExportRouteTargets
+		}
+	}
+	return resp
+}
+
+func (s *subServer) ListRoutes(ctx context.Context, req *allocpb.ListRequest) (*allocpb.ListResponse, error) {
+	s.l = log.FromContext(ctx)
+	s.l.Info("list routes", "req", req)
+
+	routes, err := s.ipam.GetRoutes(req.GetNetworkInstance(), labels.SelectorFromSet(req.GetSelector()))
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+	resp := &allocpb.ListResponse{
+		Routes: make([]*allocpb.Route, 0, len(routes)),
+	}
+	for _, route := range routes {
+		resp.Routes = append(resp.Routes, &allocpb.Route{
+			Prefix: route.String(),
+			Labels: *route.GetLabels(),
+		})
+	}
+	sort.Slice(resp.Routes, func(i, j int) bool {
+		return resp.Routes[i].Prefix < resp.Routes[j].Prefix
+	})
+	return resp, nil
+}
+
+func (s *subServer) Export(ctx context.Context, req *allocpb.ExportRequest) (*allocpb.ExportResponse, error) {
+	s.l = log.FromContext(ctx)
+	s.l.Info("export", "req", req)
+
+	data, err := s.ipam.Export(req.GetNetworkInstance(), ipam.ExportFormat(req.GetFormat()))
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+	return &allocpb.ExportResponse{Data: data}, nil
+}
+
+func (s *subServer) Defrag(ctx context.Context, req *allocpb.DefragRequest) (*allocpb.DefragResponse, error) {
+	s.l = log.FromContext(ctx)
+	s.l.Info("defrag", "req", req)
+
+	plan, err := s.ipam.Defrag(ctx, req.GetNetworkInstance(), req.GetPrefix(), req.GetExecute())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+	resp := &allocpb.DefragResponse{
+		Executed: plan.Executed,
+		Prefixes: make([]*allocpb.DefragPrefix, 0, len(plan.Prefixes)),
+	}
+	for _, dp := range plan.Prefixes {
+		p := &allocpb.DefragPrefix{
+			Prefix:                         dp.Prefix,
+			Kind:                           dp.PrefixKind,
+			LargestFreePrefixLength:        uint32(dp.LargestFreePrefixLength),
+			FreeBlocks:                     int32(dp.FreeBlocks),
+			PlannedLargestFreePrefixLength: uint32(dp.PlannedLargestFreePrefixLength),
+			PlannedFreeBlocks:              int32(dp.PlannedFreeBlocks),
+			Pinned:                         dp.Pinned,
+		}
+		for _, move := range dp.Moves {
+			p.Moves = append(p.Moves, &allocpb.DefragMove{
+				Namespace: move.Namespace,
+				Name:      move.Name,
+				Kind:      move.PrefixKind,
+				OldPrefix: move.OldPrefix,
+				NewPrefix: move.NewPrefix,
+				Signalled: move.Signalled,
+			})
+		}
+		resp.Prefixes = append(resp.Prefixes, p)
+	}
+	return resp, nil
+}
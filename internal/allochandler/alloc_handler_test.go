@@ -0,0 +1,62 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package allochandler
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/nokia/k8s-ipam/internal/ipam"
+	"github.com/nokia/k8s-ipam/internal/resourcepool"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetStatusError(t *testing.T) {
+	cases := map[string]struct {
+		err      error
+		wantCode codes.Code
+	}{
+		"QuotaExceeded":           {err: ipam.ErrQuotaExceeded, wantCode: codes.ResourceExhausted},
+		"PoolExhausted":           {err: ipam.ErrPoolExhausted, wantCode: codes.ResourceExhausted},
+		"Conflict":                {err: ipam.ErrConflict, wantCode: codes.AlreadyExists},
+		"Overlap":                 {err: ipam.ErrOverlap, wantCode: codes.FailedPrecondition},
+		"InvalidNesting":          {err: ipam.ErrInvalidNesting, wantCode: codes.FailedPrecondition},
+		"NetworkInstanceNotReady": {err: ipam.ErrNetworkInstanceNotReady, wantCode: codes.Unavailable},
+		"NotFound":                {err: ipam.ErrNotFound, wantCode: codes.NotFound},
+		"ValidationFailed":        {err: ipam.ErrValidationFailed, wantCode: codes.InvalidArgument},
+		"ResourcePoolExhausted":   {err: resourcepool.ErrPoolExhausted, wantCode: codes.ResourceExhausted},
+		"ResourcePoolConflict":    {err: resourcepool.ErrConflict, wantCode: codes.AlreadyExists},
+		"ResourcePoolNotFound":    {err: resourcepool.ErrNotFound, wantCode: codes.NotFound},
+		"Unknown":                 {err: errors.New("cannot update status"), wantCode: codes.Unknown},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// the ipam wraps the kind of error with the details
+			err := fmt.Errorf("%w: details", tc.err)
+			got := getStatusError(err)
+			if code := status.Code(got); code != tc.wantCode {
+				t.Errorf("got code %s, want %s", code, tc.wantCode)
+			}
+			if status.Convert(got).Message() != err.Error() {
+				t.Errorf("got message %q, want %q", status.Convert(got).Message(), err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package allochandler
+
+import (
+	"context"
+	"fmt"
+
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/internal/ipam"
+	"github.com/nokia/k8s-ipam/internal/utils/iputil"
+	"github.com/nokia/k8s-ipam/pkg/alloc/allocpb"
+	allocv1alpha2pb "github.com/nokia/k8s-ipam/pkg/alloc/v1alpha2/allocpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"inet.af/netaddr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var prefixKinds = map[allocv1alpha2pb.PrefixKind]ipamv1alpha1.PrefixKind{
+	allocv1alpha2pb.PrefixKind_PREFIX_KIND_NETWORK:   ipamv1alpha1.PrefixKindNetwork,
+	allocv1alpha2pb.PrefixKind_PREFIX_KIND_LOOPBACK:  ipamv1alpha1.PrefixKindLoopback,
+	allocv1alpha2pb.PrefixKind_PREFIX_KIND_POOL:      ipamv1alpha1.PrefixKindPool,
+	allocv1alpha2pb.PrefixKind_PREFIX_KIND_AGGREGATE: ipamv1alpha1.PrefixKindAggregate,
+	allocv1alpha2pb.PrefixKind_PREFIX_KIND_LINK:      ipamv1alpha1.PrefixKindLink,
+}
+
+var addressFamilies = map[allocv1alpha2pb.AddressFamily]ipamv1alpha1.AddressFamily{
+	allocv1alpha2pb.AddressFamily_ADDRESS_FAMILY_IPV4: ipamv1alpha1.AddressFamilyIpv4,
+	allocv1alpha2pb.AddressFamily_ADDRESS_FAMILY_IPV6: ipamv1alpha1.AddressFamilyIpv6,
+}
+
+func (s *subServer) AllocationV1alpha2(ctx context.Context, req *allocv1alpha2pb.Request) (*allocv1alpha2pb.Response, error) {
+	s.l = log.FromContext(ctx)
+	s.l.Info("allocate v1alpha2", "alloc", req)
+
+	alloc, err := buildV1alpha1Request(req, true)
+	if err != nil {
+		return nil, err
+	}
+	if !isIpamKind(alloc.GetKind()) {
+		resp, err := s.Allocation(ctx, alloc)
+		if err != nil {
+			return nil, err
+		}
+		return &allocv1alpha2pb.Response{Id: resp.GetId()}, nil
+	}
+
+	ipamAlloc := ipam.BuildAllocationFromGRPCAlloc(alloc)
+	setClientLabel(ctx, ipamAlloc)
+	prefix, err := s.ipam.AllocateIPPrefix(ctx, ipamAlloc)
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+	return s.buildIpamResponseV1alpha2(req, ipamAlloc, prefix)
+}
+
+func (s *subServer) DeAllocationV1alpha2(ctx context.Context, req *allocv1alpha2pb.Request) (*allocv1alpha2pb.Response, error) {
+	alloc, err := buildV1alpha1Request(req, false)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.DeAllocation(ctx, alloc); err != nil {
+		return nil, err
+	}
+	return &allocv1alpha2pb.Response{}, nil
+}
+
+func (s *subServer) GetAllocationV1alpha2(ctx context.Context, req *allocv1alpha2pb.Request) (*allocv1alpha2pb.Response, error) {
+	s.l = log.FromContext(ctx)
+	s.l.Info("get allocation v1alpha2", "alloc", req)
+
+	alloc, err := buildV1alpha1Request(req, false)
+	if err != nil {
+		return nil, err
+	}
+	if !isIpamKind(alloc.GetKind()) {
+		resp, err := s.GetAllocation(ctx, alloc)
+		if err != nil {
+			return nil, err
+		}
+		return &allocv1alpha2pb.Response{Id: resp.GetId()}, nil
+	}
+
+	ipamAlloc := ipam.BuildAllocationFromGRPCAlloc(alloc)
+	prefix, err := s.ipam.GetAllocatedPrefix(ctx, ipamAlloc)
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+	return s.buildIpamResponseV1alpha2(req, ipamAlloc, prefix)
+}
+
+// buildV1alpha1Request returns the v1alpha1 request of a v1alpha2 request,
+// such that both versions are allocated the same way; ip prefix requests need
+// a known prefix kind and, when allocating without a prefix, a known address
+// family
+func buildV1alpha1Request(req *allocv1alpha2pb.Request, allocate bool) (*allocpb.Request, error) {
+	prefixKind, addressFamily, err := getPrefixKindAndAddressFamily(req, allocate)
+	if err != nil {
+		return nil, err
+	}
+	alloc := &allocpb.Request{
+		Namespace: req.GetNamespace(),
+		Name:      req.GetName(),
+		Kind:      req.GetKind(),
+		Labels:    req.GetLabels(),
+		Spec: &allocpb.Spec{
+			Prefixkind:    string(prefixKind),
+			Prefix:        req.GetSpec().GetPrefix(),
+			PrefixLength:  req.GetSpec().GetPrefixLength(),
+			Network:       req.GetSpec().GetNetwork(),
+			AddressFamily: string(addressFamily),
+			Selector:      req.GetSpec().GetSelector(),
+			Id:            req.GetSpec().GetId(),
+			LeaseDuration: req.GetSpec().GetLeaseDuration(),
+		},
+	}
+	for _, ep := range req.GetSpec().GetEndpoints() {
+		alloc.Spec.Endpoints = append(alloc.Spec.Endpoints, &allocpb.Endpoint{
+			Node:      ep.GetNode(),
+			Interface: ep.GetInterface(),
+			Address:   ep.GetAddress(),
+		})
+	}
+	return alloc, nil
+}
+
+// getPrefixKindAndAddressFamily maps the enums of an ip prefix request; a
+// custom prefix kind is passed by name, the ipam rejects it when it is not
+// registered. The address family is only required to allocate without a
+// prefix, otherwise it is derived from the prefix or not needed
+func getPrefixKindAndAddressFamily(req *allocv1alpha2pb.Request, allocate bool) (ipamv1alpha1.PrefixKind, ipamv1alpha1.AddressFamily, error) {
+	if !isIpamKind(req.GetKind()) {
+		return "", "", nil
+	}
+	prefixKind, ok := prefixKinds[req.GetSpec().GetPrefixKind()]
+	if customPrefixKind := req.GetSpec().GetCustomPrefixKind(); req.GetSpec().GetPrefixKind() == allocv1alpha2pb.PrefixKind_PREFIX_KIND_CUSTOM {
+		prefixKind, ok = ipamv1alpha1.PrefixKind(customPrefixKind), customPrefixKind != ""
+	}
+	if !ok {
+		return "", "", status.Error(codes.InvalidArgument,
+			fmt.Sprintf("unspecified or unknown prefix kind %s", req.GetSpec().GetPrefixKind()))
+	}
+	af := req.GetSpec().GetAddressFamily()
+	if af == allocv1alpha2pb.AddressFamily_ADDRESS_FAMILY_UNSPECIFIED && (req.GetSpec().GetPrefix() != "" || !allocate) {
+		return prefixKind, "", nil
+	}
+	addressFamily, ok := addressFamilies[af]
+	if !ok {
+		return "", "", status.Error(codes.InvalidArgument,
+			fmt.Sprintf("unspecified or unknown address family %s", af))
+	}
+	return prefixKind, addressFamily, nil
+}
+
+// buildIpamResponseV1alpha2 returns the allocated prefix together with the
+// network, labels and gateways of the allocation and the vpn identifiers of
+// the network instance it was allocated in
+func (s *subServer) buildIpamResponseV1alpha2(req *allocv1alpha2pb.Request, alloc *ipam.Allocation, prefix *ipam.AllocatedPrefix) (*allocv1alpha2pb.Response, error) {
+	resp := &allocv1alpha2pb.Response{
+		AllocatedPrefix:  prefix.AllocatedPrefix,
+		ParentPrefix:     prefix.ParentPrefix,
+		NetworkInstance:  alloc.GetNetworkInstance(),
+		Network:          alloc.GetNetwork(),
+		PrefixKind:       req.GetSpec().GetPrefixKind(),
+		CustomPrefixKind: req.GetSpec().GetCustomPrefixKind(),
+	}
+	if !prefix.LeaseExpiry.IsZero() {
+		resp.Expiry = prefix.LeaseExpiry.Unix()
+	}
+	if p, err := netaddr.ParseIPPrefix(prefix.AllocatedPrefix); err == nil {
+		switch iputil.GetAddressFamily(p) {
+		case ipamv1alpha1.AddressFamilyIpv4:
+			resp.AddressFamily = allocv1alpha2pb.AddressFamily_ADDRESS_FAMILY_IPV4
+		case ipamv1alpha1.AddressFamilyIpv6:
+			resp.AddressFamily = allocv1alpha2pb.AddressFamily_ADDRESS_FAMILY_IPV6
+		}
+	}
+	allocSelector, err := alloc.GetAllocSelector()
+	if err != nil {
+		return nil, err
+	}
+	routes, err := s.ipam.GetRoutes(alloc.GetNetworkInstance(), allocSelector)
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+	// an address of a network is stored as a host route, such that we fall back
+	// to the first route of the allocation
+	for i, route := range routes {
+		if i == 0 || route.String() == prefix.AllocatedPrefix {
+			resp.Labels = *route.GetLabels()
+		}
+	}
+
+	for _, gw := range prefix.Gateways {
+		resp.Gateways = append(resp.Gateways, &allocv1alpha2pb.Gateway{
+			Address: gw.Address,
+			Role:    string(gw.Role),
+		})
+	}
+	for _, ep := range prefix.Endpoints {
+		resp.Endpoints = append(resp.Endpoints, &allocv1alpha2pb.Endpoint{
+			Node:      ep.Node,
+			Interface: ep.Interface,
+			Address:   ep.Address,
+		})
+	}
+	if s.vrf != nil {
+		if ids, ok := s.vrf.Get(alloc.GetNetworkInstance()); ok {
+			resp.RouteDistinguisher = ids.RouteDistinguisher
+			resp.ImportRouteTargets = ids.ImportRouteTargets
+			resp.ExportRouteTargets = ids.ExportRouteTargets
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package allochandler
+
+import (
+	"testing"
+
+	allocv1alpha2pb "github.com/nokia/k8s-ipam/pkg/alloc/v1alpha2/allocpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBuildV1alpha1Request(t *testing.T) {
+	cases := map[string]struct {
+		kind              string
+		spec              *allocv1alpha2pb.Spec
+		get               bool // a deallocation or get, not an allocation
+		wantCode          codes.Code
+		wantPrefixKind    string
+		wantAddressFamily string
+	}{
+		"Dynamic": {
+			spec: &allocv1alpha2pb.Spec{
+				PrefixKind:    allocv1alpha2pb.PrefixKind_PREFIX_KIND_POOL,
+				AddressFamily: allocv1alpha2pb.AddressFamily_ADDRESS_FAMILY_IPV6,
+				LeaseDuration: 3600,
+			},
+			wantCode:          codes.OK,
+			wantPrefixKind:    "pool",
+			wantAddressFamily: "ipv6",
+		},
+		"StaticWithoutAddressFamily": {
+			spec: &allocv1alpha2pb.Spec{
+				PrefixKind: allocv1alpha2pb.PrefixKind_PREFIX_KIND_LOOPBACK,
+				Prefix:     "10.0.0.1/32",
+			},
+			wantCode:          codes.OK,
+			wantPrefixKind:    "loopback",
+			wantAddressFamily: "",
+		},
+		"UnspecifiedPrefixKind": {
+			spec: &allocv1alpha2pb.Spec{
+				AddressFamily: allocv1alpha2pb.AddressFamily_ADDRESS_FAMILY_IPV4,
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		"UnknownPrefixKind": {
+			spec: &allocv1alpha2pb.Spec{
+				PrefixKind:    allocv1alpha2pb.PrefixKind(42),
+				AddressFamily: allocv1alpha2pb.AddressFamily_ADDRESS_FAMILY_IPV4,
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		"CustomPrefixKind": {
+			spec: &allocv1alpha2pb.Spec{
+				PrefixKind:       allocv1alpha2pb.PrefixKind_PREFIX_KIND_CUSTOM,
+				CustomPrefixKind: "vip",
+				AddressFamily:    allocv1alpha2pb.AddressFamily_ADDRESS_FAMILY_IPV4,
+			},
+			wantCode:          codes.OK,
+			wantPrefixKind:    "vip",
+			wantAddressFamily: "ipv4",
+		},
+		"CustomPrefixKindWithoutName": {
+			spec: &allocv1alpha2pb.Spec{
+				PrefixKind:    allocv1alpha2pb.PrefixKind_PREFIX_KIND_CUSTOM,
+				AddressFamily: allocv1alpha2pb.AddressFamily_ADDRESS_FAMILY_IPV4,
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		"UnspecifiedAddressFamily": {
+			spec: &allocv1alpha2pb.Spec{
+				PrefixKind: allocv1alpha2pb.PrefixKind_PREFIX_KIND_NETWORK,
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		"UnspecifiedAddressFamilyWithoutAllocate": {
+			spec: &allocv1alpha2pb.Spec{
+				PrefixKind: allocv1alpha2pb.PrefixKind_PREFIX_KIND_NETWORK,
+			},
+			get:               true,
+			wantCode:          codes.OK,
+			wantPrefixKind:    "network",
+			wantAddressFamily: "",
+		},
+		"UnknownAddressFamily": {
+			spec: &allocv1alpha2pb.Spec{
+				PrefixKind:    allocv1alpha2pb.PrefixKind_PREFIX_KIND_NETWORK,
+				Prefix:        "10.0.0.1/24",
+				AddressFamily: allocv1alpha2pb.AddressFamily(42),
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		"ResourcePoolKind": {
+			kind:     "vlan",
+			spec:     &allocv1alpha2pb.Spec{Id: 100},
+			wantCode: codes.OK,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			alloc, err := buildV1alpha1Request(&allocv1alpha2pb.Request{Name: "alloc", Kind: tc.kind, Spec: tc.spec}, !tc.get)
+			if got := status.Code(err); got != tc.wantCode {
+				t.Fatalf("got code %s, want %s: %v", got, tc.wantCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if got := alloc.GetSpec().GetPrefixkind(); got != tc.wantPrefixKind {
+				t.Errorf("got prefix kind %q, want %q", got, tc.wantPrefixKind)
+			}
+			if got := alloc.GetSpec().GetAddressFamily(); got != tc.wantAddressFamily {
+				t.Errorf("got address family %q, want %q", got, tc.wantAddressFamily)
+			}
+			if got, want := alloc.GetSpec().GetLeaseDuration(), tc.spec.GetLeaseDuration(); got != want {
+				t.Errorf("got lease duration %d, want %d", got, want)
+			}
+		})
+	}
+}
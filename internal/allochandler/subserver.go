@@ -21,26 +21,42 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/nokia/k8s-ipam/internal/ipam"
+	"github.com/nokia/k8s-ipam/internal/resourcepool"
+	"github.com/nokia/k8s-ipam/internal/vrf"
 	"github.com/nokia/k8s-ipam/pkg/alloc/allocpb"
+	allocv1alpha2pb "github.com/nokia/k8s-ipam/pkg/alloc/v1alpha2/allocpb"
 )
 
 type Options struct {
-	Ipam ipam.Ipam
+	Ipam  ipam.Ipam
+	Pools resourcepool.Pools
+	Vrf   vrf.Allocator
 }
 
 type SubServer interface {
 	Allocation(context.Context, *allocpb.Request) (*allocpb.Response, error)
 	DeAllocation(context.Context, *allocpb.Request) (*allocpb.Response, error)
+	GetAllocation(context.Context, *allocpb.Request) (*allocpb.Response, error)
+	ListRoutes(context.Context, *allocpb.ListRequest) (*allocpb.ListResponse, error)
+	Export(context.Context, *allocpb.ExportRequest) (*allocpb.ExportResponse, error)
+	Defrag(context.Context, *allocpb.DefragRequest) (*allocpb.DefragResponse, error)
+	AllocationV1alpha2(context.Context, *allocv1alpha2pb.Request) (*allocv1alpha2pb.Response, error)
+	DeAllocationV1alpha2(context.Context, *allocv1alpha2pb.Request) (*allocv1alpha2pb.Response, error)
+	GetAllocationV1alpha2(context.Context, *allocv1alpha2pb.Request) (*allocv1alpha2pb.Response, error)
 }
 
 func New(o *Options) SubServer {
 	s := &subServer{
-		ipam: o.Ipam,
+		ipam:  o.Ipam,
+		pools: o.Pools,
+		vrf:   o.Vrf,
 	}
 	return s
 }
 
 type subServer struct {
-	l    logr.Logger
-	ipam ipam.Ipam
+	l     logr.Logger
+	ipam  ipam.Ipam
+	pools resourcepool.Pools
+	vrf   vrf.Allocator
 }
@@ -0,0 +1,111 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package event
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/nokia/k8s-ipam/internal/ipam"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reasons of the events the ipam emits
+const (
+	ReasonAllocated               = "Allocated"
+	ReasonReleased                = "Released"
+	ReasonValidationFailed        = "ValidationFailed"
+	ReasonPoolExhausted           = "PoolExhausted"
+	ReasonQuotaExceeded           = "QuotaExceeded"
+	ReasonNetworkInstanceNotReady = "NetworkInstanceNotReady"
+	ReasonOverlap                 = "Overlap"
+	ReasonInvalidNesting          = "InvalidNesting"
+	ReasonNotFound                = "NotFound"
+	ReasonConflict                = "Conflict"
+	ReasonDeletionBlocked         = "DeletionBlocked"
+	ReasonCascadeDelete           = "CascadeDelete"
+	ReasonTerminating             = "Terminating"
+	ReasonDependentsInvalid       = "DependentsInvalid"
+	ReasonMigrated                = "Migrated"
+)
+
+// Recorder emits Kubernetes events on objects; an event that repeats the
+// last event of the object is not emitted again, such that an object that is
+// requeued does not emit the same event over and over
+type Recorder interface {
+	// Normal emits an event of type Normal
+	Normal(obj client.Object, reason, format string, args ...interface{})
+	// Warning emits an event of type Warning
+	Warning(obj client.Object, reason, format string, args ...interface{})
+	// Forget removes the last event of the object, to be called when the
+	// object is deleted
+	Forget(obj client.Object)
+}
+
+// NewRecorder returns a Recorder that emits the events with the event recorder
+func NewRecorder(r record.EventRecorder) Recorder {
+	return &recorder{
+		r:    r,
+		last: map[types.UID]event{},
+	}
+}
+
+type event struct {
+	eventType string
+	reason    string
+	message   string
+}
+
+type recorder struct {
+	r    record.EventRecorder
+	m    sync.Mutex
+	last map[types.UID]event
+}
+
+func (r *recorder) Normal(obj client.Object, reason, format string, args ...interface{}) {
+	r.emit(obj, event{eventType: corev1.EventTypeNormal, reason: reason, message: fmt.Sprintf(format, args...)})
+}
+
+func (r *recorder) Warning(obj client.Object, reason, format string, args ...interface{}) {
+	r.emit(obj, event{eventType: corev1.EventTypeWarning, reason: reason, message: fmt.Sprintf(format, args...)})
+}
+
+func (r *recorder) Forget(obj client.Object) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	delete(r.last, obj.GetUID())
+}
+
+func (r *recorder) emit(obj client.Object, e event) {
+	r.m.Lock()
+	if last, ok := r.last[obj.GetUID()]; ok && last == e {
+		r.m.Unlock()
+		return
+	}
+	r.last[obj.GetUID()] = e
+	r.m.Unlock()
+
+	r.r.Event(obj, e.eventType, e.reason, e.message)
+}
+
+// GetFailureReason returns the reason of the event of an allocation that failed
+func GetFailureReason(err error) string {
+	return string(ipam.GetConditionReason(err))
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exporthandler
+
+import (
+	"net/http"
+
+	"github.com/nokia/k8s-ipam/internal/ipam"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// Path is the path on which the export handler is served
+	Path = "/ipam/export"
+
+	networkInstanceParam = "network-instance"
+	formatParam          = "format"
+)
+
+var contentTypes = map[ipam.ExportFormat]string{
+	ipam.ExportFormatJSON: "application/json",
+	ipam.ExportFormatYAML: "application/yaml",
+	ipam.ExportFormatDOT:  "text/vnd.graphviz",
+}
+
+type Options struct {
+	Ipam ipam.Ipam
+}
+
+// New returns a http handler that exports the prefix hierarchy of a
+// network instance, e.g. /ipam/export?network-instance=vpc-1&format=dot
+func New(o *Options) http.Handler {
+	return &handler{
+		ipam: o.Ipam,
+	}
+}
+
+type handler struct {
+	ipam ipam.Ipam
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	l := log.FromContext(req.Context())
+
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	niName := req.URL.Query().Get(networkInstanceParam)
+	if niName == "" {
+		http.Error(w, "query parameter "+networkInstanceParam+" is required", http.StatusBadRequest)
+		return
+	}
+	format := ipam.ExportFormat(req.URL.Query().Get(formatParam))
+	if format == "" {
+		format = ipam.ExportFormatJSON
+	}
+	contentType, ok := contentTypes[format]
+	if !ok {
+		http.Error(w, "unsupported format "+string(format), http.StatusBadRequest)
+		return
+	}
+
+	data, err := h.ipam.Export(niName, format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	if _, err := w.Write(data); err != nil {
+		l.Error(err, "cannot write export response")
+	}
+}
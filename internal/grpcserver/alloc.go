@@ -45,7 +45,67 @@ func (s *GrpcServer) DeAllocation(ctx context.Context, req *allocpb.Request) (*a
 		return nil, err
 	}
 	defer s.sem.Release(1)
-	resp, err := s.allocHandler(ctx, req)
+	resp, err := s.deallocHandler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *GrpcServer) GetAllocation(ctx context.Context, req *allocpb.Request) (*allocpb.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	err := s.acquireSem(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.sem.Release(1)
+	resp, err := s.getHandler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *GrpcServer) ListRoutes(ctx context.Context, req *allocpb.ListRequest) (*allocpb.ListResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	err := s.acquireSem(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.sem.Release(1)
+	resp, err := s.listHandler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *GrpcServer) Export(ctx context.Context, req *allocpb.ExportRequest) (*allocpb.ExportResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	err := s.acquireSem(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.sem.Release(1)
+	resp, err := s.exportHandler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *GrpcServer) Defrag(ctx context.Context, req *allocpb.DefragRequest) (*allocpb.DefragResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	err := s.acquireSem(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.sem.Release(1)
+	resp, err := s.defragHandler(ctx, req)
 	if err != nil {
 		return nil, err
 	}
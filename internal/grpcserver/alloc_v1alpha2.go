@@ -0,0 +1,100 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcserver
+
+import (
+	"context"
+
+	allocv1alpha2pb "github.com/nokia/k8s-ipam/pkg/alloc/v1alpha2/allocpb"
+)
+
+// Alloc Handlers of the v1alpha2 allocation service
+type AllocV1alpha2Handler func(context.Context, *allocv1alpha2pb.Request) (*allocv1alpha2pb.Response, error)
+
+type DeAllocV1alpha2Handler func(context.Context, *allocv1alpha2pb.Request) (*allocv1alpha2pb.Response, error)
+
+type GetAllocV1alpha2Handler func(context.Context, *allocv1alpha2pb.Request) (*allocv1alpha2pb.Response, error)
+
+func WithAllocV1alpha2Handler(h AllocV1alpha2Handler) func(*GrpcServer) {
+	return func(s *GrpcServer) {
+		s.allocV1alpha2Handler = h
+	}
+}
+
+func WithDeAllocV1alpha2Handler(h DeAllocV1alpha2Handler) func(*GrpcServer) {
+	return func(s *GrpcServer) {
+		s.deallocV1alpha2Handler = h
+	}
+}
+
+func WithGetAllocV1alpha2Handler(h GetAllocV1alpha2Handler) func(*GrpcServer) {
+	return func(s *GrpcServer) {
+		s.getV1alpha2Handler = h
+	}
+}
+
+// allocationV1alpha2 serves the v1alpha2 allocation service, its methods have
+// the same names as the v1alpha1 methods of the GrpcServer
+type allocationV1alpha2 struct {
+	*GrpcServer
+	allocv1alpha2pb.UnimplementedAllocationServer
+}
+
+func (s *allocationV1alpha2) Allocation(ctx context.Context, req *allocv1alpha2pb.Request) (*allocv1alpha2pb.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	err := s.acquireSem(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.sem.Release(1)
+	resp, err := s.allocV1alpha2Handler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *allocationV1alpha2) DeAllocation(ctx context.Context, req *allocv1alpha2pb.Request) (*allocv1alpha2pb.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	err := s.acquireSem(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.sem.Release(1)
+	resp, err := s.deallocV1alpha2Handler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *allocationV1alpha2) GetAllocation(ctx context.Context, req *allocv1alpha2pb.Request) (*allocv1alpha2pb.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	err := s.acquireSem(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.sem.Release(1)
+	resp, err := s.getV1alpha2Handler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
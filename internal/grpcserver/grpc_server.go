@@ -23,6 +23,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/nokia/k8s-ipam/pkg/alloc/allocpb"
+	allocv1alpha2pb "github.com/nokia/k8s-ipam/pkg/alloc/v1alpha2/allocpb"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/semaphore"
 	"google.golang.org/grpc"
@@ -42,6 +43,15 @@ type GrpcServer struct {
 	//Alloc Handlers
 	allocHandler   AllocHandler
 	deallocHandler DeAllocHandler
+	getHandler     GetAllocHandler
+	listHandler    ListRoutesHandler
+	exportHandler  ExportHandler
+	defragHandler  DefragHandler
+
+	//v1alpha2 Alloc Handlers
+	allocV1alpha2Handler   AllocV1alpha2Handler
+	deallocV1alpha2Handler DeAllocV1alpha2Handler
+	getV1alpha2Handler     GetAllocV1alpha2Handler
 
 	//health handlers
 	checkHandler CheckHandler
@@ -61,6 +71,14 @@ type AllocHandler func(context.Context, *allocpb.Request) (*allocpb.Response, er
 
 type DeAllocHandler func(context.Context, *allocpb.Request) (*allocpb.Response, error)
 
+type GetAllocHandler func(context.Context, *allocpb.Request) (*allocpb.Response, error)
+
+type ListRoutesHandler func(context.Context, *allocpb.ListRequest) (*allocpb.ListResponse, error)
+
+type ExportHandler func(context.Context, *allocpb.ExportRequest) (*allocpb.ExportResponse, error)
+
+type DefragHandler func(context.Context, *allocpb.DefragRequest) (*allocpb.DefragResponse, error)
+
 type Option func(*GrpcServer)
 
 func New(c Config, opts ...Option) *GrpcServer {
@@ -102,6 +120,10 @@ func (s *GrpcServer) Start(ctx context.Context) error {
 	allocpb.RegisterAllocationServer(grpcServer, s)
 	s.l.Info("grpc server with allocation...")
 
+	// v1alpha2 is served side by side with v1alpha1 for existing clients
+	allocv1alpha2pb.RegisterAllocationServer(grpcServer, &allocationV1alpha2{GrpcServer: s})
+	s.l.Info("grpc server with allocation v1alpha2...")
+
 	healthpb.RegisterHealthServer(grpcServer, s)
 	s.l.Info("grpc server with health...")
 
@@ -138,6 +160,30 @@ func WithDeAllocHandler(h DeAllocHandler) func(*GrpcServer) {
 	}
 }
 
+func WithGetAllocHandler(h GetAllocHandler) func(*GrpcServer) {
+	return func(s *GrpcServer) {
+		s.getHandler = h
+	}
+}
+
+func WithListRoutesHandler(h ListRoutesHandler) func(*GrpcServer) {
+	return func(s *GrpcServer) {
+		s.listHandler = h
+	}
+}
+
+func WithExportHandler(h ExportHandler) func(*GrpcServer) {
+	return func(s *GrpcServer) {
+		s.exportHandler = h
+	}
+}
+
+func WithDefragHandler(h DefragHandler) func(*GrpcServer) {
+	return func(s *GrpcServer) {
+		s.defragHandler = h
+	}
+}
+
 func (s *GrpcServer) acquireSem(ctx context.Context) error {
 	select {
 	case <-ctx.Done():
@@ -0,0 +1,107 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// csv columns, the first column of an entry that is present is used
+var (
+	csvPrefixColumns  = []string{"prefix", "subnet", "cidr", "address", "ip"}
+	csvMaskColumns    = []string{"mask", "prefix-length", "prefixlength"}
+	csvRoleColumns    = []string{"kind", "role", "status"}
+	csvVRFColumns     = []string{"network-instance", "networkinstance", "vrf"}
+	csvNetworkColumns = []string{"network"}
+	csvNameColumns    = []string{"name", "hostname"}
+	csvGatewayColumns = []string{"gateway", "is_gateway"}
+	csvTypeColumns    = []string{"type"}
+)
+
+// readCSV reads a csv file with a header, e.g.
+//
+//	prefix,kind,network-instance,network,name,gateway,type
+//	10.0.0.0/8,aggregate,vpc-1,,,,
+//	10.0.1.1/24,network,vpc-1,net1,net1-gw,true,
+//	10.0.1.10/24,network,vpc-1,net1,upf1,,allocation
+func readCSV(r io.Reader) ([]*rawRecord, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	cr.FieldsPerRecord = -1
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read csv: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	header := map[string]int{}
+	for i, c := range rows[0] {
+		header[strings.ToLower(strings.TrimSpace(c))] = i
+	}
+	get := func(row []string, columns []string) string {
+		for _, c := range columns {
+			if i, ok := header[c]; ok && i < len(row) && strings.TrimSpace(row[i]) != "" {
+				return strings.TrimSpace(row[i])
+			}
+		}
+		return ""
+	}
+	if get(rows[0], csvPrefixColumns) == "" {
+		return nil, fmt.Errorf("csv header has no prefix column, expected one of %v", csvPrefixColumns)
+	}
+
+	raws := make([]*rawRecord, 0, len(rows)-1)
+	for n, row := range rows[1:] {
+		prefix := get(row, csvPrefixColumns)
+		if prefix == "" {
+			return nil, fmt.Errorf("csv line %d has no prefix", n+2)
+		}
+		if mask := get(row, csvMaskColumns); mask != "" && !strings.Contains(prefix, "/") {
+			prefix = prefix + "/" + mask
+		}
+		raw := &rawRecord{
+			name:    get(row, csvNameColumns),
+			prefix:  prefix,
+			vrf:     get(row, csvVRFColumns),
+			network: get(row, csvNetworkColumns),
+			gateway: isTrue(get(row, csvGatewayColumns)),
+		}
+		for _, c := range csvRoleColumns {
+			if i, ok := header[c]; ok && i < len(row) && strings.TrimSpace(row[i]) != "" {
+				raw.roles = append(raw.roles, strings.TrimSpace(row[i]))
+			}
+		}
+		switch strings.ToLower(get(row, csvTypeColumns)) {
+		case "allocation", "address":
+			raw.allocation = true
+		}
+		raws = append(raws, raw)
+	}
+	return raws, nil
+}
+
+func isTrue(s string) bool {
+	switch strings.ToLower(s) {
+	case "true", "yes", "1":
+		return true
+	}
+	return false
+}
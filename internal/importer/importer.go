@@ -0,0 +1,356 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package importer converts prefix exports of other IPAM systems into
+// IPPrefix and IPAllocation resources
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/internal/ipam"
+	"inet.af/netaddr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatNetBox  Format = "netbox"
+	FormatPhpIPAM Format = "phpipam"
+)
+
+// DefaultRoles maps the roles used by the supported formats to prefix kinds
+var DefaultRoles = map[string]ipamv1alpha1.PrefixKind{
+	"aggregate": ipamv1alpha1.PrefixKindAggregate,
+	"container": ipamv1alpha1.PrefixKindAggregate,
+	"network":   ipamv1alpha1.PrefixKindNetwork,
+	"active":    ipamv1alpha1.PrefixKindNetwork,
+	"subnet":    ipamv1alpha1.PrefixKindNetwork,
+	"pool":      ipamv1alpha1.PrefixKindPool,
+	"loopback":  ipamv1alpha1.PrefixKindLoopback,
+}
+
+type Options struct {
+	// Namespace of the generated resources
+	Namespace string
+	// NetworkInstance is used for records that have no vrf
+	NetworkInstance string
+	// Roles overwrite or extend the DefaultRoles
+	Roles map[string]string
+}
+
+// Record is a prefix or address read from an export
+type Record struct {
+	Name            string
+	Prefix          string
+	PrefixKind      ipamv1alpha1.PrefixKind
+	NetworkInstance string
+	Network         string
+	Labels          map[string]string
+	// Allocation indicates the record is an address assignment, which is
+	// imported as IPAllocation instead of IPPrefix
+	Allocation bool
+
+	p netaddr.IPPrefix
+}
+
+// Conflict is a record that cannot be imported
+type Conflict struct {
+	Record *Record
+	Reason string
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("%s %s (%s, network-instance %s): %s",
+		c.Record.PrefixKind, c.Record.Prefix, c.Record.Name, c.Record.NetworkInstance, c.Reason)
+}
+
+// rawRecord is the format independent representation of an exported
+// prefix, roles are tried in order and the first known role is used
+type rawRecord struct {
+	name       string
+	prefix     string
+	roles      []string
+	vrf        string
+	network    string
+	gateway    bool
+	allocation bool
+}
+
+// Read parses the export and resolves names, kinds, network instances
+// and networks of the records
+func Read(format Format, r io.Reader, o *Options) ([]*Record, error) {
+	var raws []*rawRecord
+	var err error
+	switch format {
+	case FormatCSV:
+		raws, err = readCSV(r)
+	case FormatNetBox:
+		raws, err = readNetBox(r)
+	case FormatPhpIPAM:
+		raws, err = readPhpIPAM(r)
+	default:
+		return nil, fmt.Errorf("unsupported format %s, supported: %s, %s, %s", format, FormatCSV, FormatNetBox, FormatPhpIPAM)
+	}
+	if err != nil {
+		return nil, err
+	}
+	roles := map[string]ipamv1alpha1.PrefixKind{}
+	for k, v := range DefaultRoles {
+		roles[k] = v
+	}
+	for k, v := range o.Roles {
+		roles[strings.ToLower(k)] = ipamv1alpha1.PrefixKind(v)
+	}
+
+	records := make([]*Record, 0, len(raws))
+	for _, raw := range raws {
+		rec, err := buildRecord(raw, roles, o)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	resolveNetworks(records)
+	sortRecords(records)
+	return records, nil
+}
+
+func buildRecord(raw *rawRecord, roles map[string]ipamv1alpha1.PrefixKind, o *Options) (*Record, error) {
+	p, err := netaddr.ParseIPPrefix(raw.prefix)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse prefix %q: %w", raw.prefix, err)
+	}
+	rec := &Record{
+		Prefix:          p.String(),
+		PrefixKind:      ipamv1alpha1.PrefixKindNetwork,
+		NetworkInstance: o.NetworkInstance,
+		Network:         sanitizeName(raw.network),
+		Labels:          map[string]string{},
+		Allocation:      raw.allocation,
+		p:               p,
+	}
+	for _, role := range raw.roles {
+		if kind, ok := roles[strings.ToLower(role)]; ok {
+			rec.PrefixKind = kind
+			break
+		}
+	}
+	if raw.vrf != "" {
+		rec.NetworkInstance = sanitizeName(raw.vrf)
+	}
+	if rec.NetworkInstance == "" {
+		return nil, fmt.Errorf("prefix %s has no vrf and no default network instance is set", raw.prefix)
+	}
+	if raw.gateway {
+		// gateways are defined by IPPrefixes, like in the samples
+		rec.Labels[ipamv1alpha1.NephioGatewayKey] = "true"
+		rec.Allocation = false
+	}
+	rec.Name = sanitizeName(raw.name)
+	if rec.Name == "" {
+		rec.Name = sanitizeName(strings.Join([]string{rec.NetworkInstance, p.IP().String(), fmt.Sprint(p.Bits())}, "-"))
+	}
+	return rec, nil
+}
+
+// resolveNetworks names the networks that are not named in the export after
+// their prefix and assigns the addresses to the network they belong to
+func resolveNetworks(records []*Record) {
+	for _, rec := range records {
+		if rec.PrefixKind == ipamv1alpha1.PrefixKindNetwork && rec.Network == "" &&
+			!rec.Allocation && !isAddress(rec.p) {
+			rec.Network = sanitizeName(strings.Join([]string{"net", rec.p.Masked().IP().String(), fmt.Sprint(rec.p.Bits())}, "-"))
+		}
+	}
+	for _, rec := range records {
+		if rec.PrefixKind != ipamv1alpha1.PrefixKindNetwork || rec.Network != "" {
+			continue
+		}
+		var parent *Record
+		for _, candidate := range records {
+			if candidate == rec ||
+				candidate.Allocation ||
+				candidate.PrefixKind != ipamv1alpha1.PrefixKindNetwork ||
+				candidate.NetworkInstance != rec.NetworkInstance ||
+				candidate.Network == "" ||
+				candidate.p.Bits() > rec.p.Bits() ||
+				!candidate.p.Masked().Contains(rec.p.IP()) {
+				continue
+			}
+			if parent == nil || candidate.p.Bits() > parent.p.Bits() {
+				parent = candidate
+			}
+		}
+		if parent != nil {
+			rec.Network = parent.Network
+			// the address is assigned with the prefix length of its network
+			rec.p = netaddr.IPPrefixFrom(rec.p.IP(), parent.p.Bits())
+			rec.Prefix = rec.p.String()
+		}
+	}
+}
+
+// sortRecords orders the records such that parents are created before their
+// children: aggregates first and shorter prefixes before longer ones
+func sortRecords(records []*Record) {
+	sort.SliceStable(records, func(i, j int) bool {
+		ai := records[i].PrefixKind == ipamv1alpha1.PrefixKindAggregate
+		aj := records[j].PrefixKind == ipamv1alpha1.PrefixKindAggregate
+		if ai != aj {
+			return ai
+		}
+		if records[i].Allocation != records[j].Allocation {
+			return !records[i].Allocation
+		}
+		if records[i].p.Bits() != records[j].p.Bits() {
+			return records[i].p.Bits() < records[j].p.Bits()
+		}
+		return records[i].p.IP().Less(records[j].p.IP())
+	})
+}
+
+// Validate applies the records to an offline ipam using the validation of
+// the ipam and returns the records that cannot be imported
+func Validate(ctx context.Context, records []*Record) []Conflict {
+	conflicts := []Conflict{}
+	i := ipam.New(nil)
+	names := map[string]*Record{}
+	nis := map[string]struct{}{}
+	for _, rec := range records {
+		if other, ok := names[rec.Name]; ok {
+			conflicts = append(conflicts, Conflict{Record: rec, Reason: fmt.Sprintf("name already used by %s", other.Prefix)})
+			continue
+		}
+		names[rec.Name] = rec
+
+		if _, ok := nis[rec.NetworkInstance]; !ok {
+			nis[rec.NetworkInstance] = struct{}{}
+			ni := &ipamv1alpha1.NetworkInstance{ObjectMeta: metav1.ObjectMeta{Name: rec.NetworkInstance}}
+			if err := i.Init(ctx, ni); err != nil {
+				conflicts = append(conflicts, Conflict{Record: rec, Reason: err.Error()})
+				continue
+			}
+		}
+
+		var alloc *ipam.Allocation
+		if rec.Allocation {
+			alloc = ipam.BuildAllocationFromIPAllocation(rec.ipAllocation(""))
+		} else {
+			alloc = ipam.BuildAllocationFromIPPrefix(rec.ipPrefix(""))
+		}
+		if _, err := i.AllocateIPPrefix(ctx, alloc); err != nil {
+			conflicts = append(conflicts, Conflict{Record: rec, Reason: err.Error()})
+		}
+	}
+	return conflicts
+}
+
+// Objects returns the IPPrefix and IPAllocation resources of the records
+func Objects(records []*Record, namespace string) []client.Object {
+	objs := make([]client.Object, 0, len(records))
+	for _, rec := range records {
+		if rec.Allocation {
+			objs = append(objs, rec.ipAllocation(namespace))
+			continue
+		}
+		objs = append(objs, rec.ipPrefix(namespace))
+	}
+	return objs
+}
+
+func (r *Record) ipPrefix(namespace string) *ipamv1alpha1.IPPrefix {
+	return &ipamv1alpha1.IPPrefix{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: ipamv1alpha1.GroupVersion.String(),
+			Kind:       ipamv1alpha1.IPPrefixKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.Name,
+			Namespace: namespace,
+			Labels:    r.labels(),
+		},
+		Spec: ipamv1alpha1.IPPrefixSpec{
+			PrefixKind:      string(r.PrefixKind),
+			Network:         r.Network,
+			Prefix:          r.Prefix,
+			NetworkInstance: r.NetworkInstance,
+		},
+	}
+}
+
+func (r *Record) ipAllocation(namespace string) *ipamv1alpha1.IPAllocation {
+	selector := map[string]string{
+		ipamv1alpha1.NephioNetworkInstanceKey: r.NetworkInstance,
+	}
+	if r.Network != "" {
+		selector[ipamv1alpha1.NephioNetworkNameKey] = r.Network
+	}
+	return &ipamv1alpha1.IPAllocation{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: ipamv1alpha1.GroupVersion.String(),
+			Kind:       ipamv1alpha1.IPAllocationKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.Name,
+			Namespace: namespace,
+			Labels:    r.labels(),
+		},
+		Spec: ipamv1alpha1.IPAllocationSpec{
+			PrefixKind: string(r.PrefixKind),
+			Prefix:     r.Prefix,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: selector,
+			},
+		},
+	}
+}
+
+func (r *Record) labels() map[string]string {
+	if len(r.Labels) == 0 {
+		return nil
+	}
+	l := make(map[string]string, len(r.Labels))
+	for k, v := range r.Labels {
+		l[k] = v
+	}
+	return l
+}
+
+func isAddress(p netaddr.IPPrefix) bool {
+	return p.Bits() == p.IP().BitLen()
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// sanitizeName turns s into a valid kubernetes resource name
+func sanitizeName(s string) string {
+	s = invalidNameChars.ReplaceAllString(strings.ToLower(s), "-")
+	s = strings.Trim(s, "-")
+	if len(s) > 63 {
+		s = strings.Trim(s[:63], "-")
+	}
+	return s
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+)
+
+// recordString returns the fields of the record that are imported
+func recordString(rec *Record) string {
+	s := fmt.Sprintf("%s %s ni=%s network=%s name=%s", rec.PrefixKind, rec.Prefix, rec.NetworkInstance, rec.Network, rec.Name)
+	if rec.Allocation {
+		s += " allocation"
+	}
+	if rec.Labels[ipamv1alpha1.NephioGatewayKey] == "true" {
+		s += " gateway"
+	}
+	return s
+}
+
+func TestRead(t *testing.T) {
+	cases := map[string]struct {
+		format  Format
+		input   string
+		options Options
+		want    []string
+		wantErr bool
+	}{
+		"CSV": {
+			format: FormatCSV,
+			input: `prefix,kind,network-instance,network,name,gateway,type
+10.0.0.0/8,aggregate,vpc-1,,,,
+10.0.1.1/24,network,vpc-1,net1,net1-gw,true,
+10.0.1.10/24,network,vpc-1,net1,upf1,,allocation
+`,
+			want: []string{
+				"aggregate 10.0.0.0/8 ni=vpc-1 network= name=vpc-1-10-0-0-0-8",
+				"network 10.0.1.1/24 ni=vpc-1 network=net1 name=net1-gw gateway",
+				"network 10.0.1.10/24 ni=vpc-1 network=net1 name=upf1 allocation",
+			},
+		},
+		"CSVMaskColumn": {
+			format: FormatCSV,
+			input: `address,mask,vrf,role
+10.1.2.5,32,,
+10.1.2.0,24,,
+10.1.0.0,16,,container
+`,
+			options: Options{NetworkInstance: "default-ni"},
+			want: []string{
+				"aggregate 10.1.0.0/16 ni=default-ni network= name=default-ni-10-1-0-0-16",
+				"network 10.1.2.0/24 ni=default-ni network=net-10-1-2-0-24 name=default-ni-10-1-2-0-24",
+				"network 10.1.2.5/24 ni=default-ni network=net-10-1-2-0-24 name=default-ni-10-1-2-5-32",
+			},
+		},
+		"CSVRoles": {
+			format: FormatCSV,
+			input: `prefix,role,vrf
+10.0.0.0/24,dhcp,vpc-1
+`,
+			options: Options{Roles: map[string]string{"DHCP": "pool"}},
+			want: []string{
+				"pool 10.0.0.0/24 ni=vpc-1 network= name=vpc-1-10-0-0-0-24",
+			},
+		},
+		"CSVNoPrefixColumn": {
+			format:  FormatCSV,
+			input:   "name,vrf\nhost,vpc-1\n",
+			wantErr: true,
+		},
+		"CSVNoPrefix": {
+			format:  FormatCSV,
+			input:   "prefix,vrf\n,vpc-1\n",
+			wantErr: true,
+		},
+		"CSVNoNetworkInstance": {
+			format:  FormatCSV,
+			input:   "prefix\n10.0.0.0/8\n",
+			wantErr: true,
+		},
+		"NetBoxPrefixes": {
+			format: FormatNetBox,
+			input: `{"count": 2, "results": [
+  {"prefix": "10.0.0.0/16", "vrf": {"name": "VPC 1"}, "role": {"slug": "aggregate"}, "status": {"value": "active"}},
+  {"prefix": "10.0.1.0/24", "vrf": null, "status": "active", "is_pool": true}
+]}`,
+			options: Options{NetworkInstance: "default-ni"},
+			want: []string{
+				"aggregate 10.0.0.0/16 ni=vpc-1 network= name=vpc-1-10-0-0-0-16",
+				"pool 10.0.1.0/24 ni=default-ni network= name=default-ni-10-0-1-0-24",
+			},
+		},
+		"NetBoxAddresses": {
+			format: FormatNetBox,
+			input: `[
+  {"address": "10.0.1.1/24", "vrf": {"name": "vpc-1"}, "dns_name": "gw.example.com", "tags": [{"slug": "gateway"}]},
+  {"address": "10.0.1.5/24", "vrf": {"name": "vpc-1"}, "dns_name": "host1"}
+]`,
+			want: []string{
+				"network 10.0.1.1/24 ni=vpc-1 network=net-10-0-1-0-24 name=gw-example-com gateway",
+				"network 10.0.1.5/24 ni=vpc-1 network=net-10-0-1-0-24 name=host1 allocation",
+			},
+		},
+		"NetBoxNoPrefix": {
+			format:  FormatNetBox,
+			input:   `[{"vrf": {"name": "vpc-1"}}]`,
+			wantErr: true,
+		},
+		"NetBoxInvalid": {
+			format:  FormatNetBox,
+			input:   `{"results": "none"}`,
+			wantErr: true,
+		},
+		"PhpIPAM": {
+			format: FormatPhpIPAM,
+			input: `{"code": 200, "data": [
+  {"id": "1", "subnet": "10.0.0.0", "mask": "16", "masterSubnetId": "0", "vrfId": "2"},
+  {"id": 3, "subnet": "10.0.1.0", "mask": 24, "masterSubnetId": "1", "vrfId": 2, "isPool": "1"},
+  {"id": "5", "ip": "10.0.1.1", "subnetId": "3", "hostname": "gw", "is_gateway": "1"},
+  {"id": "6", "ip": "10.0.1.6", "subnetId": 3, "hostname": "host6"}
+]}`,
+			want: []string{
+				"aggregate 10.0.0.0/16 ni=vrf-2 network= name=vrf-2-10-0-0-0-16",
+				"pool 10.0.1.0/24 ni=vrf-2 network= name=vrf-2-10-0-1-0-24",
+				"network 10.0.1.1/24 ni=vrf-2 network=net-10-0-1-0-24 name=gw gateway",
+				"network 10.0.1.6/24 ni=vrf-2 network=net-10-0-1-0-24 name=host6 allocation",
+			},
+		},
+		"PhpIPAMUnknownSubnet": {
+			format:  FormatPhpIPAM,
+			input:   `[{"id": "5", "ip": "10.0.1.1", "subnetId": "3"}]`,
+			wantErr: true,
+		},
+		"UnknownFormat": {
+			format:  "infoblox",
+			input:   "",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			records, err := Read(tc.format, strings.NewReader(tc.input), &tc.options)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tc.wantErr)
+			}
+			got := make([]string, 0, len(records))
+			for _, rec := range records {
+				got = append(got, recordString(rec))
+			}
+			if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+				t.Errorf("got records\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tc.want, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// netboxObject holds the fields of the NetBox prefix and ip address
+// REST API objects that are relevant for the import
+type netboxObject struct {
+	Prefix  string          `json:"prefix"`
+	Address string          `json:"address"`
+	VRF     *netboxVRF      `json:"vrf"`
+	Role    json.RawMessage `json:"role"`
+	Status  json.RawMessage `json:"status"`
+	IsPool  bool            `json:"is_pool"`
+	DNSName string          `json:"dns_name"`
+	Tags    []netboxTag     `json:"tags"`
+}
+
+type netboxVRF struct {
+	Name string `json:"name"`
+}
+
+type netboxTag struct {
+	Slug string `json:"slug"`
+}
+
+// netboxChoice is the representation of a choice field, e.g. the status
+// of a prefix, or of a nested object, e.g. the role of a prefix
+type netboxChoice struct {
+	Value string `json:"value"`
+	Slug  string `json:"slug"`
+}
+
+// readNetBox reads the result of the NetBox /api/ipam/prefixes/ or
+// /api/ipam/ip-addresses/ endpoint, either the paginated response or the
+// list of results
+func readNetBox(r io.Reader) ([]*rawRecord, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	objs := []netboxObject{}
+	if err := json.Unmarshal(data, &objs); err != nil {
+		page := struct {
+			Results []netboxObject `json:"results"`
+		}{}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("cannot parse netbox export: %w", err)
+		}
+		objs = page.Results
+	}
+
+	raws := make([]*rawRecord, 0, len(objs))
+	for _, o := range objs {
+		raw := &rawRecord{
+			prefix: o.Prefix,
+		}
+		if o.Address != "" {
+			raw.prefix = o.Address
+			raw.name = o.DNSName
+			raw.allocation = true
+		}
+		if raw.prefix == "" {
+			return nil, fmt.Errorf("netbox object without prefix or address")
+		}
+		if o.VRF != nil {
+			raw.vrf = o.VRF.Name
+		}
+		if role := netboxChoiceValue(o.Role); role != "" {
+			raw.roles = append(raw.roles, role)
+		}
+		if o.IsPool {
+			raw.roles = append(raw.roles, "pool")
+		}
+		if status := netboxChoiceValue(o.Status); status != "" {
+			raw.roles = append(raw.roles, status)
+		}
+		for _, t := range o.Tags {
+			if t.Slug == "gateway" {
+				raw.gateway = true
+			}
+		}
+		raws = append(raws, raw)
+	}
+	return raws, nil
+}
+
+func netboxChoiceValue(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	c := &netboxChoice{}
+	if err := json.Unmarshal(raw, c); err != nil || c == nil {
+		return ""
+	}
+	if c.Slug != "" {
+		return c.Slug
+	}
+	return c.Value
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// phpipamObject holds the fields of the phpIPAM subnet and address REST
+// API objects that are relevant for the import
+type phpipamObject struct {
+	ID             phpipamString `json:"id"`
+	Subnet         string        `json:"subnet"`
+	Mask           phpipamString `json:"mask"`
+	MasterSubnetID phpipamString `json:"masterSubnetId"`
+	VRFID          phpipamString `json:"vrfId"`
+	IsPool         phpipamString `json:"isPool"`
+	IP             string        `json:"ip"`
+	SubnetID       phpipamString `json:"subnetId"`
+	Hostname       string        `json:"hostname"`
+	IsGateway      phpipamString `json:"is_gateway"`
+}
+
+// phpipamString is a field that phpIPAM returns as string or as number,
+// depending on the version
+type phpipamString string
+
+func (s *phpipamString) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err == nil {
+		*s = phpipamString(str)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	*s = phpipamString(n.String())
+	return nil
+}
+
+// readPhpIPAM reads the result of the phpIPAM subnets and addresses API,
+// either the api response or the list of its data
+func readPhpIPAM(r io.Reader) ([]*rawRecord, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	objs := []phpipamObject{}
+	if err := json.Unmarshal(data, &objs); err != nil {
+		resp := struct {
+			Data []phpipamObject `json:"data"`
+		}{}
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return nil, fmt.Errorf("cannot parse phpipam export: %w", err)
+		}
+		objs = resp.Data
+	}
+
+	subnets := map[phpipamString]phpipamObject{}
+	masters := map[phpipamString]bool{}
+	for _, o := range objs {
+		if o.Subnet != "" {
+			subnets[o.ID] = o
+			masters[o.MasterSubnetID] = true
+		}
+	}
+
+	raws := make([]*rawRecord, 0, len(objs))
+	for _, o := range objs {
+		switch {
+		case o.Subnet != "":
+			raw := &rawRecord{
+				prefix: o.Subnet + "/" + string(o.Mask),
+				vrf:    phpipamVRF(o.VRFID),
+			}
+			if isTrue(string(o.IsPool)) {
+				raw.roles = append(raw.roles, "pool")
+			}
+			if masters[o.ID] {
+				raw.roles = append(raw.roles, "container")
+			}
+			raw.roles = append(raw.roles, "subnet")
+			raws = append(raws, raw)
+		case o.IP != "":
+			subnet, ok := subnets[o.SubnetID]
+			if !ok {
+				return nil, fmt.Errorf("address %s belongs to subnet %s that is not part of the export", o.IP, o.SubnetID)
+			}
+			raws = append(raws, &rawRecord{
+				name:       o.Hostname,
+				prefix:     o.IP + "/" + string(subnet.Mask),
+				roles:      []string{"subnet"},
+				vrf:        phpipamVRF(subnet.VRFID),
+				gateway:    isTrue(string(o.IsGateway)),
+				allocation: true,
+			})
+		default:
+			return nil, fmt.Errorf("phpipam object %s without subnet or ip", o.ID)
+		}
+	}
+	return raws, nil
+}
+
+func phpipamVRF(id phpipamString) string {
+	if id == "" || id == "0" {
+		return ""
+	}
+	return "vrf-" + string(id)
+}
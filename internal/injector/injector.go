@@ -18,37 +18,50 @@ package injector
 
 import (
 	"context"
-	"fmt"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/nokia/k8s-ipam/internal/backoff"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+const (
+	defaultPollInterval = 1 * time.Minute
+)
+
 type Injector interface {
 	GetName() string
 	Run(ctx context.Context)
 	Stop()
 }
 
+// InjectorFn performs a single injection attempt, an error indicates the
+// injection is not yet satisfied and needs to be retried
 type InjectorFn func(context.Context, types.NamespacedName) error
 
 type Config struct {
 	NamespacedName  types.NamespacedName
 	InjectorHandler InjectorFn
 	Client          client.Client
+	// PollInterval is the time the injector waits before starting a new
+	// retry cycle when the previous backoff cycle did not succeed
+	PollInterval time.Duration
 }
 
 func New(c *Config) Injector {
+	pollInterval := c.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
 	return &injector{
 		c:              c.Client,
 		namespacedName: c.NamespacedName,
 		injectorFn:     c.InjectorHandler,
+		pollInterval:   pollInterval,
 		retryAttempts:  0,
 	}
 }
@@ -57,8 +70,13 @@ type injector struct {
 	c              client.Client
 	namespacedName types.NamespacedName
 	retryAttempts  int
+	pollInterval   time.Duration
 	injectorFn     InjectorFn
-	cancelFn       context.CancelFunc
+
+	// m guards the cancelFn, Stop can be called before Run set it
+	m        sync.Mutex
+	cancelFn context.CancelFunc
+	stopped  bool
 
 	l logr.Logger
 }
@@ -67,19 +85,30 @@ func (r *injector) GetName() string {
 	return r.namespacedName.String()
 }
 
-// Run starts the resolver. Run will not return
-// before resolver loop is stopped by ctx.
+// Run starts the injector. Run returns when the injection succeeded
+// or when the injector is stopped.
 func (r *injector) Run(ctx context.Context) {
 	defer runtime.HandleCrash()
 	// TBD do we need callbacks on start
 
+	r.m.Lock()
+	if r.stopped {
+		// the injector was stopped before it got started
+		r.m.Unlock()
+		return
+	}
 	ctx, r.cancelFn = context.WithCancel(ctx)
+	r.m.Unlock()
 	r.inject(ctx)
 }
 
 func (r *injector) Stop() {
-	fmt.Println("Stop", r.GetName())
-	r.cancelFn()
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.stopped = true
+	if r.cancelFn != nil {
+		r.cancelFn()
+	}
 }
 
 func (r *injector) inject(ctx context.Context) {
@@ -88,17 +117,24 @@ func (r *injector) inject(ctx context.Context) {
 
 	r.l = log.FromContext(ctx)
 
-	wait.Until(func() {
+	for {
 		r.l.Info("inject loop start", "name", r.GetName())
-		r.injector(ctx)
-		r.l.Info("inject done", "name", r.GetName())
-	}, 1*time.Minute, ctx.Done())
-
-	r.l.Info("inject loop done", "name", r.GetName())
-
+		if r.injector(ctx) {
+			r.l.Info("inject loop done", "name", r.GetName())
+			return
+		}
+		select {
+		case <-ctx.Done():
+			r.l.Info("inject loop stopped", "name", r.GetName())
+			return
+		case <-time.After(r.pollInterval):
+		}
+	}
 }
 
-func (r *injector) injector(ctx context.Context) {
+// injector retries the injection using a backoff policy and
+// returns true if the injection succeeded
+func (r *injector) injector(ctx context.Context) bool {
 	p := backoff.NewConstantPolicy()
 	b := p.Start(ctx)
 	r.retryAttempts = 0
@@ -112,6 +148,7 @@ func (r *injector) injector(ctx context.Context) {
 			r.l.Error(err, "injection failed")
 			continue
 		}
-		return
+		return true
 	}
+	return false
 }
@@ -39,14 +39,27 @@ func New() Injectors {
 	}
 }
 
+// Run starts the injector if no injector with the same name is running.
+// The injector is removed from the list once it finishes.
 func (r *injectors) Run(i injector.Injector) {
 	r.m.Lock()
 	defer r.m.Unlock()
 	if _, ok := r.injectors[i.GetName()]; !ok {
 		r.injectors[i.GetName()] = i
-		go r.injectors[i.GetName()].Run(context.Background())
+		go func() {
+			i.Run(context.Background())
+			r.done(i)
+		}()
 	}
+}
 
+// done removes the injector from the list, when it was not replaced in the meantime
+func (r *injectors) done(i injector.Injector) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if existing, ok := r.injectors[i.GetName()]; ok && existing == i {
+		delete(r.injectors, i.GetName())
+	}
 }
 
 func (r *injectors) Stop(i injector.Injector) {
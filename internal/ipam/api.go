@@ -19,6 +19,7 @@ package ipam
 import (
 	"encoding/json"
 	"strings"
+	"time"
 
 	"github.com/hansthienpondt/goipam/pkg/table"
 	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
@@ -33,22 +34,30 @@ import (
 
 // +k8s:deepcopy-gen=false
 type Allocation struct {
-	NamespacedName  types.NamespacedName       `json:"namespacedName,omitempty"`
-	Origin          ipamv1alpha1.Origin        `json:"origin,omitempty"`
-	NetworkInstance string                     `json:"networkInstance,omitempty"`
-	PrefixKind      ipamv1alpha1.PrefixKind    `json:"prefixKind,omitempty"`
-	AddresFamily    ipamv1alpha1.AddressFamily `json:"addressFamily,omitempty"` // only used for alloc w/o prefix
-	Prefix          string                     `json:"prefix,omitempty"`
-	PrefixLength    uint8                      `json:"prefixLength,omitempty"` // only used for alloc w/o prefix and prefix kind = pool
-	Network         string                     `json:"network,omitempty"`      // explicitly mentioned for prefixkind network
-	Labels          map[string]string          `json:"labels,omitempty"`
-	SelectorLabels  map[string]string          `json:"selectorLabels,omitempty"`
+	NamespacedName  types.NamespacedName        `json:"namespacedName,omitempty"`
+	Origin          ipamv1alpha1.Origin         `json:"origin,omitempty"`
+	NetworkInstance string                      `json:"networkInstance,omitempty"`
+	PrefixKind      ipamv1alpha1.PrefixKind     `json:"prefixKind,omitempty"`
+	AddresFamily    ipamv1alpha1.AddressFamily  `json:"addressFamily,omitempty"` // only used for alloc w/o prefix
+	Prefix          string                      `json:"prefix,omitempty"`
+	PrefixLength    uint8                       `json:"prefixLength,omitempty"` // only used for alloc w/o prefix and prefix kind = pool
+	Network         string                      `json:"network,omitempty"`      // explicitly mentioned for prefixkind network
+	Labels          map[string]string           `json:"labels,omitempty"`
+	SelectorLabels  map[string]string           `json:"selectorLabels,omitempty"`
+	Endpoints       []ipamv1alpha1.LinkEndpoint `json:"endpoints,omitempty"` // only used for prefixkind link
+	LeaseDuration   time.Duration               `json:"leaseDuration,omitempty"`
 	//specificLabels  map[string]string
 }
 
 type AllocatedPrefix struct {
 	AllocatedPrefix string
 	Gateway         string
+	Gateways        []ipamv1alpha1.Gateway
+	ParentPrefix    string
+	Endpoints       []ipamv1alpha1.AllocatedLinkEndpoint
+	// LeaseExpiry is the time the lease of the allocation expires, zero
+	// when the allocation has no lease
+	LeaseExpiry time.Time
 }
 
 func (r *Allocation) GetName() string {
@@ -185,7 +194,13 @@ func (r *Allocation) GetAllocSelector() (labels.Selector, error) {
 		}
 		fullselector = fullselector.Add(*req)
 	}
-	return fullselector, nil
+	// routes mirrored from another network instance belong to allocations of
+	// that network instance, even when the name is the same
+	req, err := labels.NewRequirement(ipamv1alpha1.NephioSourceNIKey, selection.DoesNotExist, nil)
+	if err != nil {
+		return nil, err
+	}
+	return fullselector.Add(*req), nil
 }
 
 func (r *Allocation) GetFullSelector() (labels.Selector, error) {
@@ -215,6 +230,10 @@ func (r *Allocation) GetFullLabels() map[string]string {
 	for k, v := range r.GetSelectorLabels() {
 		l[k] = v
 	}
+	// the namespace identifies the tenant of the allocation
+	if r.GetNameSpace() != "" {
+		l[ipamv1alpha1.NephioNamespaceKey] = r.GetNameSpace()
+	}
 	return l
 }
 
@@ -271,6 +290,8 @@ func BuildAllocationFromIPAllocation(cr *ipamv1alpha1.IPAllocation) *Allocation
 		Network:         cr.Spec.Selector.MatchLabels[ipamv1alpha1.NephioNetworkNameKey],
 		Labels:          cr.GetLabels(),
 		SelectorLabels:  cr.Spec.Selector.MatchLabels,
+		Endpoints:       cr.Spec.Endpoints,
+		LeaseDuration:   getLeaseDuration(cr.Spec.LeaseDuration),
 	}
 }
 
@@ -289,7 +310,23 @@ func BuildAllocationFromGRPCAlloc(alloc *allocpb.Request) *Allocation {
 		Network:         alloc.GetSpec().GetSelector()[ipamv1alpha1.NephioNetworkNameKey],
 		Labels:          alloc.GetLabels(),
 		SelectorLabels:  alloc.GetSpec().GetSelector(),
+		Endpoints:       buildLinkEndpointsFromGRPCAlloc(alloc),
+		LeaseDuration:   time.Duration(alloc.GetSpec().GetLeaseDuration()) * time.Second,
+	}
+}
+
+func buildLinkEndpointsFromGRPCAlloc(alloc *allocpb.Request) []ipamv1alpha1.LinkEndpoint {
+	if len(alloc.GetSpec().GetEndpoints()) == 0 {
+		return nil
+	}
+	eps := make([]ipamv1alpha1.LinkEndpoint, 0, len(alloc.GetSpec().GetEndpoints()))
+	for _, ep := range alloc.GetSpec().GetEndpoints() {
+		eps = append(eps, ipamv1alpha1.LinkEndpoint{
+			Node:      ep.GetNode(),
+			Interface: ep.GetInterface(),
+		})
 	}
+	return eps
 }
 
 func (in *Allocation) DeepCopy() (*Allocation, error) {
@@ -0,0 +1,474 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hansthienpondt/goipam/pkg/table"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/pkg/errors"
+	"inet.af/netaddr"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefragPlan is the plan to compact the allocations of a network instance
+type DefragPlan struct {
+	NetworkInstance string `json:"networkInstance"`
+	// Executed indicates the plan was applied to the routing table
+	Executed bool            `json:"executed"`
+	Prefixes []*DefragPrefix `json:"prefixes"`
+}
+
+// DefragPrefix is the plan to compact the allocations of an aggregate or pool prefix
+type DefragPrefix struct {
+	Prefix     string `json:"prefix"`
+	PrefixKind string `json:"kind"`
+	// LargestFreePrefixLength and FreeBlocks describe the free space before the plan
+	LargestFreePrefixLength uint8 `json:"largestFreePrefixLength,omitempty"`
+	FreeBlocks              int   `json:"freeBlocks"`
+	// PlannedLargestFreePrefixLength and PlannedFreeBlocks describe the free space after the plan
+	PlannedLargestFreePrefixLength uint8 `json:"plannedLargestFreePrefixLength,omitempty"`
+	PlannedFreeBlocks              int   `json:"plannedFreeBlocks"`
+	// Pinned are the prefixes that are allocated statically, they are never moved
+	Pinned []string     `json:"pinned,omitempty"`
+	Moves  []DefragMove `json:"moves,omitempty"`
+}
+
+// DefragMove moves an allocation from its old prefix to a new prefix
+type DefragMove struct {
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	PrefixKind string `json:"kind,omitempty"`
+	OldPrefix  string `json:"oldPrefix"`
+	NewPrefix  string `json:"newPrefix"`
+	// Signalled indicates the status of the IPAllocation of the allocation was
+	// updated with the new prefix; allocations without an IPAllocation, e.g.
+	// allocated over grpc, learn the new prefix from the plan or GetAllocation
+	Signalled bool `json:"signalled,omitempty"`
+}
+
+// defragSubtree is a prefix that is moved together with the prefixes nested in it
+type defragSubtree struct {
+	from   netaddr.IPPrefix
+	to     netaddr.IPPrefix
+	routes table.Routes
+}
+
+// Defrag plans to compact the dynamic allocations of the aggregate and pool
+// prefixes of the network instance, or of the given prefix only, towards the
+// start of the prefix. When execute is set the plan is applied to the routing
+// table and the owners of the moved allocations are signalled through the
+// status of their IPAllocation.
+func (r *ipam) Defrag(ctx context.Context, niName, prefix string, execute bool) (*DefragPlan, error) {
+	r.l = log.FromContext(ctx)
+	r.l.Info("defrag", "networkInstance", niName, "prefix", prefix, "execute", execute)
+
+	rt, ok := r.get(niName)
+	if !ok {
+		return nil, newError(ErrNetworkInstanceNotReady, fmt.Sprintf("network-instance %s", niName))
+	}
+	// the plan is executed on the routing table it was built on, no allocation
+	// can take the free space the plan moves prefixes to in between
+	r.am.Lock()
+	plan, moved, err := r.defrag(rt, niName, prefix, execute)
+	r.am.Unlock()
+	if err != nil || moved == 0 {
+		return plan, err
+	}
+	r.l.Info("defrag executed", "networkInstance", niName, "moves", moved)
+
+	if err := r.updateNetworkInstanceStatus(ctx, &Allocation{NetworkInstance: niName}); err != nil {
+		return plan, err
+	}
+	if _, err := r.syncImportsIfNeeded(ctx); err != nil {
+		return plan, err
+	}
+	return plan, r.signalDefragMoves(ctx, plan)
+}
+
+// defrag plans the compaction of the prefixes and executes the plan when
+// requested, it returns the number of subtrees that were moved
+func (r *ipam) defrag(rt *table.RouteTable, niName, prefix string, execute bool) (*DefragPlan, int, error) {
+	scopes, err := getDefragScopes(rt, prefix)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	plan := &DefragPlan{
+		NetworkInstance: niName,
+		Prefixes:        make([]*DefragPrefix, 0, len(scopes)),
+	}
+	// the plan is built on a copy of the routing table, the moves of a prefix
+	// are reflected in the free space of the next prefixes
+	dryrunrt := copyRoutingTable(rt)
+	subtrees := []*defragSubtree{}
+	for _, scope := range scopes {
+		dp, moved := planDefrag(dryrunrt, scope)
+		plan.Prefixes = append(plan.Prefixes, dp)
+		subtrees = append(subtrees, moved...)
+	}
+	if !execute || len(subtrees) == 0 {
+		return plan, 0, nil
+	}
+	if err := moveDefragSubtrees(rt, subtrees); err != nil {
+		return nil, 0, err
+	}
+	plan.Executed = true
+	return plan, len(subtrees), nil
+}
+
+// moveDefragSubtrees moves the subtrees to their new prefixes; when a route
+// cannot be moved, the routes that were moved are restored such that the
+// routing table is left as it was
+func moveDefragSubtrees(rt *table.RouteTable, subtrees []*defragSubtree) error {
+	deleted := table.Routes{}
+	added := table.Routes{}
+	rollback := func() {
+		for _, route := range added {
+			_, _, _ = rt.Delete(route)
+		}
+		for _, route := range deleted {
+			_ = rt.Add(route)
+		}
+	}
+	// a new prefix can be the old prefix of another subtree, so all subtrees
+	// are removed before any of them is added again
+	for _, st := range subtrees {
+		for _, route := range st.routes {
+			if _, _, err := rt.Delete(route); err != nil {
+				rollback()
+				return errors.Wrap(err, "cannot delete prefix")
+			}
+			deleted = append(deleted, route)
+		}
+	}
+	for _, st := range subtrees {
+		for _, route := range st.translate() {
+			if err := rt.Add(route); err != nil {
+				rollback()
+				return errors.Wrap(err, "cannot add prefix")
+			}
+			added = append(added, route)
+		}
+	}
+	return nil
+}
+
+// getDefragScopes returns the prefixes whose allocations are compacted; the
+// given prefix or all aggregate and pool prefixes that are not moved
+// themselves. Prefixes imported from another network instance are compacted
+// in the network instance that owns them.
+func getDefragScopes(rt *table.RouteTable, prefix string) (table.Routes, error) {
+	if prefix != "" {
+		p, err := netaddr.ParseIPPrefix(prefix)
+		if err != nil {
+			return nil, newError(ErrValidationFailed, err.Error())
+		}
+		route, ok, err := rt.Get(p)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot get ip prefix")
+		}
+		if !ok {
+			return nil, newError(ErrNotFound, fmt.Sprintf("prefix %s", prefix))
+		}
+		if owner := route.GetLabels().Get(ipamv1alpha1.NephioSourceNIKey); owner != "" {
+			return nil, newError(ErrValidationFailed, fmt.Sprintf("prefix %s is imported from network instance %s, it can only be compacted in %s", prefix, owner, owner))
+		}
+		if !isDefragScope(route) {
+			return nil, newError(ErrValidationFailed, fmt.Sprintf("prefix %s is a %s prefix, only %s and %s prefixes can be compacted", prefix,
+				route.GetLabels().Get(ipamv1alpha1.NephioPrefixKindKey), ipamv1alpha1.PrefixKindAggregate, ipamv1alpha1.PrefixKindPool))
+		}
+		return table.Routes{route}, nil
+	}
+
+	scopes := table.Routes{}
+	for _, route := range rt.GetTable() {
+		if isDefragScope(route) && !isDynamic(route) && route.GetLabels().Get(ipamv1alpha1.NephioSourceNIKey) == "" {
+			scopes = append(scopes, route)
+		}
+	}
+	sort.SliceStable(scopes, func(i, j int) bool {
+		if scopes[i].IPPrefix().IP() != scopes[j].IPPrefix().IP() {
+			return scopes[i].IPPrefix().IP().Less(scopes[j].IPPrefix().IP())
+		}
+		return scopes[i].IPPrefix().Bits() < scopes[j].IPPrefix().Bits()
+	})
+	return scopes, nil
+}
+
+func isDefragScope(route *table.Route) bool {
+	switch ipamv1alpha1.PrefixKind(route.GetLabels().Get(ipamv1alpha1.NephioPrefixKindKey)) {
+	case ipamv1alpha1.PrefixKindAggregate, ipamv1alpha1.PrefixKindPool:
+		return true
+	}
+	return false
+}
+
+// isDynamic returns true if the prefix was allocated by the ipam and is not
+// pinned by the allocation
+func isDynamic(route *table.Route) bool {
+	// routes of other network instances are renumbered by the network instance that owns them
+	return route.GetLabels().Get(ipamv1alpha1.NephioDynamicKey) == "true" &&
+		route.GetLabels().Get(ipamv1alpha1.NephioSourceNIKey) == ""
+}
+
+// planDefrag repacks the dynamic allocations of the scope, largest first, at
+// the lowest free prefix of the scope. The allocations are only moved when the
+// free space of the scope ends up less fragmented. The routing table is
+// updated with the moves.
+func planDefrag(rt *table.RouteTable, scope *table.Route) (*DefragPrefix, []*defragSubtree) {
+	dp := &DefragPrefix{
+		Prefix:     scope.IPPrefix().String(),
+		PrefixKind: scope.GetLabels().Get(ipamv1alpha1.NephioPrefixKindKey),
+	}
+	dp.LargestFreePrefixLength, dp.FreeBlocks = getFreeSpace(rt, scope.IPPrefix())
+
+	subtrees := []*defragSubtree{}
+	for _, route := range getChildren(rt, scope.IPPrefix()) {
+		if getParentPrefix(rt, route) != scope.IPPrefix().String() {
+			continue
+		}
+		st, ok := getDefragSubtree(rt, route)
+		if !ok {
+			dp.Pinned = append(dp.Pinned, route.IPPrefix().String())
+			continue
+		}
+		subtrees = append(subtrees, st)
+	}
+	sort.Strings(dp.Pinned)
+	sort.SliceStable(subtrees, func(i, j int) bool {
+		if subtrees[i].from.Bits() != subtrees[j].from.Bits() {
+			return subtrees[i].from.Bits() < subtrees[j].from.Bits()
+		}
+		return subtrees[i].from.IP().Less(subtrees[j].from.IP())
+	})
+
+	for _, st := range subtrees {
+		for _, route := range st.routes {
+			_, _, _ = rt.Delete(route)
+		}
+	}
+	placed := 0
+	for _, st := range subtrees {
+		p, ok := getLowestFreePrefix(rt, scope.IPPrefix(), st.from.Bits())
+		if !ok {
+			break
+		}
+		st.to = p
+		for _, route := range st.translate() {
+			_ = rt.Add(route)
+		}
+		placed++
+	}
+	dp.PlannedLargestFreePrefixLength, dp.PlannedFreeBlocks = getFreeSpace(rt, scope.IPPrefix())
+
+	if placed != len(subtrees) || !lessFragmented(
+		dp.PlannedLargestFreePrefixLength, dp.PlannedFreeBlocks,
+		dp.LargestFreePrefixLength, dp.FreeBlocks) {
+		// keep the allocations where they are
+		for _, st := range subtrees[:placed] {
+			for _, route := range st.translate() {
+				_, _, _ = rt.Delete(route)
+			}
+		}
+		for _, st := range subtrees {
+			st.to = st.from
+			for _, route := range st.routes {
+				_ = rt.Add(route)
+			}
+		}
+		dp.PlannedLargestFreePrefixLength, dp.PlannedFreeBlocks = dp.LargestFreePrefixLength, dp.FreeBlocks
+	}
+
+	movedSubtrees := []*defragSubtree{}
+	for _, st := range subtrees {
+		if st.from == st.to {
+			continue
+		}
+		movedSubtrees = append(movedSubtrees, st)
+		for _, route := range st.routes {
+			if !isDynamic(route) {
+				continue
+			}
+			dp.Moves = append(dp.Moves, DefragMove{
+				Namespace:  route.GetLabels().Get(ipamv1alpha1.NephioNamespaceKey),
+				Name:       route.GetLabels().Get(ipamv1alpha1.NephioIPAllocactionNameKey),
+				PrefixKind: route.GetLabels().Get(ipamv1alpha1.NephioPrefixKindKey),
+				OldPrefix:  route.IPPrefix().String(),
+				NewPrefix:  translatePrefix(route.IPPrefix(), st.from, st.to).String(),
+			})
+		}
+	}
+	return dp, movedSubtrees
+}
+
+// lessFragmented returns true if the free space a has a larger free block
+// than the free space b, or the same largest free block in less blocks
+func lessFragmented(aLargest uint8, aBlocks int, bLargest uint8, bBlocks int) bool {
+	if aBlocks == 0 || bBlocks == 0 {
+		return false
+	}
+	if aLargest != bLargest {
+		return aLargest < bLargest
+	}
+	return aBlocks < bBlocks
+}
+
+// getDefragSubtree returns the route with the routes nested in it; the route
+// can only be moved if it is dynamic and all nested routes are dynamic or
+// belong to the same allocation, like the endpoints of a link
+func getDefragSubtree(rt *table.RouteTable, route *table.Route) (*defragSubtree, bool) {
+	if !isDynamic(route) {
+		return nil, false
+	}
+	name := route.GetLabels().Get(ipamv1alpha1.NephioIPAllocactionNameKey)
+	st := &defragSubtree{
+		from:   route.IPPrefix(),
+		to:     route.IPPrefix(),
+		routes: table.Routes{route},
+	}
+	for _, child := range getChildren(rt, route.IPPrefix()) {
+		if !isDynamic(child) && child.GetLabels().Get(ipamv1alpha1.NephioIPAllocactionNameKey) != name {
+			return nil, false
+		}
+		st.routes = append(st.routes, child)
+	}
+	return st, true
+}
+
+// translate returns the routes of the subtree moved to the new prefix, the
+// network label follows the move when it points into the subtree
+func (st *defragSubtree) translate() table.Routes {
+	routes := make(table.Routes, 0, len(st.routes))
+	for _, route := range st.routes {
+		labels := map[string]string{}
+		for k, v := range *route.GetLabels() {
+			labels[k] = v
+		}
+		if ip, err := netaddr.ParseIP(labels[ipamv1alpha1.NephioNetworkKey]); err == nil && st.from.Contains(ip) {
+			labels[ipamv1alpha1.NephioNetworkKey] = translateIP(ip, st.from, st.to).String()
+		}
+		newRoute := table.NewRoute(translatePrefix(route.IPPrefix(), st.from, st.to))
+		newRoute.UpdateLabel(labels)
+		routes = append(routes, newRoute)
+	}
+	return routes
+}
+
+// getLowestFreePrefix returns the free prefix with the lowest address and the
+// requested prefix length in the prefix
+func getLowestFreePrefix(rt *table.RouteTable, p netaddr.IPPrefix, prefixLength uint8) (netaddr.IPPrefix, bool) {
+	blocks, _ := getFreePrefixes(rt, p)
+	for _, block := range blocks {
+		if block.Bits() <= prefixLength {
+			return netaddr.IPPrefixFrom(block.IP(), prefixLength), true
+		}
+	}
+	return netaddr.IPPrefix{}, false
+}
+
+// translatePrefix moves the prefix that is nested in from to the same offset in to
+func translatePrefix(p, from, to netaddr.IPPrefix) netaddr.IPPrefix {
+	return netaddr.IPPrefixFrom(translateIP(p.IP(), from, to), p.Bits())
+}
+
+// translateIP moves the address that is nested in from to the same offset in to
+func translateIP(ip netaddr.IP, from, to netaddr.IPPrefix) netaddr.IP {
+	b := ip.As16()
+	t := to.IP().As16()
+	bits := int(from.Bits())
+	if ip.Is4() {
+		bits += 96
+	}
+	for i := range b {
+		switch {
+		case bits >= 8:
+			b[i] = t[i]
+		case bits > 0:
+			mask := byte(0xff << (8 - bits))
+			b[i] = t[i]&mask | b[i]&^mask
+		}
+		bits -= 8
+	}
+	newIP := netaddr.IPFrom16(b)
+	if ip.Is4() {
+		return newIP.Unmap()
+	}
+	return newIP
+}
+
+// signalDefragMoves updates the status of the IPAllocations that were moved,
+// the owners of the allocations pick up the new prefix from the status. Moves
+// of allocations without an IPAllocation, e.g. allocated over grpc, are not
+// signalled, their clients learn the new prefix from the plan.
+func (r *ipam) signalDefragMoves(ctx context.Context, plan *DefragPlan) error {
+	if r.c == nil {
+		return nil
+	}
+	moves := map[types.NamespacedName]*DefragMove{}
+	for _, dp := range plan.Prefixes {
+		for i := range dp.Moves {
+			move := &dp.Moves[i]
+			moves[types.NamespacedName{Namespace: move.Namespace, Name: move.Name}] = move
+		}
+	}
+
+	allocList := &ipamv1alpha1.IPAllocationList{}
+	if err := r.c.List(ctx, allocList); err != nil {
+		return errors.Wrap(err, "cannot get ip allocation list")
+	}
+	for i := range allocList.Items {
+		cr := &allocList.Items[i]
+		if cr.Spec.Selector == nil || cr.Spec.Selector.MatchLabels[ipamv1alpha1.NephioNetworkInstanceKey] != plan.NetworkInstance {
+			continue
+		}
+		move, ok := moves[types.NamespacedName{Namespace: cr.GetNamespace(), Name: cr.GetName()}]
+		if !ok {
+			continue
+		}
+		from := netaddr.MustParseIPPrefix(move.OldPrefix)
+		to := netaddr.MustParseIPPrefix(move.NewPrefix)
+		// addresses are reported with the prefix length of their network
+		if p, err := netaddr.ParseIPPrefix(cr.Status.AllocatedPrefix); err == nil && from.Contains(p.IP()) {
+			np := translatePrefix(p, from, to)
+			cr.Status.AllocatedPrefix = np.String()
+			cr.Status.Address = np.IP().String()
+		}
+		for j, ep := range cr.Status.Endpoints {
+			if p, err := netaddr.ParseIPPrefix(ep.Address); err == nil && from.Contains(p.IP()) {
+				cr.Status.Endpoints[j].Address = translatePrefix(p, from, to).String()
+			}
+		}
+		cr.SetConditions(ipamv1alpha1.ReconcileSuccess(), ipamv1alpha1.Ready().WithMessage(
+			fmt.Sprintf("renumbered from %s to %s", move.OldPrefix, move.NewPrefix)))
+		if err := r.c.Status().Update(ctx, cr); err != nil {
+			return errors.Wrapf(err, "cannot update status of ip allocation %s", cr.GetName())
+		}
+		move.Signalled = true
+		r.l.Info("defrag", "signalled", cr.GetName(), "oldPrefix", move.OldPrefix, "newPrefix", move.NewPrefix)
+	}
+	for nsn, move := range moves {
+		if !move.Signalled {
+			r.l.Info("defrag", "not signalled", nsn.String(), "oldPrefix", move.OldPrefix, "newPrefix", move.NewPrefix)
+		}
+	}
+	return nil
+}
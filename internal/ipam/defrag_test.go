@@ -0,0 +1,223 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hansthienpondt/goipam/pkg/table"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"inet.af/netaddr"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// newTestPoolAllocation returns a pool allocation in the aggregates of vpc-1,
+// the pool is dynamic when the prefix is empty
+func newTestPoolAllocation(name, prefix string) *Allocation {
+	alloc := &Allocation{
+		NamespacedName:  types.NamespacedName{Namespace: "default", Name: name},
+		Origin:          ipamv1alpha1.OriginIPAllocation,
+		NetworkInstance: "vpc-1",
+		PrefixKind:      ipamv1alpha1.PrefixKindPool,
+		Prefix:          prefix,
+		PrefixLength:    27,
+	}
+	if prefix == "" {
+		alloc.SelectorLabels = map[string]string{ipamv1alpha1.NephioNetworkInstanceKey: "vpc-1"}
+	}
+	return alloc
+}
+
+func TestDefragPlan(t *testing.T) {
+	cases := map[string]struct {
+		// static pools have a prefix, dynamic pools are allocated in order
+		pools      [][2]string
+		released   []string
+		wantMoves  []string
+		wantPinned []string
+		wantFree   string
+	}{
+		"Fragmented": {
+			pools:    [][2]string{{"pool-a", ""}, {"pool-b", ""}, {"pool-c", ""}},
+			released: []string{"pool-a"},
+			wantMoves: []string{
+				"pool-b 10.0.0.32/27 -> 10.0.0.0/27",
+				"pool-c 10.0.0.64/27 -> 10.0.0.32/27",
+			},
+			wantFree: "/25 in 3 blocks -> /25 in 2 blocks",
+		},
+		"Compact": {
+			pools:    [][2]string{{"pool-a", ""}, {"pool-b", ""}, {"pool-c", ""}},
+			released: []string{"pool-c"},
+			wantFree: "/25 in 2 blocks -> /25 in 2 blocks",
+		},
+		"Pinned": {
+			pools:      [][2]string{{"pool-a", "10.0.0.0/27"}, {"pool-b", ""}, {"pool-c", ""}},
+			released:   []string{"pool-b"},
+			wantPinned: []string{"10.0.0.0/27"},
+			wantMoves: []string{
+				"pool-c 10.0.0.64/27 -> 10.0.0.32/27",
+			},
+			wantFree: "/25 in 3 blocks -> /25 in 2 blocks",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			r := newTestIpam(t, "vpc-1", "10.0.0.0/24")
+			for _, pool := range tc.pools {
+				if _, err := r.AllocateIPPrefix(ctx, newTestPoolAllocation(pool[0], pool[1])); err != nil {
+					t.Fatalf("cannot allocate %s: %v", pool[0], err)
+				}
+			}
+			for _, name := range tc.released {
+				if err := r.DeAllocateIPPrefix(ctx, newTestPoolAllocation(name, "")); err != nil {
+					t.Fatalf("cannot release %s: %v", name, err)
+				}
+			}
+			rt, _ := r.get("vpc-1")
+			before := rt.Size()
+
+			plan, err := r.Defrag(ctx, "vpc-1", "10.0.0.0/24", false)
+			if err != nil {
+				t.Fatalf("cannot defrag: %v", err)
+			}
+			if plan.Executed {
+				t.Errorf("got executed plan, want a dry run")
+			}
+			if len(plan.Prefixes) != 1 {
+				t.Fatalf("got %d prefixes, want 1", len(plan.Prefixes))
+			}
+			dp := plan.Prefixes[0]
+			gotMoves := []string{}
+			for _, m := range dp.Moves {
+				gotMoves = append(gotMoves, fmt.Sprintf("%s %s -> %s", m.Name, m.OldPrefix, m.NewPrefix))
+			}
+			if len(gotMoves) != 0 || len(tc.wantMoves) != 0 {
+				if !reflect.DeepEqual(gotMoves, tc.wantMoves) {
+					t.Errorf("got moves %v, want %v", gotMoves, tc.wantMoves)
+				}
+			}
+			if len(dp.Pinned) != 0 || len(tc.wantPinned) != 0 {
+				if !reflect.DeepEqual(dp.Pinned, tc.wantPinned) {
+					t.Errorf("got pinned %v, want %v", dp.Pinned, tc.wantPinned)
+				}
+			}
+			gotFree := fmt.Sprintf("/%d in %d blocks -> /%d in %d blocks",
+				dp.LargestFreePrefixLength, dp.FreeBlocks, dp.PlannedLargestFreePrefixLength, dp.PlannedFreeBlocks)
+			if gotFree != tc.wantFree {
+				t.Errorf("got free space %s, want %s", gotFree, tc.wantFree)
+			}
+
+			// a dry run leaves the routing table as it is
+			if rt.Size() != before {
+				t.Errorf("got %d routes after the dry run, want %d", rt.Size(), before)
+			}
+			for _, m := range dp.Moves {
+				if _, ok, _ := rt.Get(netaddr.MustParseIPPrefix(m.OldPrefix)); !ok {
+					t.Errorf("got %s moved by the dry run, want it in place", m.OldPrefix)
+				}
+			}
+		})
+	}
+}
+
+func TestMoveDefragSubtreesRollback(t *testing.T) {
+	rt := table.NewRouteTable()
+	for _, route := range newTestRoutes("10.0.0.0/24", "10.0.0.32/27", "10.0.0.32/28", "10.0.0.64/27", "10.0.0.128/27") {
+		if err := rt.Add(route); err != nil {
+			t.Fatalf("cannot add route %s: %v", route.IPPrefix().String(), err)
+		}
+	}
+	before := fmt.Sprint(rt.GetTable())
+
+	// the second subtree moves onto a prefix that is not moved away
+	subtrees := []*defragSubtree{
+		{
+			from:   netaddr.MustParseIPPrefix("10.0.0.32/27"),
+			to:     netaddr.MustParseIPPrefix("10.0.0.0/27"),
+			routes: newTestRoutes("10.0.0.32/27", "10.0.0.32/28"),
+		},
+		{
+			from:   netaddr.MustParseIPPrefix("10.0.0.64/27"),
+			to:     netaddr.MustParseIPPrefix("10.0.0.128/27"),
+			routes: newTestRoutes("10.0.0.64/27"),
+		},
+	}
+	if err := moveDefragSubtrees(rt, subtrees); err == nil {
+		t.Fatalf("got no error, want an error for a prefix that already exists")
+	}
+	if got := fmt.Sprint(rt.GetTable()); got != before {
+		t.Errorf("got routes %s after the rollback, want %s", got, before)
+	}
+}
+
+func TestGetDefragScopes(t *testing.T) {
+	imported := newTestRoute("10.1.0.0/16", ipamv1alpha1.PrefixKindAggregate)
+	imported.UpdateLabel(map[string]string{ipamv1alpha1.NephioSourceNIKey: "vpc-shared"})
+	rt, _ := newTestNestedRouteTable(t,
+		newTestRoute("10.0.0.0/16", ipamv1alpha1.PrefixKindAggregate),
+		newTestRoute("10.0.0.0/24", ipamv1alpha1.PrefixKindPool),
+		newTestRoute("10.0.1.0/24", ipamv1alpha1.PrefixKindNetwork),
+		imported,
+	)
+
+	cases := map[string]struct {
+		prefix  string
+		want    []string
+		wantErr error
+	}{
+		"All": {
+			want: []string{"10.0.0.0/16", "10.0.0.0/24"},
+		},
+		"Pool": {
+			prefix: "10.0.0.0/24",
+			want:   []string{"10.0.0.0/24"},
+		},
+		"Imported": {
+			prefix:  "10.1.0.0/16",
+			wantErr: ErrValidationFailed,
+		},
+		"NotFound": {
+			prefix:  "10.2.0.0/16",
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			scopes, err := getDefragScopes(rt, tc.prefix)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tc.wantErr)
+			}
+			got := []string{}
+			for _, route := range scopes {
+				got = append(got, route.IPPrefix().String())
+			}
+			if len(got) != 0 || len(tc.want) != 0 {
+				if !reflect.DeepEqual(got, tc.want) {
+					t.Errorf("got scopes %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"context"
+	"sort"
+
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// GetDependents returns the prefixes and allocations nested in the prefix of
+// the allocation, which are left behind when the allocation is released
+func (r *ipam) GetDependents(ctx context.Context, alloc *Allocation) ([]ipamv1alpha1.PrefixDependent, error) {
+	r.l = log.FromContext(ctx)
+
+	rt, err := r.getRoutingTable(alloc, false)
+	if err != nil {
+		return nil, err
+	}
+	// a network prefix only releases the network with the latest prefix of the network
+	if !r.IsLatestPrefixInNetwork(alloc) {
+		return nil, nil
+	}
+
+	dependents := map[string]ipamv1alpha1.PrefixDependent{}
+	bits := map[string]uint8{}
+	for _, route := range getChildren(rt, alloc.GetIPPrefix().Masked()) {
+		l := route.GetLabels()
+		name := l.Get(ipamv1alpha1.NephioIPAllocactionNameKey)
+		if name == "" || name == alloc.GetName() ||
+			l.Get(ipamv1alpha1.NephioOriginKey) == string(ipamv1alpha1.OriginIPSystem) {
+			continue
+		}
+		niName := l.Get(ipamv1alpha1.NephioNetworkInstanceKey)
+		if source := l.Get(ipamv1alpha1.NephioSourceNIKey); source != "" {
+			niName = source
+		}
+		d := ipamv1alpha1.PrefixDependent{
+			Name:            name,
+			Namespace:       l.Get(ipamv1alpha1.NephioNamespaceKey),
+			Prefix:          route.IPPrefix().String(),
+			PrefixKind:      l.Get(ipamv1alpha1.NephioPrefixKindKey),
+			NetworkInstance: niName,
+		}
+		// an allocation with multiple routes, e.g. a link, is reported with its
+		// shortest prefix
+		key := d.Namespace + "/" + d.Name
+		if b, ok := bits[key]; !ok || route.IPPrefix().Bits() < b {
+			dependents[key] = d
+			bits[key] = route.IPPrefix().Bits()
+		}
+	}
+
+	result := make([]ipamv1alpha1.PrefixDependent, 0, len(dependents))
+	for _, d := range dependents {
+		result = append(result, d)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Prefix != result[j].Prefix {
+			return result[i].Prefix < result[j].Prefix
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
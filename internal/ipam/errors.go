@@ -0,0 +1,81 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"fmt"
+
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrValidationFailed is returned when an allocation is not valid
+	ErrValidationFailed = errors.New("validated failed")
+	// ErrPoolExhausted is returned when no prefix is free for a dynamic allocation
+	ErrPoolExhausted = errors.New("no free prefix found")
+	// ErrQuotaExceeded is returned when an allocation exceeds a quota
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrOverlap is returned when an allocation overlaps with a prefix it
+	// cannot coexist with
+	ErrOverlap = errors.New("prefix overlaps")
+	// ErrInvalidNesting is returned when an allocation is not nested in or
+	// does not nest the prefixes the nesting rules require
+	ErrInvalidNesting = errors.New("invalid nesting")
+	// ErrNetworkInstanceNotReady is returned when the network instance of an
+	// allocation is not initialized in the ipam
+	ErrNetworkInstanceNotReady = errors.New("network instance not ready")
+	// ErrNotFound is returned when an allocation or the prefix it is allocated
+	// from does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when an allocation conflicts with an existing
+	// allocation of the same prefix
+	ErrConflict = errors.New("conflict")
+)
+
+// newError returns an error of the supplied kind with the message, or nil
+// when the message is empty
+func newError(kind error, msg string) error {
+	if msg == "" {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", kind, msg)
+}
+
+// GetConditionReason returns the reason of the ready condition of a resource
+// whose allocation failed with the error
+func GetConditionReason(err error) ipamv1alpha1.ConditionReason {
+	switch {
+	case errors.Is(err, ErrQuotaExceeded):
+		return ipamv1alpha1.ConditionReasonQuotaExceeded
+	case errors.Is(err, ErrPoolExhausted):
+		return ipamv1alpha1.ConditionReasonPoolExhausted
+	case errors.Is(err, ErrOverlap):
+		return ipamv1alpha1.ConditionReasonOverlap
+	case errors.Is(err, ErrInvalidNesting):
+		return ipamv1alpha1.ConditionReasonInvalidNesting
+	case errors.Is(err, ErrNetworkInstanceNotReady):
+		return ipamv1alpha1.ConditionReasonNetworkInstanceNotReady
+	case errors.Is(err, ErrNotFound):
+		return ipamv1alpha1.ConditionReasonNotFound
+	case errors.Is(err, ErrConflict):
+		return ipamv1alpha1.ConditionReasonConflict
+	case errors.Is(err, ErrValidationFailed):
+		return ipamv1alpha1.ConditionReasonValidationFailed
+	}
+	return ipamv1alpha1.ConditionReasonFailed
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hansthienpondt/goipam/pkg/table"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatJSON ExportFormat = "json"
+	ExportFormatYAML ExportFormat = "yaml"
+	ExportFormatDOT  ExportFormat = "dot"
+)
+
+// PrefixNode is a prefix of a network instance with the prefixes nested in it
+type PrefixNode struct {
+	Prefix   string            `json:"prefix"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Children []*PrefixNode     `json:"children,omitempty"`
+}
+
+// Export renders the prefix hierarchy of the network instance in the
+// requested format
+func (r *ipam) Export(niName string, format ExportFormat) ([]byte, error) {
+	rt, ok := r.get(niName)
+	if !ok {
+		return nil, newError(ErrNetworkInstanceNotReady, fmt.Sprintf("network-instance %s", niName))
+	}
+	tree := getPrefixTree(rt)
+
+	switch format {
+	case ExportFormatJSON, "":
+		return json.MarshalIndent(tree, "", "  ")
+	case ExportFormatYAML:
+		return yaml.Marshal(tree)
+	case ExportFormatDOT:
+		return exportDOT(niName, tree), nil
+	default:
+		return nil, newError(ErrValidationFailed, fmt.Sprintf("unsupported export format %s, supported: %s, %s, %s",
+			format, ExportFormatJSON, ExportFormatYAML, ExportFormatDOT))
+	}
+}
+
+// getPrefixTree nests every route of the routing table under its most
+// specific parent route
+func getPrefixTree(rt *table.RouteTable) []*PrefixNode {
+	routes := rt.GetTable()
+	// parents always have a shorter prefix length than their children
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].IPPrefix().Bits() != routes[j].IPPrefix().Bits() {
+			return routes[i].IPPrefix().Bits() < routes[j].IPPrefix().Bits()
+		}
+		return routes[i].IPPrefix().IP().Less(routes[j].IPPrefix().IP())
+	})
+
+	nodes := make(map[string]*PrefixNode, len(routes))
+	roots := []*PrefixNode{}
+	for _, route := range routes {
+		node := &PrefixNode{
+			Prefix: route.String(),
+			Labels: *route.GetLabels(),
+		}
+		nodes[route.String()] = node
+
+		var parent *table.Route
+		for _, p := range rt.Parents(route.IPPrefix()) {
+			if parent == nil || p.IPPrefix().Bits() > parent.IPPrefix().Bits() {
+				parent = p
+			}
+		}
+		if parent == nil {
+			roots = append(roots, node)
+			continue
+		}
+		parentNode, ok := nodes[parent.String()]
+		if !ok {
+			roots = append(roots, node)
+			continue
+		}
+		parentNode.Children = append(parentNode.Children, node)
+	}
+	return roots
+}
+
+func exportDOT(niName string, tree []*PrefixNode) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "digraph %q {\n", niName)
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	var walk func(nodes []*PrefixNode, parent *PrefixNode)
+	walk = func(nodes []*PrefixNode, parent *PrefixNode) {
+		for _, n := range nodes {
+			label := []string{n.Prefix}
+			if kind, ok := n.Labels[ipamv1alpha1.NephioPrefixKindKey]; ok {
+				label = append(label, kind)
+			}
+			if name, ok := n.Labels[ipamv1alpha1.NephioIPAllocactionNameKey]; ok {
+				label = append(label, name)
+			}
+			fmt.Fprintf(&b, "  %q [label=%q];\n", n.Prefix, strings.Join(label, "\n"))
+			if parent != nil {
+				fmt.Fprintf(&b, "  %q -> %q;\n", parent.Prefix, n.Prefix)
+			}
+			walk(n.Children, n)
+		}
+	}
+	walk(tree, nil)
+	b.WriteString("}\n")
+	return b.Bytes()
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/hansthienpondt/goipam/pkg/table"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"inet.af/netaddr"
+)
+
+var allocatableBlocks = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "ipam_prefix_allocatable_blocks",
+	Help: "Number of blocks of the prefix length that can be allocated from an aggregate or pool prefix, from the largest free block up to the smallest one; absent when the prefix is full",
+}, []string{"network_instance", "prefix", "kind", "prefix_length"})
+
+// RegisterMetrics registers the metrics of the ipam with the registry
+func RegisterMetrics(registry prometheus.Registerer) {
+	registry.MustRegister(allocatableBlocks)
+}
+
+// freeSpace is the free space of an aggregate or pool prefix
+type freeSpace struct {
+	prefix netaddr.IPPrefix
+	kind   string
+	// blocks are the free blocks of the prefix ordered by address
+	blocks []netaddr.IPPrefix
+}
+
+// getFreeSpaces returns the free space of the aggregate and pool prefixes of
+// the routing table. The routes are sorted once, such that the routes nested
+// in a prefix follow it and the free space of all prefixes is found in a
+// single pass rather than a scan of the routing table per prefix.
+func getFreeSpaces(rt *table.RouteTable) []freeSpace {
+	routes := rt.GetTable()
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].IPPrefix().IP() != routes[j].IPPrefix().IP() {
+			return routes[i].IPPrefix().IP().Less(routes[j].IPPrefix().IP())
+		}
+		return routes[i].IPPrefix().Bits() < routes[j].IPPrefix().Bits()
+	})
+
+	spaces := []freeSpace{}
+	for i, route := range routes {
+		kind := route.GetLabels().Get(ipamv1alpha1.NephioPrefixKindKey)
+		if kind != string(ipamv1alpha1.PrefixKindAggregate) && kind != string(ipamv1alpha1.PrefixKindPool) {
+			continue
+		}
+		p := route.IPPrefix()
+		var b netaddr.IPSetBuilder
+		b.AddPrefix(p)
+		for _, child := range routes[i+1:] {
+			if !p.Contains(child.IPPrefix().IP()) {
+				break
+			}
+			b.RemovePrefix(child.IPPrefix())
+		}
+		s, err := b.IPSet()
+		if err != nil {
+			continue
+		}
+		spaces = append(spaces, freeSpace{prefix: p, kind: kind, blocks: s.Prefixes()})
+	}
+	return spaces
+}
+
+// getFragmentation returns the free space of the aggregate and pool prefixes
+// as reported in the status of the network instance
+func getFragmentation(spaces []freeSpace) []ipamv1alpha1.PrefixFragmentation {
+	fragmentation := make([]ipamv1alpha1.PrefixFragmentation, 0, len(spaces))
+	for _, space := range spaces {
+		f := ipamv1alpha1.PrefixFragmentation{
+			Prefix:     space.prefix.String(),
+			PrefixKind: space.kind,
+		}
+		f.LargestFreePrefixLength, f.FreeBlocks = getLargestFreePrefixLength(space.blocks), len(space.blocks)
+		fragmentation = append(fragmentation, f)
+	}
+	return fragmentation
+}
+
+// getFreeSpace returns the prefix length of the largest free block and the
+// number of free blocks of the prefix
+func getFreeSpace(rt *table.RouteTable, p netaddr.IPPrefix) (uint8, int) {
+	blocks, _ := getFreePrefixes(rt, p)
+	return getLargestFreePrefixLength(blocks), len(blocks)
+}
+
+// getLargestFreePrefixLength returns the prefix length of the largest block,
+// zero when there are no blocks
+func getLargestFreePrefixLength(blocks []netaddr.IPPrefix) uint8 {
+	var largest uint8
+	for _, block := range blocks {
+		if largest == 0 || block.Bits() < largest {
+			largest = block.Bits()
+		}
+	}
+	return largest
+}
+
+// getAllocatableBlocks returns the number of blocks per prefix length that can
+// be allocated from the free blocks, from the prefix length of the largest
+// free block up to the prefix length of the smallest one
+func getAllocatableBlocks(blocks []netaddr.IPPrefix) map[uint8]float64 {
+	allocatable := map[uint8]float64{}
+	var smallest uint8
+	for _, block := range blocks {
+		if block.Bits() > smallest {
+			smallest = block.Bits()
+		}
+	}
+	for prefixLength := getLargestFreePrefixLength(blocks); prefixLength > 0 && prefixLength <= smallest; prefixLength++ {
+		for _, block := range blocks {
+			if block.Bits() <= prefixLength {
+				allocatable[prefixLength] += math.Ldexp(1, int(prefixLength-block.Bits()))
+			}
+		}
+	}
+	return allocatable
+}
+
+// setFragmentationMetrics replaces the fragmentation metrics of the network instance
+func (r *ipam) setFragmentationMetrics(niName string, spaces []freeSpace) {
+	r.deleteFragmentationMetrics(niName)
+
+	r.mmetrics.Lock()
+	defer r.mmetrics.Unlock()
+	for _, space := range spaces {
+		for prefixLength, n := range getAllocatableBlocks(space.blocks) {
+			l := prometheus.Labels{
+				"network_instance": niName,
+				"prefix":           space.prefix.String(),
+				"kind":             space.kind,
+				"prefix_length":    strconv.Itoa(int(prefixLength)),
+			}
+			allocatableBlocks.With(l).Set(n)
+			r.metrics[niName] = append(r.metrics[niName], l)
+		}
+	}
+}
+
+func (r *ipam) deleteFragmentationMetrics(niName string) {
+	r.mmetrics.Lock()
+	defer r.mmetrics.Unlock()
+	for _, l := range r.metrics[niName] {
+		allocatableBlocks.Delete(l)
+	}
+	delete(r.metrics, niName)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"reflect"
+	"testing"
+
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"inet.af/netaddr"
+)
+
+func TestGetFreeSpaces(t *testing.T) {
+	rt, _ := newTestNestedRouteTable(t,
+		newTestRoute("10.0.0.0/16", ipamv1alpha1.PrefixKindAggregate),
+		newTestRoute("10.0.0.0/24", ipamv1alpha1.PrefixKindPool),
+		newTestRoute("10.0.0.0/26", ipamv1alpha1.PrefixKindNetwork),
+		newTestRoute("10.0.0.128/28", ipamv1alpha1.PrefixKindNetwork),
+		newTestRoute("10.0.1.0/24", ipamv1alpha1.PrefixKindPool),
+		newTestRoute("10.1.0.0/24", ipamv1alpha1.PrefixKindNetwork),
+	)
+
+	got := map[string][]string{}
+	for _, space := range getFreeSpaces(rt) {
+		blocks := []string{}
+		for _, block := range space.blocks {
+			blocks = append(blocks, block.String())
+		}
+		got[space.prefix.String()] = blocks
+	}
+	want := map[string][]string{
+		"10.0.0.0/16": {"10.0.2.0/23", "10.0.4.0/22", "10.0.8.0/21", "10.0.16.0/20", "10.0.32.0/19", "10.0.64.0/18", "10.0.128.0/17"},
+		"10.0.0.0/24": {"10.0.0.64/26", "10.0.0.144/28", "10.0.0.160/27", "10.0.0.192/26"},
+		"10.0.1.0/24": {"10.0.1.0/24"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got free blocks %v, want %v", got, want)
+	}
+}
+
+func TestGetAllocatableBlocks(t *testing.T) {
+	cases := map[string]struct {
+		blocks []string
+		want   map[uint8]float64
+	}{
+		"Full": {
+			want: map[uint8]float64{},
+		},
+		"Single": {
+			blocks: []string{"10.0.0.0/24"},
+			want:   map[uint8]float64{24: 1},
+		},
+		"Fragmented": {
+			blocks: []string{"10.0.0.64/26", "10.0.0.144/28", "10.0.0.160/27", "10.0.0.192/26"},
+			want:   map[uint8]float64{26: 2, 27: 5, 28: 11},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			blocks := []netaddr.IPPrefix{}
+			for _, b := range tc.blocks {
+				blocks = append(blocks, netaddr.MustParseIPPrefix(b))
+			}
+			if got := getAllocatableBlocks(blocks); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
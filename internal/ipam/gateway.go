@@ -0,0 +1,135 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hansthienpondt/goipam/pkg/table"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/internal/utils/iputil"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// gatewayRoles are the roles of a gateway, in order of preference
+var gatewayRoles = []ipamv1alpha1.GatewayRole{
+	ipamv1alpha1.GatewayRoleVirtual,
+	ipamv1alpha1.GatewayRolePrimary,
+	ipamv1alpha1.GatewayRoleSecondary,
+}
+
+// getGatewayRole returns the role of a gateway, a gateway without a role is
+// the primary gateway
+func getGatewayRole(l labels.Labels) ipamv1alpha1.GatewayRole {
+	if role := l.Get(ipamv1alpha1.NephioGatewayRoleKey); role != "" {
+		return ipamv1alpha1.GatewayRole(role)
+	}
+	return ipamv1alpha1.GatewayRolePrimary
+}
+
+func gatewayRolePreference(role ipamv1alpha1.GatewayRole) int {
+	for i, r := range gatewayRoles {
+		if r == role {
+			return i
+		}
+	}
+	return len(gatewayRoles)
+}
+
+// setGateways sets the gateways of the address family of the allocated prefix;
+// the gateway of the allocated prefix is the most preferred one
+func setGateways(allocatedPrefix *AllocatedPrefix, routes table.Routes, af ipamv1alpha1.AddressFamily) {
+	gateways := []ipamv1alpha1.Gateway{}
+	for _, route := range routes {
+		if iputil.GetAddressFamily(route.IPPrefix()) != af {
+			continue
+		}
+		gateways = append(gateways, ipamv1alpha1.Gateway{
+			Address: route.IPPrefix().IP().String(),
+			Role:    getGatewayRole(route.GetLabels()),
+		})
+	}
+	sort.SliceStable(gateways, func(i, j int) bool {
+		if gatewayRolePreference(gateways[i].Role) != gatewayRolePreference(gateways[j].Role) {
+			return gatewayRolePreference(gateways[i].Role) < gatewayRolePreference(gateways[j].Role)
+		}
+		return gateways[i].Address < gateways[j].Address
+	})
+	if len(gateways) > 0 {
+		allocatedPrefix.Gateway = gateways[0].Address
+		allocatedPrefix.Gateways = gateways
+	}
+}
+
+type GatewayValidationFn func(alloc *Allocation, dryrunrt *table.RouteTable) string
+
+func GatewayValidationNopFn(alloc *Allocation, dryrunrt *table.RouteTable) string { return "" }
+
+// GatewayValidationNetworkFn validates the role of a gateway, a network has at
+// most one gateway per role and address family
+func GatewayValidationNetworkFn(alloc *Allocation, dryrunrt *table.RouteTable) string {
+	l := labels.Set(alloc.GetLabels())
+	if l.Get(ipamv1alpha1.NephioGatewayKey) != "true" {
+		if l.Get(ipamv1alpha1.NephioGatewayRoleKey) != "" {
+			return fmt.Sprintf("a gateway role requires the %s label", ipamv1alpha1.NephioGatewayKey)
+		}
+		return ""
+	}
+	role := getGatewayRole(l)
+	if gatewayRolePreference(role) == len(gatewayRoles) {
+		return fmt.Sprintf("unknown gateway role %s, supported roles are %v", role, gatewayRoles)
+	}
+
+	selector, err := getNetworkGatewaySelector(alloc)
+	if err != nil {
+		return err.Error()
+	}
+	af := iputil.GetAddressFamily(alloc.GetIPPrefix())
+	for _, route := range dryrunrt.GetByLabel(selector) {
+		if route.GetLabels().Get(ipamv1alpha1.NephioIPAllocactionNameKey) == alloc.GetName() ||
+			iputil.GetAddressFamily(route.IPPrefix()) != af {
+			continue
+		}
+		if getGatewayRole(route.GetLabels()) == role {
+			return fmt.Sprintf("network %s already has a %s gateway for %s, gateway %s",
+				alloc.GetNetwork(),
+				role,
+				af,
+				route.GetLabels().Get(ipamv1alpha1.NephioIPAllocactionNameKey))
+		}
+	}
+	return ""
+}
+
+func getNetworkGatewaySelector(alloc *Allocation) (labels.Selector, error) {
+	l := map[string]string{
+		ipamv1alpha1.NephioGatewayKey:         "true",
+		ipamv1alpha1.NephioNetworkNameKey:     alloc.GetNetwork(),
+		ipamv1alpha1.NephioNetworkInstanceKey: alloc.GetNetworkInstance(),
+	}
+	fullselector := labels.NewSelector()
+	for k, v := range l {
+		req, err := labels.NewRequirement(k, selection.In, []string{v})
+		if err != nil {
+			return nil, err
+		}
+		fullselector = fullselector.Add(*req)
+	}
+	return fullselector, nil
+}
@@ -19,6 +19,8 @@ package ipam
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/hansthienpondt/goipam/pkg/table"
@@ -80,6 +82,7 @@ func (r *ipam) GenericPrefixInsertor(ctx context.Context, alloc *Allocation) (*A
 
 	return &AllocatedPrefix{
 		AllocatedPrefix: alloc.GetPrefixFromNewAlloc(),
+		ParentPrefix:    getParentPrefix(rt, route),
 	}, nil
 }
 
@@ -106,7 +109,14 @@ func (r *ipam) GenericPrefixAllocator(ctx context.Context, alloc *Allocation) (*
 	if len(routes) != 0 {
 		// there should only be 1 route with this name in the route table
 		route := routes[0]
-		route.UpdateLabel(alloc.GetFullLabels())
+		labels := alloc.GetFullLabels()
+		// label the prefix as dynamic as well when it was allocated before
+		// dynamic prefixes were labeled, such that it can be renumbered; an
+		// allocation with a prefix is pinned to it
+		if alloc.Prefix == "" {
+			labels[ipamv1alpha1.NephioDynamicKey] = "true"
+		}
+		route.UpdateLabel(labels)
 		// update the route with the latest labels
 		if err := rt.Update(route); err != nil {
 			if !strings.Contains(err.Error(), "already exists") {
@@ -116,6 +126,7 @@ func (r *ipam) GenericPrefixAllocator(ctx context.Context, alloc *Allocation) (*
 		p := route.IPPrefix()
 		allocatedPrefix := &AllocatedPrefix{
 			AllocatedPrefix: p.String(),
+			ParentPrefix:    getParentPrefix(rt, route),
 		}
 
 		if alloc.PrefixKind == ipamv1alpha1.PrefixKindNetwork {
@@ -125,10 +136,8 @@ func (r *ipam) GenericPrefixAllocator(ctx context.Context, alloc *Allocation) (*
 			}
 			r.l.Info("gateway", "gatewaySelector", gatewaySelector)
 			routes = rt.GetByLabel(gatewaySelector)
-			if len(routes) > 0 {
-				r.l.Info("gateway", "routes", routes)
-				allocatedPrefix.Gateway = strings.Split(routes[0].IPPrefix().String(), "/")[0]
-			}
+			r.l.Info("gateway", "routes", routes)
+			setGateways(allocatedPrefix, routes, iputil.GetAddressFamily(p))
 		}
 		r.l.Info("allocatedPrefix", "allocatedPrefix", allocatedPrefix)
 		return allocatedPrefix, nil
@@ -140,19 +149,31 @@ func (r *ipam) GenericPrefixAllocator(ctx context.Context, alloc *Allocation) (*
 		return nil, err
 	}
 
-	routes = rt.GetByLabel(labelSelector)
+	routes = r.getAllowedRoutes(alloc.GetNetworkInstance(), rt.GetByLabel(labelSelector))
+	if len(routes) == 0 {
+		return nil, newError(ErrNotFound, fmt.Sprintf("no available routes based on the label selector: %v", labelSelector))
+	}
+	// only routes of the requested address family can be selected, without
+	// one the address family of the first route is used
+	af := alloc.GetAddressFamily()
+	if af != ipamv1alpha1.AddressFamilyIpv4 && af != ipamv1alpha1.AddressFamilyIpv6 {
+		af = iputil.GetAddressFamily(routes[0].IPPrefix())
+	}
+	routes = getAddressFamilyRoutes(routes, af)
 	if len(routes) == 0 {
-		return nil, fmt.Errorf("no available routes based on the label selector: %v", labelSelector)
+		return nil, newError(ErrNotFound, fmt.Sprintf("no available %s routes based on the label selector: %v", af, labelSelector))
 	}
 
 	prefixLength := alloc.GetPrefixLengthFromRoute(routes[0])
-	selectedRoute := r.GetSelectedRouteWithPrefixLength(routes, prefixLength)
-	if selectedRoute == nil {
-		return nil, fmt.Errorf("no route found with requested prefixLength: %d", prefixLength)
+	if alloc.PrefixLength == 0 {
+		// the network instance defines the prefix length when the allocation has none
+		if l := r.getDefaultPrefixLength(alloc.GetNetworkInstance(), alloc.PrefixKind, af); l != 0 {
+			prefixLength = l
+		}
 	}
-	p, ok := rt.FindFreePrefix(selectedRoute.IPPrefix(), prefixLength)
-	if !ok {
-		return nil, errors.New("no free prefix found")
+	selectedRoute, p, err := r.findFreePrefix(rt, routes, prefixLength, r.getAllocationStrategy(alloc.GetNetworkInstance()))
+	if err != nil {
+		return nil, err
 	}
 	route := table.NewRoute(p)
 	prefix := p.String()
@@ -167,6 +188,8 @@ func (r *ipam) GenericPrefixAllocator(ctx context.Context, alloc *Allocation) (*
 	labels[ipamv1alpha1.NephioAddressFamilyKey] = string(iputil.GetAddressFamily(selectedRoute.IPPrefix()))
 	labels[ipamv1alpha1.NephioPrefixLengthKey] = string(iputil.GetAddressPrefixLength(p))
 	labels[ipamv1alpha1.NephioNetworkKey] = selectedRoute.IPPrefix().Masked().IP().String()
+	// the prefix is not pinned by the allocation, which allows to renumber it
+	labels[ipamv1alpha1.NephioDynamicKey] = "true"
 	route.UpdateLabel(labels)
 
 	if err := rt.Update(route); err != nil {
@@ -177,6 +200,7 @@ func (r *ipam) GenericPrefixAllocator(ctx context.Context, alloc *Allocation) (*
 
 	allocatedPrefix := &AllocatedPrefix{
 		AllocatedPrefix: prefix,
+		ParentPrefix:    selectedRoute.IPPrefix().String(),
 	}
 
 	if alloc.PrefixKind == ipamv1alpha1.PrefixKindNetwork {
@@ -186,30 +210,125 @@ func (r *ipam) GenericPrefixAllocator(ctx context.Context, alloc *Allocation) (*
 		}
 		r.l.Info("gateway", "gatewaySelector", gatewaySelector)
 		routes = rt.GetByLabel(gatewaySelector)
-		if len(routes) > 0 {
-			r.l.Info("gateway", "routes", routes)
-			allocatedPrefix.Gateway = strings.Split(routes[0].IPPrefix().String(), "/")[0]
-		}
+		r.l.Info("gateway", "routes", routes)
+		setGateways(allocatedPrefix, routes, iputil.GetAddressFamily(p))
 	}
 
 	r.l.Info("allocatedPrefix", "allocatedPrefix", allocatedPrefix)
 	return allocatedPrefix, nil
 }
 
-func (r *ipam) GetSelectedRouteWithPrefixLength(routes table.Routes, prefixLength uint8) *table.Route {
-	r.l.Info("alloc w/o prefix", "routes", routes)
+// findFreePrefix returns a free prefix in the first candidate route that has
+// one, such that an allocation spills over to the next route when a route is full
+func (r *ipam) findFreePrefix(rt *table.RouteTable, routes table.Routes, prefixLength uint8, strategy ipamv1alpha1.AllocationStrategy) (*table.Route, netaddr.IPPrefix, error) {
+	candidates := r.getCandidateRoutes(routes, prefixLength)
+	if len(candidates) == 0 {
+		return nil, netaddr.IPPrefix{}, newError(ErrPoolExhausted, fmt.Sprintf("no route found with requested prefixLength: %d", prefixLength))
+	}
+	if strategy == ipamv1alpha1.AllocationStrategyBestFit {
+		if route, p, ok := r.findBestFitPrefix(rt, candidates, prefixLength); ok {
+			return route, p, nil
+		}
+		return nil, netaddr.IPPrefix{}, newPoolExhaustedError(candidates, prefixLength)
+	}
+	for _, route := range candidates {
+		if p, ok := getFreePrefix(rt, route.IPPrefix(), prefixLength); ok {
+			return route, p, nil
+		}
+		r.l.Info("no free prefix, spill over to the next route", "route", route.String())
+	}
+	return nil, netaddr.IPPrefix{}, newPoolExhaustedError(candidates, prefixLength)
+}
+
+// newPoolExhaustedError returns the error of candidate routes without a free
+// prefix of the prefix length
+func newPoolExhaustedError(candidates table.Routes, prefixLength uint8) error {
+	prefixes := make([]string, 0, len(candidates))
+	for _, route := range candidates {
+		prefixes = append(prefixes, route.IPPrefix().String())
+	}
+	return newError(ErrPoolExhausted, fmt.Sprintf("no free prefix with requested prefixLength: %d in routes: %s",
+		prefixLength, strings.Join(prefixes, ", ")))
+}
 
+// findBestFitPrefix returns a prefix from the smallest free block that fits
+// across the candidate routes; routes of a lower priority are only used when
+// the routes of a higher priority have no free block that fits
+func (r *ipam) findBestFitPrefix(rt *table.RouteTable, candidates table.Routes, prefixLength uint8) (*table.Route, netaddr.IPPrefix, bool) {
 	var selectedRoute *table.Route
-	if prefixLength == 32 || prefixLength == 128 {
-		selectedRoute = routes[0]
-	} else {
-		for _, route := range routes {
-			ps, _ := netaddr.MustParseIPPrefix(route.String()).IPNet().Mask.Size()
-			if ps < int(prefixLength) {
+	var selectedBlock netaddr.IPPrefix
+	for _, route := range candidates {
+		if selectedRoute != nil && r.getPriority(route) < r.getPriority(selectedRoute) {
+			break
+		}
+		blocks, ok := getFreePrefixes(rt, route.IPPrefix())
+		if !ok {
+			continue
+		}
+		for _, block := range blocks {
+			if block.Bits() > prefixLength {
+				continue
+			}
+			if selectedRoute == nil || block.Bits() > selectedBlock.Bits() {
 				selectedRoute = route
-				break
+				selectedBlock = block
 			}
 		}
 	}
-	return selectedRoute
+	if selectedRoute == nil {
+		return nil, netaddr.IPPrefix{}, false
+	}
+	return selectedRoute, netaddr.IPPrefixFrom(selectedBlock.IP(), prefixLength), true
+}
+
+// getCandidateRoutes returns the routes that can hold a prefix of the prefix
+// length, ordered by the priority label, highest first; routes without a
+// priority have priority 0. The routes are of the address family of the
+// allocation.
+func (r *ipam) getCandidateRoutes(routes table.Routes, prefixLength uint8) table.Routes {
+	r.l.Info("alloc w/o prefix", "routes", routes)
+
+	candidates := make(table.Routes, 0, len(routes))
+	for _, route := range routes {
+		if route.IPPrefix().Bits() < prefixLength {
+			candidates = append(candidates, route)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return r.getPriority(candidates[i]) > r.getPriority(candidates[j])
+	})
+	return candidates
+}
+
+// getAddressFamilyRoutes returns the routes of the address family
+func getAddressFamilyRoutes(routes table.Routes, af ipamv1alpha1.AddressFamily) table.Routes {
+	afRoutes := make(table.Routes, 0, len(routes))
+	for _, route := range routes {
+		if iputil.GetAddressFamily(route.IPPrefix()) == af {
+			afRoutes = append(afRoutes, route)
+		}
+	}
+	return afRoutes
+}
+
+func (r *ipam) getPriority(route *table.Route) int {
+	priority, err := strconv.Atoi(route.GetLabels().Get(r.priorityKey))
+	if err != nil {
+		return 0
+	}
+	return priority
+}
+
+// getParentPrefix returns the closest parent of the route
+func getParentPrefix(rt *table.RouteTable, route *table.Route) string {
+	var parent *table.Route
+	for _, route := range route.GetParents(rt) {
+		if parent == nil || route.IPPrefix().Bits() > parent.IPPrefix().Bits() {
+			parent = route
+		}
+	}
+	if parent == nil {
+		return ""
+	}
+	return parent.IPPrefix().String()
 }
@@ -0,0 +1,205 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hansthienpondt/goipam/pkg/table"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"inet.af/netaddr"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestGenericPrefixAllocatorDynamicLabel(t *testing.T) {
+	cases := map[string]struct {
+		prefix string
+		want   string
+	}{
+		"Dynamic": {
+			want: "true",
+		},
+		"Static": {
+			prefix: "10.0.1.0/24",
+			want:   "",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := newTestIpam(t, "vpc-1", "10.0.0.0/16")
+
+			// a prefix allocated before dynamic prefixes were labeled
+			rt, _ := r.get("vpc-1")
+			route := table.NewRoute(netaddr.MustParseIPPrefix("10.0.1.0/24"))
+			route.UpdateLabel(map[string]string{
+				ipamv1alpha1.NephioIPAllocactionNameKey: "pool-1",
+				ipamv1alpha1.NephioPrefixKindKey:        string(ipamv1alpha1.PrefixKindPool),
+			})
+			if err := rt.Add(route); err != nil {
+				t.Fatalf("cannot add route: %v", err)
+			}
+
+			// the allocator is called directly, a custom prefix kind can use
+			// it for allocations with a prefix as well
+			ap, err := r.GenericPrefixAllocator(context.Background(), &Allocation{
+				NamespacedName:  types.NamespacedName{Namespace: "default", Name: "pool-1"},
+				Origin:          ipamv1alpha1.OriginIPAllocation,
+				NetworkInstance: "vpc-1",
+				PrefixKind:      ipamv1alpha1.PrefixKindPool,
+				Prefix:          tc.prefix,
+				PrefixLength:    24,
+				SelectorLabels:  map[string]string{ipamv1alpha1.NephioNetworkInstanceKey: "vpc-1"},
+			})
+			if err != nil {
+				t.Fatalf("cannot allocate: %v", err)
+			}
+			if ap.AllocatedPrefix != "10.0.1.0/24" {
+				t.Errorf("got prefix %s, want %s", ap.AllocatedPrefix, "10.0.1.0/24")
+			}
+			route, _, _ = rt.Get(netaddr.MustParseIPPrefix("10.0.1.0/24"))
+			if got := route.GetLabels().Get(ipamv1alpha1.NephioDynamicKey); got != tc.want {
+				t.Errorf("got label %s=%q, want %q", ipamv1alpha1.NephioDynamicKey, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGenericPrefixAllocatorAddressFamily(t *testing.T) {
+	cases := map[string]struct {
+		af         ipamv1alpha1.AddressFamily
+		wantPrefix string
+	}{
+		"Ipv4": {
+			af:         ipamv1alpha1.AddressFamilyIpv4,
+			wantPrefix: "10.0.0.0/24",
+		},
+		"Ipv6": {
+			af:         ipamv1alpha1.AddressFamilyIpv6,
+			wantPrefix: "2001:db8::/64",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := newTestIpam(t, "vpc-1", "10.0.0.0/16", "2001:db8::/48")
+			prefixLength := uint8(24)
+			if tc.af == ipamv1alpha1.AddressFamilyIpv6 {
+				prefixLength = 64
+			}
+			ap, err := r.AllocateIPPrefix(context.Background(), &Allocation{
+				NamespacedName:  types.NamespacedName{Namespace: "default", Name: "pool-1"},
+				Origin:          ipamv1alpha1.OriginIPAllocation,
+				NetworkInstance: "vpc-1",
+				PrefixKind:      ipamv1alpha1.PrefixKindPool,
+				AddresFamily:    tc.af,
+				PrefixLength:    prefixLength,
+				SelectorLabels:  map[string]string{ipamv1alpha1.NephioNetworkInstanceKey: "vpc-1"},
+			})
+			if err != nil {
+				t.Fatalf("cannot allocate: %v", err)
+			}
+			if ap.AllocatedPrefix != tc.wantPrefix {
+				t.Errorf("got prefix %s, want %s", ap.AllocatedPrefix, tc.wantPrefix)
+			}
+		})
+	}
+}
+
+func TestFindFreePrefix(t *testing.T) {
+	cases := map[string]struct {
+		strategy   ipamv1alpha1.AllocationStrategy
+		priorities map[string]string
+		children   []string
+		wantPrefix string
+		wantErr    error
+	}{
+		"FirstFit": {
+			strategy:   ipamv1alpha1.AllocationStrategyFirstFit,
+			children:   []string{"10.0.0.0/25"},
+			wantPrefix: "10.0.0.128/26",
+		},
+		"FirstFitSpillOver": {
+			strategy:   ipamv1alpha1.AllocationStrategyFirstFit,
+			children:   []string{"10.0.0.0/25", "10.0.0.128/25"},
+			wantPrefix: "10.0.1.0/26",
+		},
+		"BestFit": {
+			strategy:   ipamv1alpha1.AllocationStrategyBestFit,
+			children:   []string{"10.0.0.0/25", "10.0.1.0/25", "10.0.1.128/26"},
+			wantPrefix: "10.0.1.192/26",
+		},
+		"BestFitPriority": {
+			strategy:   ipamv1alpha1.AllocationStrategyBestFit,
+			priorities: map[string]string{"10.0.0.0/24": "10"},
+			children:   []string{"10.0.0.0/25", "10.0.1.0/25", "10.0.1.128/26"},
+			wantPrefix: "10.0.0.128/26",
+		},
+		"BestFitSpillOver": {
+			strategy:   ipamv1alpha1.AllocationStrategyBestFit,
+			priorities: map[string]string{"10.0.0.0/24": "10"},
+			children:   []string{"10.0.0.0/25", "10.0.0.128/25", "10.0.1.0/25"},
+			wantPrefix: "10.0.1.128/26",
+		},
+		"FirstFitExhausted": {
+			strategy: ipamv1alpha1.AllocationStrategyFirstFit,
+			children: []string{"10.0.0.0/25", "10.0.0.128/25", "10.0.1.0/25", "10.0.1.128/25"},
+			wantErr:  ErrPoolExhausted,
+		},
+		"BestFitExhausted": {
+			strategy: ipamv1alpha1.AllocationStrategyBestFit,
+			children: []string{"10.0.0.0/25", "10.0.0.128/25", "10.0.1.0/25", "10.0.1.128/25"},
+			wantErr:  ErrPoolExhausted,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := newTestIpam(t, "vpc-1")
+			parents := []string{"10.0.0.0/24", "10.0.1.0/24"}
+			rt := newTestRouteTable(t, append(append([]string{}, parents...), tc.children...)...)
+			routes := table.Routes{}
+			for _, p := range parents {
+				route, _, _ := rt.Get(netaddr.MustParseIPPrefix(p))
+				if priority, ok := tc.priorities[p]; ok {
+					route.UpdateLabel(map[string]string{r.priorityKey: priority})
+				}
+				routes = append(routes, route)
+			}
+
+			_, p, err := r.findFreePrefix(rt, routes, 26, tc.strategy)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				// the error tells the requested prefix length and the routes
+				for _, want := range append([]string{"26"}, parents...) {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("got error %q, want it to contain %q", err, want)
+					}
+				}
+				return
+			}
+			if p.String() != tc.wantPrefix {
+				t.Errorf("got prefix %s, want %s", p, tc.wantPrefix)
+			}
+		})
+	}
+}
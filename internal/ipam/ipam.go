@@ -21,12 +21,14 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/hansthienpondt/goipam/pkg/table"
 	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
 	"github.com/nokia/k8s-ipam/internal/utils/iputil"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	"inet.af/netaddr"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
@@ -35,7 +37,7 @@ import (
 )
 
 // Option can be used to manipulate Options.
-type Option func(Ipam)
+type Option func(*ipam)
 
 type Ipam interface {
 	// Init
@@ -46,51 +48,73 @@ type Ipam interface {
 	AllocateIPPrefix(ctx context.Context, alloc *Allocation) (*AllocatedPrefix, error)
 	// DeAllocateIPPrefix
 	DeAllocateIPPrefix(ctx context.Context, alloc *Allocation) error
+	// ReleaseExpired releases the allocations whose lease expired
+	ReleaseExpired(ctx context.Context) error
+	// GetAllocatedPrefix returns the prefix allocated for the allocation
+	GetAllocatedPrefix(ctx context.Context, alloc *Allocation) (*AllocatedPrefix, error)
+	// GetRoutes returns the routes of a network instance matching the selector
+	GetRoutes(niName string, selector labels.Selector) (table.Routes, error)
+	// Export renders the prefix hierarchy of a network instance
+	Export(niName string, format ExportFormat) ([]byte, error)
+	// Defrag plans and optionally executes the compaction of the allocations of a network instance
+	Defrag(ctx context.Context, niName, prefix string, execute bool) (*DefragPlan, error)
+	// SetNestingPolicy applies the nesting rules on top of the default nesting rules
+	SetNestingPolicy(rules []ipamv1alpha1.NestingRule) error
+	// SetImports applies the prefixes a network instance exports and imports
+	SetImports(ctx context.Context, cr *ipamv1alpha1.NetworkInstance) error
+	// SetQuota applies the quota to the allocations of its network instance
+	SetQuota(cr *ipamv1alpha1.IPQuota) error
+	// DeleteQuota removes the quota
+	DeleteQuota(name types.NamespacedName)
+	// GetQuotaUsage returns the number of allocations per tenant of the quota
+	GetQuotaUsage(cr *ipamv1alpha1.IPQuota) ([]ipamv1alpha1.QuotaUsage, error)
+	// GetDependents returns the prefixes and allocations nested in the prefix of the allocation
+	GetDependents(ctx context.Context, alloc *Allocation) ([]ipamv1alpha1.PrefixDependent, error)
+	// RevalidateDependents validates the dependents of a prefix again after the prefix changed
+	RevalidateDependents(ctx context.Context, alloc *Allocation, dependents []ipamv1alpha1.PrefixDependent, migrate bool) ([]DependentValidation, error)
 }
 
+// New returns an ipam; when the client is nil the ipam runs offline, which
+// allows to validate prefixes without a cluster
 func New(c client.Client, opts ...Option) Ipam {
 	i := &ipam{
-		c:    c,
-		ipam: make(map[string]*table.RouteTable),
+		c:           c,
+		ipam:        make(map[string]*table.RouteTable),
+		priorityKey: ipamv1alpha1.NephioPriorityKey,
+		strategy:    ipamv1alpha1.AllocationStrategyFirstFit,
+		metrics:     map[string][]prometheus.Labels{},
+		exports:     map[string][]labels.Selector{},
+		imports:     map[string][]prefixImport{},
+		specs:       map[string]*ipamv1alpha1.NetworkInstanceSpec{},
+		quotas:      map[string]*quota{},
+		invalid:     map[string]map[string]string{},
 	}
 
 	i.validator = map[ipamUsage]*ValidationConfig{
 		// Allocation has a prefix
 		{PrefixKind: ipamv1alpha1.PrefixKindNetwork, HasPrefix: true}: {
-			ValidateInputFn:    ValidateInputNetworkWithPrefixFn,
-			IsAddressFn:        IsAddressGenericFn,
-			IsAddressInNetFn:   IsAddressInNetNopFn,
-			ExactPrefixMatchFn: ExactPrefixMatchNetworkFn,
-			ChildrenExistFn:    ChildrenExistGenericFn,
-			ParentExistFn:      ParentExistNetworkFn,
-			FinalValidationFn:  FinalValidationNetworkFn,
+			ValidateInputFn:     ValidateInputNetworkWithPrefixFn,
+			GatewayValidationFn: GatewayValidationNetworkFn,
+			ExactPrefixMatchFn:  ExactPrefixMatchNetworkFn,
+			FinalValidationFn:   FinalValidationNetworkFn,
 		},
 		{PrefixKind: ipamv1alpha1.PrefixKindLoopback, HasPrefix: true}: {
-			ValidateInputFn:    ValidateInputNopFn,
-			IsAddressFn:        IsAddressNopFn,
-			IsAddressInNetFn:   IsAddressInNetGenericFn,
-			ExactPrefixMatchFn: ExactPrefixMatchGenericFn,
-			ChildrenExistFn:    ChildrenExistLoopbackFn,
-			ParentExistFn:      ParentExistLoopbackFn,
-			FinalValidationFn:  FinalValidationNopFn,
+			ValidateInputFn:     ValidateInputNopFn,
+			GatewayValidationFn: GatewayValidationNopFn,
+			ExactPrefixMatchFn:  ExactPrefixMatchGenericFn,
+			FinalValidationFn:   FinalValidationNopFn,
 		},
 		{PrefixKind: ipamv1alpha1.PrefixKindPool, HasPrefix: true}: {
-			ValidateInputFn:    ValidateInputNopFn,
-			IsAddressFn:        IsAddressGenericFn,
-			IsAddressInNetFn:   IsAddressInNetGenericFn,
-			ExactPrefixMatchFn: ExactPrefixMatchGenericFn,
-			ChildrenExistFn:    ChildrenExistGenericFn,
-			ParentExistFn:      ParentExistPoolFn,
-			FinalValidationFn:  FinalValidationNopFn,
+			ValidateInputFn:     ValidateInputNopFn,
+			GatewayValidationFn: GatewayValidationNopFn,
+			ExactPrefixMatchFn:  ExactPrefixMatchGenericFn,
+			FinalValidationFn:   FinalValidationNopFn,
 		},
 		{PrefixKind: ipamv1alpha1.PrefixKindAggregate, HasPrefix: true}: {
-			ValidateInputFn:    ValidateInputNopFn,
-			IsAddressFn:        IsAddressGenericFn,
-			IsAddressInNetFn:   IsAddressInNetGenericFn,
-			ExactPrefixMatchFn: ExactPrefixMatchGenericFn,
-			ChildrenExistFn:    ChildrenExistNopFn,
-			ParentExistFn:      ParentExistAggregateFn,
-			FinalValidationFn:  FinalValidationNopFn,
+			ValidateInputFn:     ValidateInputNopFn,
+			GatewayValidationFn: GatewayValidationNopFn,
+			ExactPrefixMatchFn:  ExactPrefixMatchGenericFn,
+			FinalValidationFn:   FinalValidationNopFn,
 		},
 		// Allocation has no prefix
 		{PrefixKind: ipamv1alpha1.PrefixKindNetwork, HasPrefix: false}: {
@@ -111,15 +135,15 @@ func New(c client.Client, opts ...Option) Ipam {
 	i.mutator = map[ipamUsage]MutatorFn{
 		// Allocation has a prefix
 		{PrefixKind: ipamv1alpha1.PrefixKindNetwork, HasPrefix: true}:   i.networkMutator,
-		{PrefixKind: ipamv1alpha1.PrefixKindLoopback, HasPrefix: true}:  i.genericMutatorWithPrefix,
-		{PrefixKind: ipamv1alpha1.PrefixKindPool, HasPrefix: true}:      i.genericMutatorWithPrefix,
-		{PrefixKind: ipamv1alpha1.PrefixKindAggregate, HasPrefix: true}: i.genericMutatorWithPrefix,
+		{PrefixKind: ipamv1alpha1.PrefixKindLoopback, HasPrefix: true}:  i.GenericMutatorWithPrefix,
+		{PrefixKind: ipamv1alpha1.PrefixKindPool, HasPrefix: true}:      i.GenericMutatorWithPrefix,
+		{PrefixKind: ipamv1alpha1.PrefixKindAggregate, HasPrefix: true}: i.GenericMutatorWithPrefix,
 		// Allocation has no prefix
-		{PrefixKind: ipamv1alpha1.PrefixKindNetwork, HasPrefix: false}:  i.genericMutatorWithoutPrefix,
-		{PrefixKind: ipamv1alpha1.PrefixKindLoopback, HasPrefix: false}: i.genericMutatorWithoutPrefix,
-		{PrefixKind: ipamv1alpha1.PrefixKindPool, HasPrefix: false}:     i.genericMutatorWithoutPrefix,
+		{PrefixKind: ipamv1alpha1.PrefixKindNetwork, HasPrefix: false}:  i.GenericMutatorWithoutPrefix,
+		{PrefixKind: ipamv1alpha1.PrefixKindLoopback, HasPrefix: false}: i.GenericMutatorWithoutPrefix,
+		{PrefixKind: ipamv1alpha1.PrefixKindPool, HasPrefix: false}:     i.GenericMutatorWithoutPrefix,
 		// aggregate prefixes should always have a prefix
-		{PrefixKind: ipamv1alpha1.PrefixKindAggregate, HasPrefix: false}: i.nopMutator,
+		{PrefixKind: ipamv1alpha1.PrefixKindAggregate, HasPrefix: false}: i.NopMutator,
 	}
 
 	i.insertor = map[ipamUsage]InsertorFn{
@@ -136,10 +160,20 @@ func New(c client.Client, opts ...Option) Ipam {
 		{PrefixKind: ipamv1alpha1.PrefixKindAggregate, HasPrefix: false}: i.NopPrefixAllocator,
 	}
 
+	i.rules = make([]ipamv1alpha1.NestingRule, 0, len(DefaultNestingRules))
+	for _, rule := range DefaultNestingRules {
+		i.rules = append(i.rules, *rule.DeepCopy())
+	}
+	i.registerPrefixKind(ipamv1alpha1.PrefixKindLink, i.linkPrefixKindConfig())
+
 	for _, opt := range opts {
 		opt(i)
 	}
 
+	// the address and nesting validation of prefixes follows the nesting policy,
+	// the default nesting rules are always valid
+	_ = i.SetNestingPolicy(nil)
+
 	return i
 }
 
@@ -147,6 +181,9 @@ type ipam struct {
 	c    client.Client
 	m    sync.Mutex
 	ipam map[string]*table.RouteTable
+	// am serializes the validation, quota check and insertion of allocations
+	// with every other change of the routing tables; lm is taken before am
+	am sync.Mutex
 
 	vm        sync.RWMutex
 	validator map[ipamUsage]*ValidationConfig
@@ -154,6 +191,29 @@ type ipam struct {
 	mutator   map[ipamUsage]MutatorFn
 	im        sync.RWMutex
 	insertor  map[ipamUsage]InsertorFn
+	// rules are the default nesting rules, including the registered prefix kinds
+	rules []ipamv1alpha1.NestingRule
+	// priorityKey is the label that orders the routes a prefix is allocated from
+	priorityKey string
+	// strategy selects the free block a dynamic allocation is allocated from
+	strategy ipamv1alpha1.AllocationStrategy
+	// metrics are the label sets of the fragmentation metrics per network instance
+	mmetrics sync.Mutex
+	metrics  map[string][]prometheus.Labels
+	// exports and imports are the prefixes shared between network instances
+	lm      sync.Mutex
+	exports map[string][]labels.Selector
+	imports map[string][]prefixImport
+	// specs are the configuration of the network instances
+	sm    sync.RWMutex
+	specs map[string]*ipamv1alpha1.NetworkInstanceSpec
+	// quotas cap the allocations per tenant
+	qm     sync.RWMutex
+	quotas map[string]*quota
+	// invalid are the allocations per network instance that are no longer
+	// valid since a prefix they depend on changed
+	invm    sync.RWMutex
+	invalid map[string]map[string]string
 
 	l logr.Logger
 }
@@ -170,6 +230,11 @@ func (r *ipam) Init(ctx context.Context, cr *ipamv1alpha1.NetworkInstance) error
 		r.ipam[cr.GetName()] = table.NewRouteTable()
 		r.m.Unlock()
 
+		// without a client the ipam runs offline and there is nothing to restore
+		if r.c == nil {
+			return r.setSpec(cr)
+		}
+
 		prefixList := &ipamv1alpha1.IPPrefixList{}
 		if err := r.c.List(context.Background(), prefixList); err != nil {
 			return errors.Wrap(err, "cannot get ip prefix list")
@@ -181,6 +246,10 @@ func (r *ipam) Init(ctx context.Context, cr *ipamv1alpha1.NetworkInstance) error
 			return errors.Wrap(err, "cannot get ip allocation list")
 		}
 		for prefix, labels := range cr.Status.Allocations {
+			// routes of other network instances are restored when the imports are applied
+			if labels.Get(ipamv1alpha1.NephioSourceNIKey) != "" {
+				continue
+			}
 			switch labels.Get(ipamv1alpha1.NephioOriginKey) {
 			case string(ipamv1alpha1.OriginIPPrefix):
 				for _, ipprefix := range prefixList.Items {
@@ -199,6 +268,10 @@ func (r *ipam) Init(ctx context.Context, cr *ipamv1alpha1.NetworkInstance) error
 							PrefixKind: ipamv1alpha1.PrefixKind(ipprefix.Spec.PrefixKind),
 							HasPrefix:  true}]
 						r.mm.Unlock()
+						if mutatorFn == nil {
+							r.l.Info("ipam action", "action", "initialize", "prefix", "unknown prefix kind", "kind", ipprefix.Spec.PrefixKind)
+							continue
+						}
 						allocs := mutatorFn(BuildAllocationFromIPPrefix(&ipprefix))
 
 						for _, alloc := range allocs {
@@ -224,6 +297,10 @@ func (r *ipam) Init(ctx context.Context, cr *ipamv1alpha1.NetworkInstance) error
 							PrefixKind: ipamv1alpha1.PrefixKind(ipalloc.Spec.PrefixKind),
 							HasPrefix:  ipalloc.Spec.Prefix != ""}]
 						r.mm.Unlock()
+						if mutatorFn == nil {
+							r.l.Info("ipam action", "action", "initialize", "alloc", "unknown prefix kind", "kind", ipalloc.Spec.PrefixKind)
+							continue
+						}
 						allocs := mutatorFn(BuildAllocationFromIPAllocation(&ipalloc))
 
 						for _, alloc := range allocs {
@@ -243,16 +320,45 @@ func (r *ipam) Init(ctx context.Context, cr *ipamv1alpha1.NetworkInstance) error
 
 	}
 
+	// the configuration applies once the allocations are restored, which are
+	// kept even when they exceed the limits of the network instance
+	return r.setSpec(cr)
+}
+
+// SetNestingPolicy applies the nesting rules on top of the default nesting rules
+func (r *ipam) SetNestingPolicy(rules []ipamv1alpha1.NestingRule) error {
+	p, err := newNestingPolicy(r.rules, rules)
+	if err != nil {
+		return err
+	}
+	r.vm.Lock()
+	defer r.vm.Unlock()
+	for usage, fnc := range r.validator {
+		if usage.HasPrefix {
+			r.validator[usage] = p.validationConfig(usage.PrefixKind, fnc)
+		}
+	}
 	return nil
 }
 
 // Delete the ipam instance
 func (r *ipam) Delete(crName string) {
 	r.m.Lock()
-	defer r.m.Unlock()
 	r.l = log.FromContext(context.Background())
 	r.l.Info("ipam action", "action", "delete", "name", crName)
 	delete(r.ipam, crName)
+	r.deleteFragmentationMetrics(crName)
+	r.m.Unlock()
+	r.deleteSpec(crName)
+	r.invm.Lock()
+	delete(r.invalid, crName)
+	r.invm.Unlock()
+
+	// withdraw the prefixes shared with other network instances
+	r.deleteImports(crName)
+	if _, err := r.syncImports(context.Background()); err != nil {
+		r.l.Error(err, "cannot sync imports", "name", crName)
+	}
 }
 
 // AllocateIPPrefix allocates the prefix
@@ -260,17 +366,58 @@ func (r *ipam) AllocateIPPrefix(ctx context.Context, alloc *Allocation) (*Alloca
 	r.l = log.FromContext(ctx)
 	r.l.Info("allocate prefix ", "alloc", alloc)
 
+	leaseExpiry := setLease(alloc, time.Now())
+
 	// copy original allocation
 	origAlloc := new(Allocation)
 	*origAlloc = *alloc
 
-	// validate alloc
-	msg, err := r.validate(ctx, alloc)
+	// the allocation is checked and inserted under one lock, such that
+	// concurrent allocations cannot both take the last allocation of a quota
+	r.am.Lock()
+	allocatedPrefix, err := r.insertAllocation(ctx, alloc, origAlloc)
+	r.am.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.updateNetworkInstanceStatus(ctx, origAlloc); err != nil {
+		return nil, err
+	}
+	result, err := r.syncImportsIfNeeded(ctx)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := r.getImportCollision(origAlloc, result)
 	if err != nil {
 		return nil, err
 	}
 	if msg != "" {
-		return nil, fmt.Errorf("validated failed: %s", msg)
+		// another network instance allocated the same prefix in an imported
+		// prefix concurrently; the allocation is released such that it can
+		// be retried
+		dealloc := new(Allocation)
+		*dealloc = *origAlloc
+		if err := r.DeAllocateIPPrefix(ctx, dealloc); err != nil {
+			return nil, err
+		}
+		return nil, newError(ErrConflict, msg)
+	}
+	allocatedPrefix.LeaseExpiry = leaseExpiry
+	return allocatedPrefix, nil
+}
+
+// insertAllocation validates the allocation, checks the quotas and inserts
+// the allocation in the routing table
+func (r *ipam) insertAllocation(ctx context.Context, alloc, origAlloc *Allocation) (*AllocatedPrefix, error) {
+	// validate alloc
+	if err := r.validate(ctx, alloc); err != nil {
+		return nil, err
+	}
+	if err := r.checkQuotas(origAlloc); err != nil {
+		return nil, err
+	}
+	if msg := r.getInvalid(origAlloc); msg != "" {
+		return nil, newError(ErrInvalidNesting, msg)
 	}
 
 	// mutate alloc from Allocation to []IpamAllocation
@@ -288,7 +435,7 @@ func (r *ipam) AllocateIPPrefix(ctx context.Context, alloc *Allocation) (*Alloca
 			allocatedPrefix = ap
 		}
 	}
-	return allocatedPrefix, r.updateNetworkInstanceStatus(ctx, origAlloc)
+	return allocatedPrefix, nil
 }
 
 func (r *ipam) DeAllocateIPPrefix(ctx context.Context, alloc *Allocation) error {
@@ -307,7 +454,31 @@ func (r *ipam) DeAllocateIPPrefix(ctx context.Context, alloc *Allocation) error
 		PrefixKind: alloc.PrefixKind,
 		HasPrefix:  alloc.Prefix != ""}]
 	r.mm.Unlock()
-	allocs := mutatorFn(alloc)
+	if mutatorFn == nil {
+		return newError(ErrValidationFailed, fmt.Sprintf("unknown prefix kind %s", alloc.PrefixKind))
+	}
+	// the routes are deleted under the allocation lock, such that an
+	// allocation does not see the allocation partially deleted
+	r.am.Lock()
+	err = r.deleteAllocation(rt, alloc, mutatorFn(alloc))
+	r.am.Unlock()
+	if err != nil {
+		return err
+	}
+
+	r.deleteInvalid(origAlloc)
+
+	if err := r.updateNetworkInstanceStatus(ctx, origAlloc); err != nil {
+		return err
+	}
+	_, err = r.syncImportsIfNeeded(ctx)
+	return err
+}
+
+// deleteAllocation deletes the routes of the allocations the allocation was
+// mutated in; the net and first routes of a network are only deleted with
+// the last prefix of the network
+func (r *ipam) deleteAllocation(rt *table.RouteTable, alloc *Allocation, allocs []*Allocation) error {
 	if !r.IsLatestPrefixInNetwork(alloc) {
 		r.l.Info("deallocate prefix ", "latest", "false")
 		allocs = allocs[:1]
@@ -332,8 +503,7 @@ func (r *ipam) DeAllocateIPPrefix(ctx context.Context, alloc *Allocation) error
 			}
 		}
 	}
-
-	return r.updateNetworkInstanceStatus(ctx, origAlloc)
+	return nil
 }
 
 func (r *ipam) get(crName string) (*table.RouteTable, bool) {
@@ -346,7 +516,7 @@ func (r *ipam) get(crName string) (*table.RouteTable, bool) {
 func (r *ipam) getRoutingTable(alloc *Allocation, dryrun bool) (*table.RouteTable, error) {
 	rt, ok := r.get(alloc.GetNetworkInstance())
 	if !ok {
-		return nil, fmt.Errorf("ipam ni not ready or network-instance %s not correct", alloc.GetName())
+		return nil, newError(ErrNetworkInstanceNotReady, fmt.Sprintf("network-instance %s", alloc.GetNetworkInstance()))
 	}
 	if dryrun {
 		// copy the routing table for validation
@@ -365,6 +535,9 @@ func copyRoutingTable(rt *table.RouteTable) *table.RouteTable {
 }
 
 func (r *ipam) updateNetworkInstanceStatus(ctx context.Context, alloc *Allocation) error {
+	if r.c == nil {
+		return nil
+	}
 	rt, err := r.getRoutingTable(alloc, false)
 	if err != nil {
 		return err
@@ -382,6 +555,10 @@ func (r *ipam) updateNetworkInstanceStatus(ctx context.Context, alloc *Allocatio
 		//r.l.Info("updateNetworkInstanceStatus", "route", route.String())
 		ni.Status.Allocations[route.String()] = *route.GetLabels()
 	}
+	spaces := getFreeSpaces(rt)
+	ni.Status.Fragmentation = getFragmentation(spaces)
+	ni.Status.ImportedPrefixes = getImportedPrefixes(rt)
+	r.setFragmentationMetrics(alloc.GetNetworkInstance(), spaces)
 	return errors.Wrap(r.c.Status().Update(ctx, ni), "cannot update ni status")
 }
 
@@ -0,0 +1,51 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"context"
+	"testing"
+
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// newTestIpam returns an ipam without a client with a network instance that
+// has an aggregate per prefix
+func newTestIpam(t *testing.T, niName string, prefixes ...string) *ipam {
+	t.Helper()
+	ctx := context.Background()
+	r := New(nil).(*ipam)
+	if err := r.Init(ctx, &ipamv1alpha1.NetworkInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: niName, Namespace: "default"},
+	}); err != nil {
+		t.Fatalf("cannot init %s: %v", niName, err)
+	}
+	for _, p := range prefixes {
+		if _, err := r.AllocateIPPrefix(ctx, &Allocation{
+			NamespacedName:  types.NamespacedName{Namespace: "default", Name: "aggregate-" + p},
+			Origin:          ipamv1alpha1.OriginIPPrefix,
+			NetworkInstance: niName,
+			PrefixKind:      ipamv1alpha1.PrefixKindAggregate,
+			Prefix:          p,
+		}); err != nil {
+			t.Fatalf("cannot allocate aggregate %s: %v", p, err)
+		}
+	}
+	return r
+}
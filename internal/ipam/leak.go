@@ -0,0 +1,407 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hansthienpondt/goipam/pkg/table"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"inet.af/netaddr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// prefixImport imports the exported prefixes of a network instance that
+// match the selector
+type prefixImport struct {
+	networkInstance string
+	selector        labels.Selector
+}
+
+// importResult is the outcome of syncing the imports
+type importResult struct {
+	// conflicts are the imports that cannot be applied per importing network
+	// instance
+	conflicts map[string][]string
+	// collisions are the prefixes per network instance that another network
+	// instance sharing the imported prefix allocated as well, e.g. when both
+	// allocated the prefix concurrently, with the other network instance
+	collisions map[string]map[netaddr.IPPrefix]string
+}
+
+// importedRoot is a prefix of the owning network instance that is imported
+type importedRoot struct {
+	owner  string
+	prefix netaddr.IPPrefix
+}
+
+// SetImports applies the prefixes the network instance exports and imports.
+// The imported prefixes, with the routes nested in them, are mirrored in the
+// routing table of the importing network instance and the allocations made
+// in the imported prefixes are reflected in the routing table of the network
+// instance that owns them, such that the owning network instance remains the
+// single source of truth.
+func (r *ipam) SetImports(ctx context.Context, cr *ipamv1alpha1.NetworkInstance) error {
+	exports := make([]labels.Selector, 0, len(cr.Spec.Exports))
+	for _, e := range cr.Spec.Exports {
+		e := e
+		selector, err := metav1.LabelSelectorAsSelector(&e)
+		if err != nil {
+			return err
+		}
+		exports = append(exports, selector)
+	}
+	imports := make([]prefixImport, 0, len(cr.Spec.Imports))
+	for _, i := range cr.Spec.Imports {
+		if i.NetworkInstance == cr.GetName() {
+			return fmt.Errorf("network instance %s cannot import from itself", cr.GetName())
+		}
+		selector := labels.Everything()
+		if i.Selector != nil {
+			var err error
+			if selector, err = metav1.LabelSelectorAsSelector(i.Selector); err != nil {
+				return err
+			}
+		}
+		imports = append(imports, prefixImport{networkInstance: i.NetworkInstance, selector: selector})
+	}
+
+	r.lm.Lock()
+	r.exports[cr.GetName()] = exports
+	r.imports[cr.GetName()] = imports
+	r.lm.Unlock()
+
+	result, err := r.syncImports(ctx)
+	if err != nil {
+		return err
+	}
+	if msgs := result.conflicts[cr.GetName()]; len(msgs) > 0 {
+		return fmt.Errorf("cannot import: %s", strings.Join(msgs, ", "))
+	}
+	return nil
+}
+
+// deleteImports removes the exports and imports of the network instance
+func (r *ipam) deleteImports(niName string) {
+	r.lm.Lock()
+	delete(r.exports, niName)
+	delete(r.imports, niName)
+	r.lm.Unlock()
+}
+
+// syncImportsIfNeeded syncs the imported prefixes when a network instance
+// imports prefixes
+func (r *ipam) syncImportsIfNeeded(ctx context.Context) (*importResult, error) {
+	r.lm.Lock()
+	n := len(r.imports)
+	r.lm.Unlock()
+	if n == 0 {
+		return &importResult{}, nil
+	}
+	return r.syncImports(ctx)
+}
+
+// syncImports mirrors the imported prefixes and reflects the allocations made
+// in them, the status of the network instances whose routing table changed is
+// updated. It returns the imports that conflict and the prefixes that collide.
+func (r *ipam) syncImports(ctx context.Context) (*importResult, error) {
+	r.lm.Lock()
+	defer r.lm.Unlock()
+
+	r.m.Lock()
+	rts := make(map[string]*table.RouteTable, len(r.ipam))
+	for niName, rt := range r.ipam {
+		rts[niName] = rt
+	}
+	r.m.Unlock()
+
+	// the routing tables of all network instances are read and changed under
+	// the allocation lock, such that no allocation changes them in between
+	r.am.Lock()
+	result, changed := r.syncImportedRoutes(rts)
+	r.am.Unlock()
+
+	for _, niName := range changed {
+		r.l.Info("imports", "action", "sync", "networkInstance", niName)
+		if err := r.updateNetworkInstanceStatus(ctx, &Allocation{NetworkInstance: niName}); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// syncImportedRoutes updates the routing tables with the routes they import
+// from other network instances, it returns the network instances whose
+// routing table changed
+func (r *ipam) syncImportedRoutes(rts map[string]*table.RouteTable) (*importResult, []string) {
+	own := make(map[string]table.Routes, len(rts))
+	for niName, rt := range rts {
+		for _, route := range rt.GetTable() {
+			if route.GetLabels().Get(ipamv1alpha1.NephioSourceNIKey) == "" {
+				own[niName] = append(own[niName], route)
+			}
+		}
+	}
+
+	// select the imported prefixes per importing network instance
+	importers := map[importedRoot][]string{}
+	result := &importResult{
+		conflicts:  map[string][]string{},
+		collisions: map[string]map[netaddr.IPPrefix]string{},
+	}
+	for _, niName := range sortedKeys(r.imports) {
+		if _, ok := rts[niName]; !ok {
+			continue
+		}
+		accepted := []importedRoot{}
+		for _, i := range r.imports[niName] {
+			for _, p := range r.getExportedPrefixes(own[i.networkInstance], i) {
+				if msg := getImportConflict(own[niName], accepted, i.networkInstance, p); msg != "" {
+					result.conflicts[niName] = append(result.conflicts[niName], msg)
+					continue
+				}
+				root := importedRoot{owner: i.networkInstance, prefix: p}
+				accepted = append(accepted, root)
+				importers[root] = append(importers[root], niName)
+			}
+		}
+	}
+
+	// build the routes every network instance needs from other network instances
+	desired := map[string]map[netaddr.IPPrefix]labels.Set{}
+	add := func(niName string, route *table.Route, source string, root bool) {
+		if desired[niName] == nil {
+			desired[niName] = map[netaddr.IPPrefix]labels.Set{}
+		}
+		if _, ok := desired[niName][route.IPPrefix()]; ok {
+			return
+		}
+		l := labels.Set{}
+		for k, v := range *route.GetLabels() {
+			l[k] = v
+		}
+		l[ipamv1alpha1.NephioSourceNIKey] = source
+		if root {
+			// the importing network instance allocates from the imported prefix
+			l[ipamv1alpha1.NephioNetworkInstanceKey] = niName
+			l[ipamv1alpha1.NephioImportedFromKey] = source
+		}
+		desired[niName][route.IPPrefix()] = l
+	}
+	for root, niNames := range importers {
+		for _, niName := range niNames {
+			for _, route := range getNestedRoutes(own[root.owner], root.prefix, true) {
+				add(niName, route, root.owner, route.IPPrefix() == root.prefix)
+			}
+			for _, other := range niNames {
+				if other == niName {
+					continue
+				}
+				for _, route := range getNestedRoutes(own[other], root.prefix, false) {
+					add(niName, route, other, false)
+				}
+			}
+			for _, route := range getNestedRoutes(own[niName], root.prefix, false) {
+				add(root.owner, route, niName, false)
+			}
+		}
+	}
+
+	// update the routing tables with the desired routes of other network instances
+	changed := []string{}
+	for _, niName := range sortedKeys(rts) {
+		ok, collisions := applyForeignRoutes(rts[niName], desired[niName])
+		if len(collisions) > 0 {
+			result.collisions[niName] = collisions
+		}
+		if ok {
+			changed = append(changed, niName)
+		}
+	}
+	return result, changed
+}
+
+// getImportCollision returns why the routes of the allocation collide with
+// the routes another network instance sharing the imported prefix allocated
+func (r *ipam) getImportCollision(alloc *Allocation, result *importResult) (string, error) {
+	collisions := result.collisions[alloc.GetNetworkInstance()]
+	if len(collisions) == 0 {
+		return "", nil
+	}
+	rt, err := r.getRoutingTable(alloc, false)
+	if err != nil {
+		return "", err
+	}
+	allocSelector, err := alloc.GetAllocSelector()
+	if err != nil {
+		return "", err
+	}
+	for _, route := range rt.GetByLabel(allocSelector) {
+		if other, ok := collisions[route.IPPrefix()]; ok {
+			return fmt.Sprintf("prefix %s is allocated in network instance %s as well",
+				route.IPPrefix(), other), nil
+		}
+	}
+	return "", nil
+}
+
+// getExportedPrefixes returns the outermost prefixes of the network instance
+// that are exported and selected by the import
+func (r *ipam) getExportedPrefixes(routes table.Routes, i prefixImport) []netaddr.IPPrefix {
+	selected := table.Routes{}
+	for _, route := range routes {
+		if !i.selector.Matches(route.GetLabels()) {
+			continue
+		}
+		for _, export := range r.exports[i.networkInstance] {
+			if export.Matches(route.GetLabels()) {
+				selected = append(selected, route)
+				break
+			}
+		}
+	}
+	prefixes := []netaddr.IPPrefix{}
+	for _, route := range selected {
+		nested := false
+		for _, other := range selected {
+			if other.IPPrefix().Bits() < route.IPPrefix().Bits() && other.IPPrefix().Contains(route.IPPrefix().IP()) {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			prefixes = append(prefixes, route.IPPrefix())
+		}
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		return prefixes[i].IP().Less(prefixes[j].IP())
+	})
+	return prefixes
+}
+
+// getImportConflict returns why the prefix cannot be imported; the prefix
+// cannot overlap a prefix the network instance owns, unless it is nested in
+// the imported prefix, or a prefix that is imported already
+func getImportConflict(own table.Routes, accepted []importedRoot, owner string, p netaddr.IPPrefix) string {
+	for _, route := range own {
+		if route.IPPrefix().Bits() <= p.Bits() && route.IPPrefix().Contains(p.IP()) {
+			return fmt.Sprintf("prefix %s imported from network instance %s overlaps with prefix %s",
+				p, owner, route.IPPrefix())
+		}
+	}
+	for _, root := range accepted {
+		if root.prefix.Overlaps(p) {
+			return fmt.Sprintf("prefix %s imported from network instance %s overlaps with prefix %s imported from network instance %s",
+				p, owner, root.prefix, root.owner)
+		}
+	}
+	return ""
+}
+
+// getNestedRoutes returns the routes nested in the prefix, including the
+// route of the prefix itself if requested
+func getNestedRoutes(routes table.Routes, p netaddr.IPPrefix, self bool) table.Routes {
+	nested := table.Routes{}
+	for _, route := range routes {
+		if !p.Contains(route.IPPrefix().IP()) || route.IPPrefix().Bits() < p.Bits() {
+			continue
+		}
+		if route.IPPrefix().Bits() == p.Bits() && !self {
+			continue
+		}
+		nested = append(nested, route)
+	}
+	return nested
+}
+
+// applyForeignRoutes replaces the routes of other network instances in the
+// routing table with the desired routes; it reports if the table changed and
+// the desired prefixes the network instance has its own route for, with the
+// network instance they originate from
+func applyForeignRoutes(rt *table.RouteTable, desired map[netaddr.IPPrefix]labels.Set) (bool, map[netaddr.IPPrefix]string) {
+	changed := false
+	collisions := map[netaddr.IPPrefix]string{}
+	for _, route := range rt.GetTable() {
+		if route.GetLabels().Get(ipamv1alpha1.NephioSourceNIKey) == "" {
+			continue
+		}
+		if l, ok := desired[route.IPPrefix()]; ok && labels.Equals(l, *route.GetLabels()) {
+			delete(desired, route.IPPrefix())
+			continue
+		}
+		_, _, _ = rt.Delete(route)
+		changed = true
+	}
+	for p, l := range desired {
+		if _, ok, _ := rt.Get(p); ok {
+			// the network instance has its own route for the prefix, the
+			// prefix was allocated in both network instances
+			collisions[p] = l.Get(ipamv1alpha1.NephioSourceNIKey)
+			continue
+		}
+		route := table.NewRoute(p)
+		route.UpdateLabel(l)
+		if err := rt.Add(route); err == nil {
+			changed = true
+		}
+	}
+	return changed, collisions
+}
+
+// getImportedPrefixes returns the prefixes that are imported in the routing table
+func getImportedPrefixes(rt *table.RouteTable) []ipamv1alpha1.ImportedPrefix {
+	imported := []ipamv1alpha1.ImportedPrefix{}
+	for _, route := range rt.GetTable() {
+		if owner := route.GetLabels().Get(ipamv1alpha1.NephioImportedFromKey); owner != "" {
+			imported = append(imported, ipamv1alpha1.ImportedPrefix{
+				Prefix:          route.IPPrefix().String(),
+				NetworkInstance: owner,
+			})
+		}
+	}
+	return imported
+}
+
+// validateImportOverlap rejects prefixes that overlap an imported prefix
+// without being nested in it
+func validateImportOverlap(alloc *Allocation, dryrunrt *table.RouteTable) string {
+	p := alloc.GetIPPrefix().Masked()
+	for _, route := range dryrunrt.GetTable() {
+		owner := route.GetLabels().Get(ipamv1alpha1.NephioImportedFromKey)
+		if owner == "" || !route.IPPrefix().Overlaps(p) {
+			continue
+		}
+		if p.Bits() <= route.IPPrefix().Bits() {
+			return fmt.Sprintf("prefix %s overlaps with prefix %s imported from network instance %s",
+				alloc.GetPrefix(), route.IPPrefix(), owner)
+		}
+	}
+	return ""
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
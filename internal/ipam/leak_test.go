@@ -0,0 +1,199 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hansthienpondt/goipam/pkg/table"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"inet.af/netaddr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newTestRoutes(prefixes ...string) table.Routes {
+	routes := table.Routes{}
+	for _, p := range prefixes {
+		routes = append(routes, table.NewRoute(netaddr.MustParseIPPrefix(p)))
+	}
+	return routes
+}
+
+func TestGetImportConflict(t *testing.T) {
+	cases := map[string]struct {
+		own      []string
+		accepted []importedRoot
+		prefix   string
+		wantErr  bool
+	}{
+		"NoOverlap": {
+			own:    []string{"10.0.0.0/16"},
+			prefix: "10.1.0.0/16",
+		},
+		"CoveredByOwnPrefix": {
+			own:     []string{"10.0.0.0/8"},
+			prefix:  "10.1.0.0/16",
+			wantErr: true,
+		},
+		"SameAsOwnPrefix": {
+			own:     []string{"10.1.0.0/16"},
+			prefix:  "10.1.0.0/16",
+			wantErr: true,
+		},
+		// a prefix of the network instance can be nested in the imported prefix
+		"CoversOwnPrefix": {
+			own:    []string{"10.1.1.0/24"},
+			prefix: "10.1.0.0/16",
+		},
+		"OverlapsImportedPrefix": {
+			accepted: []importedRoot{{owner: "vpc-1", prefix: netaddr.MustParseIPPrefix("10.1.0.0/24")}},
+			prefix:   "10.1.0.0/16",
+			wantErr:  true,
+		},
+		"NextToImportedPrefix": {
+			accepted: []importedRoot{{owner: "vpc-1", prefix: netaddr.MustParseIPPrefix("10.0.0.0/16")}},
+			prefix:   "10.1.0.0/16",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			msg := getImportConflict(newTestRoutes(tc.own...), tc.accepted, "vpc-shared", netaddr.MustParseIPPrefix(tc.prefix))
+			if (msg != "") != tc.wantErr {
+				t.Errorf("got %q, want error %t", msg, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetNestedRoutes(t *testing.T) {
+	routes := []string{"10.0.0.0/8", "10.1.0.0/16", "10.1.1.0/24", "10.1.1.1/32", "10.2.0.0/16"}
+	cases := map[string]struct {
+		prefix string
+		self   bool
+		want   []string
+	}{
+		"WithSelf": {
+			prefix: "10.1.0.0/16",
+			self:   true,
+			want:   []string{"10.1.0.0/16", "10.1.1.0/24", "10.1.1.1/32"},
+		},
+		"WithoutSelf": {
+			prefix: "10.1.0.0/16",
+			self:   false,
+			want:   []string{"10.1.1.0/24", "10.1.1.1/32"},
+		},
+		"Address": {
+			prefix: "10.1.1.1/32",
+			self:   false,
+			want:   []string{},
+		},
+		"NotInTable": {
+			prefix: "10.3.0.0/16",
+			self:   true,
+			want:   []string{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := getNestedRoutes(newTestRoutes(routes...), netaddr.MustParseIPPrefix(tc.prefix), tc.self)
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+			for i, route := range got {
+				if route.IPPrefix().String() != tc.want[i] {
+					t.Errorf("got %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAllocateImportCollision(t *testing.T) {
+	ctx := context.Background()
+	r := New(nil).(*ipam)
+	owner := &ipamv1alpha1.NetworkInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "vpc-shared", Namespace: "default"},
+		Spec: ipamv1alpha1.NetworkInstanceSpec{
+			Exports: []metav1.LabelSelector{{MatchLabels: map[string]string{
+				ipamv1alpha1.NephioPrefixKindKey: string(ipamv1alpha1.PrefixKindAggregate),
+			}}},
+		},
+	}
+	importer := &ipamv1alpha1.NetworkInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "vpc-1", Namespace: "default"},
+		Spec: ipamv1alpha1.NetworkInstanceSpec{
+			Imports: []ipamv1alpha1.PrefixImport{{NetworkInstance: "vpc-shared"}},
+		},
+	}
+	for _, cr := range []*ipamv1alpha1.NetworkInstance{owner, importer} {
+		if err := r.Init(ctx, cr); err != nil {
+			t.Fatalf("cannot init %s: %v", cr.GetName(), err)
+		}
+	}
+	if _, err := r.AllocateIPPrefix(ctx, &Allocation{
+		NamespacedName:  types.NamespacedName{Namespace: "default", Name: "aggregate"},
+		Origin:          ipamv1alpha1.OriginIPPrefix,
+		NetworkInstance: "vpc-shared",
+		PrefixKind:      ipamv1alpha1.PrefixKindAggregate,
+		Prefix:          "10.0.0.0/16",
+	}); err != nil {
+		t.Fatalf("cannot allocate aggregate: %v", err)
+	}
+	for _, cr := range []*ipamv1alpha1.NetworkInstance{owner, importer} {
+		if err := r.SetImports(ctx, cr); err != nil {
+			t.Fatalf("cannot set imports of %s: %v", cr.GetName(), err)
+		}
+	}
+
+	// the importing network instance allocated the pool at the same time,
+	// before the allocation was reflected in the owning network instance
+	rt, _ := r.get("vpc-1")
+	route := table.NewRoute(netaddr.MustParseIPPrefix("10.0.1.0/24"))
+	route.UpdateLabel(map[string]string{
+		ipamv1alpha1.NephioIPAllocactionNameKey: "pool-1",
+		ipamv1alpha1.NephioPrefixKindKey:        string(ipamv1alpha1.PrefixKindPool),
+	})
+	if err := rt.Add(route); err != nil {
+		t.Fatalf("cannot add route: %v", err)
+	}
+
+	_, err := r.AllocateIPPrefix(ctx, &Allocation{
+		NamespacedName:  types.NamespacedName{Namespace: "default", Name: "pool-2"},
+		Origin:          ipamv1alpha1.OriginIPPrefix,
+		NetworkInstance: "vpc-shared",
+		PrefixKind:      ipamv1alpha1.PrefixKindPool,
+		Prefix:          "10.0.1.0/24",
+	})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("got error %v, want %v", err, ErrConflict)
+	}
+	// the rejected allocation is released, the owning network instance
+	// reflects the allocation of the importing network instance
+	rt, _ = r.get("vpc-shared")
+	route, ok, _ := rt.Get(netaddr.MustParseIPPrefix("10.0.1.0/24"))
+	if !ok {
+		t.Fatalf("prefix 10.0.1.0/24 not reflected")
+	}
+	if got := route.GetLabels().Get(ipamv1alpha1.NephioSourceNIKey); got != "vpc-1" {
+		t.Errorf("got source network instance %q, want %q", got, "vpc-1")
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hansthienpondt/goipam/pkg/table"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// getLeaseDuration returns the lease duration of an allocation, zero when
+// the allocation has no lease
+func getLeaseDuration(d *metav1.Duration) time.Duration {
+	if d == nil || d.Duration < 0 {
+		return 0
+	}
+	return d.Duration
+}
+
+// setLease labels the allocation with the time its lease expires, such that
+// allocating it again renews the lease; it returns the time the lease
+// expires, zero when the allocation has no lease
+func setLease(alloc *Allocation, now time.Time) time.Time {
+	if alloc.LeaseDuration <= 0 {
+		return time.Time{}
+	}
+	expiry := time.Unix(now.Add(alloc.LeaseDuration).Unix(), 0)
+	l := alloc.GetLabels()
+	l[ipamv1alpha1.NephioLeaseExpiryKey] = strconv.FormatInt(expiry.Unix(), 10)
+	alloc.Labels = l
+	return expiry
+}
+
+// getLeaseExpiry returns the time the lease of the route expires, zero when
+// the route has no lease
+func getLeaseExpiry(route *table.Route) time.Time {
+	expiry, err := strconv.ParseInt(route.GetLabels().Get(ipamv1alpha1.NephioLeaseExpiryKey), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(expiry, 0)
+}
+
+// ReleaseExpired releases the allocations whose lease expired
+func (r *ipam) ReleaseExpired(ctx context.Context) error {
+	return r.releaseExpired(ctx, time.Now())
+}
+
+func (r *ipam) releaseExpired(ctx context.Context, now time.Time) error {
+	r.l = log.FromContext(ctx)
+
+	r.m.Lock()
+	rts := make(map[string]*table.RouteTable, len(r.ipam))
+	for niName, rt := range r.ipam {
+		rts[niName] = rt
+	}
+	r.m.Unlock()
+
+	expired := map[string]*Allocation{}
+	r.am.Lock()
+	for niName, rt := range rts {
+		for _, route := range rt.GetTable() {
+			if alloc := getExpiredAllocation(niName, route, now); alloc != nil {
+				expired[strings.Join([]string{niName, alloc.GetName()}, "/")] = alloc
+			}
+		}
+	}
+	r.am.Unlock()
+
+	for _, alloc := range expired {
+		r.l.Info("release expired allocation", "networkInstance", alloc.GetNetworkInstance(), "name", alloc.GetName())
+		if err := r.DeAllocateIPPrefix(ctx, alloc); err != nil && !errors.Is(err, ErrNetworkInstanceNotReady) {
+			return err
+		}
+	}
+	return nil
+}
+
+// getExpiredAllocation returns the allocation of the route when its lease
+// expired; the routes the ipam adds for a network and the routes mirrored
+// from other network instances are not released on their own
+func getExpiredAllocation(niName string, route *table.Route, now time.Time) *Allocation {
+	l := route.GetLabels()
+	if l.Get(ipamv1alpha1.NephioOriginKey) == string(ipamv1alpha1.OriginIPSystem) ||
+		l.Get(ipamv1alpha1.NephioSourceNIKey) != "" {
+		return nil
+	}
+	expiry := getLeaseExpiry(route)
+	if expiry.IsZero() || now.Before(expiry) {
+		return nil
+	}
+	alloc := &Allocation{
+		NamespacedName: types.NamespacedName{
+			Namespace: l.Get(ipamv1alpha1.NephioNamespaceKey),
+			Name:      l.Get(ipamv1alpha1.NephioIPAllocactionNameKey),
+		},
+		Origin:          ipamv1alpha1.Origin(l.Get(ipamv1alpha1.NephioOriginKey)),
+		NetworkInstance: niName,
+		PrefixKind:      ipamv1alpha1.PrefixKind(l.Get(ipamv1alpha1.NephioPrefixKindKey)),
+		Network:         l.Get(ipamv1alpha1.NephioNetworkNameKey),
+	}
+	// a dynamic allocation is released like it was allocated, without prefix
+	if l.Get(ipamv1alpha1.NephioDynamicKey) != "true" {
+		alloc.Prefix = route.IPPrefix().String()
+		if parentPrefixLength := l.Get(ipamv1alpha1.NephioParentPrefixLengthKey); parentPrefixLength != "" {
+			alloc.Prefix = strings.Join([]string{route.IPPrefix().IP().String(), parentPrefixLength}, "/")
+		}
+	}
+	return alloc
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hansthienpondt/goipam/pkg/table"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+)
+
+func TestLeaseExpiry(t *testing.T) {
+	cases := map[string]struct {
+		prefix        string
+		leaseDuration time.Duration
+	}{
+		"Dynamic": {
+			leaseDuration: time.Hour,
+		},
+		"Static": {
+			prefix:        "10.0.0.64/27",
+			leaseDuration: time.Hour,
+		},
+		"NoLease": {},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			r := newTestIpam(t, "vpc-1", "10.0.0.0/24")
+			alloc := newTestPoolAllocation("pool-a", tc.prefix)
+			alloc.LeaseDuration = tc.leaseDuration
+
+			now := time.Now()
+			allocatedPrefix, err := r.AllocateIPPrefix(ctx, alloc)
+			if err != nil {
+				t.Fatalf("cannot allocate: %v", err)
+			}
+			if tc.leaseDuration == 0 {
+				if !allocatedPrefix.LeaseExpiry.IsZero() {
+					t.Errorf("LeaseExpiry: got %v, want zero", allocatedPrefix.LeaseExpiry)
+				}
+				if err := r.releaseExpired(ctx, now.Add(24*time.Hour)); err != nil {
+					t.Fatalf("cannot release expired: %v", err)
+				}
+				if got := getTestAllocationRoutes(r, "pool-a"); len(got) != 1 {
+					t.Errorf("routes after release: got %d, want 1", len(got))
+				}
+				return
+			}
+			want := now.Add(tc.leaseDuration).Truncate(time.Second)
+			if got := allocatedPrefix.LeaseExpiry; got.Before(want) || got.After(want.Add(time.Second)) {
+				t.Errorf("LeaseExpiry: got %v, want %v", got, want)
+			}
+			got, err := r.GetAllocatedPrefix(ctx, newTestPoolAllocation("pool-a", tc.prefix))
+			if err != nil {
+				t.Fatalf("cannot get allocated prefix: %v", err)
+			}
+			if !got.LeaseExpiry.Equal(allocatedPrefix.LeaseExpiry) {
+				t.Errorf("GetAllocatedPrefix LeaseExpiry: got %v, want %v", got.LeaseExpiry, allocatedPrefix.LeaseExpiry)
+			}
+
+			// the allocation is held while the lease is valid
+			if err := r.releaseExpired(ctx, now.Add(tc.leaseDuration/2)); err != nil {
+				t.Fatalf("cannot release expired: %v", err)
+			}
+			if got := getTestAllocationRoutes(r, "pool-a"); len(got) != 1 {
+				t.Fatalf("routes before expiry: got %d, want 1", len(got))
+			}
+			// and released when the lease expired
+			if err := r.releaseExpired(ctx, now.Add(2*tc.leaseDuration)); err != nil {
+				t.Fatalf("cannot release expired: %v", err)
+			}
+			if got := getTestAllocationRoutes(r, "pool-a"); len(got) != 0 {
+				t.Errorf("routes after expiry: got %d, want 0", len(got))
+			}
+		})
+	}
+}
+
+// getTestAllocationRoutes returns the routes of the allocation in vpc-1
+func getTestAllocationRoutes(r *ipam, name string) table.Routes {
+	rt, _ := r.get("vpc-1")
+	routes := table.Routes{}
+	for _, route := range rt.GetTable() {
+		if route.GetLabels().Get(ipamv1alpha1.NephioIPAllocactionNameKey) == name {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
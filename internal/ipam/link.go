@@ -0,0 +1,305 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hansthienpondt/goipam/pkg/table"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/internal/utils/iputil"
+	"github.com/pkg/errors"
+	"inet.af/netaddr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// linkPrefixKindConfig handles point-to-point links; a link is a /31 or /127
+// (RFC 3021, RFC 6164) of which both addresses are assigned to an endpoint,
+// no network or broadcast address is reserved
+func (r *ipam) linkPrefixKindConfig() *PrefixKindConfig {
+	return &PrefixKindConfig{
+		NestingRule: ipamv1alpha1.NestingRule{
+			ParentKinds: []string{
+				string(ipamv1alpha1.PrefixKindAggregate),
+				string(ipamv1alpha1.PrefixKindPool),
+			},
+		},
+		WithPrefix: UsageConfig{
+			Validation: &ValidationConfig{
+				ValidateInputFn: ValidateInputLinkWithPrefixFn,
+			},
+			Mutator:  r.linkMutatorWithPrefix,
+			Insertor: r.LinkInsertor,
+		},
+		WithoutPrefix: UsageConfig{
+			Validation: &ValidationConfig{
+				ValidateInputFn: ValidateInputLinkWithoutPrefixFn,
+			},
+			Mutator:  r.linkMutatorWithoutPrefix,
+			Insertor: r.LinkInsertor,
+		},
+	}
+}
+
+// getLinkPrefixLength returns the prefix length of a point-to-point link
+func getLinkPrefixLength(af ipamv1alpha1.AddressFamily) uint8 {
+	if af == ipamv1alpha1.AddressFamilyIpv6 {
+		return 127
+	}
+	return 31
+}
+
+func ValidateInputLinkWithPrefixFn(alloc *Allocation) string {
+	if msg := validateLinkEndpoints(alloc); msg != "" {
+		return msg
+	}
+	p := alloc.GetIPPrefix()
+	if uint8(p.Bits()) != getLinkPrefixLength(iputil.GetAddressFamily(p)) {
+		return fmt.Sprintf("a link prefix needs to be a /31 or /127, got %s", p.String())
+	}
+	return ""
+}
+
+func ValidateInputLinkWithoutPrefixFn(alloc *Allocation) string {
+	if msg := validateLinkEndpoints(alloc); msg != "" {
+		return msg
+	}
+	if alloc.PrefixLength != 0 && alloc.PrefixLength != 31 && alloc.PrefixLength != 127 {
+		return fmt.Sprintf("a link can only request a prefix length of 31 or 127, got %d", alloc.PrefixLength)
+	}
+	af := alloc.GetAddressFamily()
+	if alloc.PrefixLength != 0 && (af == ipamv1alpha1.AddressFamilyIpv4 || af == ipamv1alpha1.AddressFamilyIpv6) &&
+		alloc.PrefixLength != getLinkPrefixLength(af) {
+		return fmt.Sprintf("an %s link needs a prefix length of %d, got %d", af, getLinkPrefixLength(af), alloc.PrefixLength)
+	}
+	return ""
+}
+
+func validateLinkEndpoints(alloc *Allocation) string {
+	if len(alloc.Endpoints) != 2 {
+		return fmt.Sprintf("a link needs 2 endpoints, got %d", len(alloc.Endpoints))
+	}
+	for _, ep := range alloc.Endpoints {
+		if ep.Node == "" || ep.Interface == "" {
+			return "a link endpoint needs a node and an interface"
+		}
+	}
+	if alloc.Endpoints[0] == alloc.Endpoints[1] {
+		return fmt.Sprintf("the endpoints of a link need to be different, got %s/%s twice",
+			alloc.Endpoints[0].Node, alloc.Endpoints[0].Interface)
+	}
+	return ""
+}
+
+func (r *ipam) linkMutatorWithPrefix(alloc *Allocation) []*Allocation {
+	return setLinkOrigin(alloc, r.GenericMutatorWithPrefix(alloc))
+}
+
+func (r *ipam) linkMutatorWithoutPrefix(alloc *Allocation) []*Allocation {
+	return setLinkOrigin(alloc, r.GenericMutatorWithoutPrefix(alloc))
+}
+
+// setLinkOrigin keeps the origin of the link, such that the link is restored
+// from the resource it was allocated with
+func setLinkOrigin(alloc *Allocation, newallocs []*Allocation) []*Allocation {
+	for _, newalloc := range newallocs {
+		newalloc.Labels[ipamv1alpha1.NephioOriginKey] = string(alloc.GetOrigin())
+	}
+	return newallocs
+}
+
+// LinkInsertor inserts the subnet of the link and an address route per
+// endpoint; all routes carry the name of the allocation such that they are
+// released together
+func (r *ipam) LinkInsertor(ctx context.Context, alloc *Allocation) (*AllocatedPrefix, error) {
+	r.l = log.FromContext(ctx)
+	r.l.Info("insert link", "alloc", alloc)
+
+	rt, err := r.getRoutingTable(alloc, false)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := r.getLinkPrefix(rt, alloc)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := alloc.GetFullLabels()
+	labels[ipamv1alpha1.NephioAddressFamilyKey] = string(iputil.GetAddressFamily(p))
+	labels[ipamv1alpha1.NephioPrefixLengthKey] = iputil.GetPrefixLength(p)
+	labels[ipamv1alpha1.NephioNetworkKey] = p.Masked().IP().String()
+	if alloc.Prefix == "" {
+		labels[ipamv1alpha1.NephioDynamicKey] = "true"
+	}
+	if err := upsertRoute(rt, p, labels); err != nil {
+		return nil, err
+	}
+	route, _, err := rt.Get(p)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get ip prefix")
+	}
+
+	allocatedPrefix := &AllocatedPrefix{
+		AllocatedPrefix: p.String(),
+		ParentPrefix:    getParentPrefix(rt, route),
+		Endpoints:       make([]ipamv1alpha1.AllocatedLinkEndpoint, 0, len(alloc.Endpoints)),
+	}
+	// both addresses of the link are usable, the first one goes to the first
+	// endpoint and the second one to the second endpoint
+	addresses := []netaddr.IP{p.Range().From(), p.Range().To()}
+	for i, ep := range alloc.Endpoints {
+		a := netaddr.IPPrefixFrom(addresses[i], addresses[i].BitLen())
+		epLabels := alloc.GetFullLabels()
+		epLabels[ipamv1alpha1.NephioAddressFamilyKey] = string(iputil.GetAddressFamily(p))
+		epLabels[ipamv1alpha1.NephioPrefixLengthKey] = iputil.GetAddressPrefixLength(p)
+		epLabels[ipamv1alpha1.NephioParentPrefixLengthKey] = iputil.GetPrefixLength(p)
+		epLabels[ipamv1alpha1.NephioNetworkKey] = p.Masked().IP().String()
+		epLabels[ipamv1alpha1.NephioNodeKey] = ep.Node
+		epLabels[ipamv1alpha1.NephioInterfaceKey] = ep.Interface
+		if err := upsertRoute(rt, a, epLabels); err != nil {
+			return nil, err
+		}
+		allocatedPrefix.Endpoints = append(allocatedPrefix.Endpoints, ipamv1alpha1.AllocatedLinkEndpoint{
+			LinkEndpoint: ep,
+			Address:      netaddr.IPPrefixFrom(addresses[i], p.Bits()).String(),
+		})
+	}
+
+	r.l.Info("allocatedPrefix", "allocatedPrefix", allocatedPrefix)
+	return allocatedPrefix, nil
+}
+
+// getLinkPrefix returns the prefix of the link; the prefix of the allocation
+// if it has one, the prefix that was allocated before for the link or a free
+// prefix in the selected route
+func (r *ipam) getLinkPrefix(rt *table.RouteTable, alloc *Allocation) (netaddr.IPPrefix, error) {
+	if alloc.Prefix != "" {
+		return alloc.GetIPPrefix().Masked(), nil
+	}
+
+	allocSelector, err := alloc.GetAllocSelector()
+	if err != nil {
+		return netaddr.IPPrefix{}, err
+	}
+	if route := getLinkRoute(rt.GetByLabel(allocSelector)); route != nil {
+		return route.IPPrefix(), nil
+	}
+
+	labelSelector, err := alloc.GetLabelSelector()
+	if err != nil {
+		return netaddr.IPPrefix{}, err
+	}
+	routes := r.getAllowedRoutes(alloc.GetNetworkInstance(), rt.GetByLabel(labelSelector))
+	if len(routes) == 0 {
+		return netaddr.IPPrefix{}, newError(ErrNotFound, fmt.Sprintf("no available routes based on the label selector: %v", labelSelector))
+	}
+	af := alloc.GetAddressFamily()
+	switch alloc.PrefixLength {
+	case 31:
+		af = ipamv1alpha1.AddressFamilyIpv4
+	case 127:
+		af = ipamv1alpha1.AddressFamilyIpv6
+	}
+	if af != ipamv1alpha1.AddressFamilyIpv4 && af != ipamv1alpha1.AddressFamilyIpv6 {
+		af = iputil.GetAddressFamily(routes[0].IPPrefix())
+	}
+	// only routes of the address family of the link can be selected
+	afRoutes := getAddressFamilyRoutes(routes, af)
+	if len(afRoutes) == 0 {
+		return netaddr.IPPrefix{}, newError(ErrNotFound, fmt.Sprintf("no available %s routes based on the label selector: %v", af, labelSelector))
+	}
+	_, p, err := r.findFreePrefix(rt, afRoutes, getLinkPrefixLength(af), r.getAllocationStrategy(alloc.GetNetworkInstance()))
+	if err != nil {
+		return netaddr.IPPrefix{}, err
+	}
+	return p, nil
+}
+
+// getLinkRoute returns the subnet route of a link, the endpoint routes carry
+// the prefix length of the subnet as parent prefix length
+func getLinkRoute(routes table.Routes) *table.Route {
+	for _, route := range routes {
+		if route.GetLabels().Get(ipamv1alpha1.NephioParentPrefixLengthKey) == "" {
+			return route
+		}
+	}
+	return nil
+}
+
+// getAllocatedLink returns the subnet and the endpoint addresses of a link
+func getAllocatedLink(rt *table.RouteTable, routes table.Routes) (*AllocatedPrefix, error) {
+	route := getLinkRoute(routes)
+	if route == nil {
+		return nil, errors.New("link without subnet")
+	}
+	allocatedPrefix := &AllocatedPrefix{
+		AllocatedPrefix: route.IPPrefix().String(),
+		ParentPrefix:    getParentPrefix(rt, route),
+		LeaseExpiry:     getLeaseExpiry(route),
+		Endpoints:       []ipamv1alpha1.AllocatedLinkEndpoint{},
+	}
+	eps := make(table.Routes, 0, len(routes))
+	for _, route := range routes {
+		if route.GetLabels().Get(ipamv1alpha1.NephioParentPrefixLengthKey) != "" {
+			eps = append(eps, route)
+		}
+	}
+	sort.Slice(eps, func(i, j int) bool {
+		return eps[i].IPPrefix().IP().Less(eps[j].IPPrefix().IP())
+	})
+	for _, ep := range eps {
+		allocatedPrefix.Endpoints = append(allocatedPrefix.Endpoints, ipamv1alpha1.AllocatedLinkEndpoint{
+			LinkEndpoint: ipamv1alpha1.LinkEndpoint{
+				Node:      ep.GetLabels().Get(ipamv1alpha1.NephioNodeKey),
+				Interface: ep.GetLabels().Get(ipamv1alpha1.NephioInterfaceKey),
+			},
+			Address: strings.Join([]string{
+				ep.IPPrefix().IP().String(),
+				ep.GetLabels().Get(ipamv1alpha1.NephioParentPrefixLengthKey),
+			}, "/"),
+		})
+	}
+	return allocatedPrefix, nil
+}
+
+// upsertRoute adds the route or updates the labels of an existing route
+func upsertRoute(rt *table.RouteTable, p netaddr.IPPrefix, labels map[string]string) error {
+	_, ok, err := rt.Get(p)
+	if err != nil {
+		return errors.Wrap(err, "cannot get ip prefix")
+	}
+	route := table.NewRoute(p)
+	route.UpdateLabel(labels)
+	if ok {
+		if err := rt.Update(route); err != nil {
+			if !strings.Contains(err.Error(), "already exists") {
+				return errors.Wrap(err, "cannot update prefix")
+			}
+		}
+		return nil
+	}
+	if err := rt.Add(route); err != nil {
+		if !strings.Contains(err.Error(), "already exists") {
+			return errors.Wrap(err, "cannot add prefix")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestGetLinkPrefix(t *testing.T) {
+	cases := map[string]struct {
+		aggregates   []string
+		prefix       string
+		prefixLength uint8
+		af           ipamv1alpha1.AddressFamily
+		wantPrefix   string
+		wantErr      error
+	}{
+		"StaticIpv4": {
+			aggregates: []string{"10.0.0.0/16", "2001:db8::/48"},
+			prefix:     "10.0.0.2/31",
+			wantPrefix: "10.0.0.2/31",
+		},
+		"StaticIpv6": {
+			aggregates: []string{"10.0.0.0/16", "2001:db8::/48"},
+			prefix:     "2001:db8::2/127",
+			wantPrefix: "2001:db8::2/127",
+		},
+		"PrefixLength31": {
+			aggregates:   []string{"10.0.0.0/16", "2001:db8::/48"},
+			prefixLength: 31,
+			wantPrefix:   "10.0.0.0/31",
+		},
+		"PrefixLength127": {
+			aggregates:   []string{"10.0.0.0/16", "2001:db8::/48"},
+			prefixLength: 127,
+			wantPrefix:   "2001:db8::/127",
+		},
+		"AddressFamilyIpv6": {
+			aggregates: []string{"10.0.0.0/16", "2001:db8::/48"},
+			af:         ipamv1alpha1.AddressFamilyIpv6,
+			wantPrefix: "2001:db8::/127",
+		},
+		"AddressFamilyOfRoutes": {
+			aggregates: []string{"2001:db8::/48"},
+			wantPrefix: "2001:db8::/127",
+		},
+		"NoRoutesOfAddressFamily": {
+			aggregates:   []string{"10.0.0.0/16"},
+			prefixLength: 127,
+			wantErr:      ErrNotFound,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := newTestIpam(t, "vpc-1", tc.aggregates...)
+			alloc := &Allocation{
+				NamespacedName:  types.NamespacedName{Namespace: "default", Name: "link-1"},
+				Origin:          ipamv1alpha1.OriginIPAllocation,
+				NetworkInstance: "vpc-1",
+				PrefixKind:      ipamv1alpha1.PrefixKindLink,
+				AddresFamily:    tc.af,
+				Prefix:          tc.prefix,
+				PrefixLength:    tc.prefixLength,
+				Endpoints: []ipamv1alpha1.LinkEndpoint{
+					{Node: "leaf1", Interface: "e1-1"},
+					{Node: "leaf2", Interface: "e1-1"},
+				},
+			}
+			if tc.prefix == "" {
+				alloc.SelectorLabels = map[string]string{ipamv1alpha1.NephioNetworkInstanceKey: "vpc-1"}
+			}
+			ap, err := r.AllocateIPPrefix(context.Background(), alloc)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if ap.AllocatedPrefix != tc.wantPrefix {
+				t.Errorf("got prefix %s, want %s", ap.AllocatedPrefix, tc.wantPrefix)
+			}
+
+			// the link keeps its prefix when it is allocated again
+			ap, err = r.AllocateIPPrefix(context.Background(), alloc)
+			if err != nil {
+				t.Fatalf("cannot allocate again: %v", err)
+			}
+			if ap.AllocatedPrefix != tc.wantPrefix {
+				t.Errorf("got prefix %s after allocating again, want %s", ap.AllocatedPrefix, tc.wantPrefix)
+			}
+		})
+	}
+}
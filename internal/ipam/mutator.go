@@ -42,11 +42,11 @@ func (r *ipam) mutateAllocation(alloc *Allocation) []*Allocation {
 	return mutatFn(alloc)
 }
 
-func (r *ipam) nopMutator(alloc *Allocation) []*Allocation {
+func (r *ipam) NopMutator(alloc *Allocation) []*Allocation {
 	return []*Allocation{}
 }
 
-func (r *ipam) genericMutatorWithoutPrefix(alloc *Allocation) []*Allocation {
+func (r *ipam) GenericMutatorWithoutPrefix(alloc *Allocation) []*Allocation {
 	newallocs := []*Allocation{}
 
 	// copy allocation
@@ -67,6 +67,7 @@ func (r *ipam) genericMutatorWithoutPrefix(alloc *Allocation) []*Allocation {
 
 	// NO GW allowed here
 	delete(newlabels, ipamv1alpha1.NephioGatewayKey)
+	delete(newlabels, ipamv1alpha1.NephioGatewayRoleKey)
 
 	newlabels[ipamv1alpha1.NephioIPPrefixNameKey] = alloc.GetName()
 	newlabels[ipamv1alpha1.NephioOriginKey] = string(ipamv1alpha1.OriginIPPrefix)
@@ -88,7 +89,7 @@ func (r *ipam) genericMutatorWithoutPrefix(alloc *Allocation) []*Allocation {
 
 // genericMutator mutates the allocation
 // removes gateway key in the label
-func (r *ipam) genericMutatorWithPrefix(alloc *Allocation) []*Allocation {
+func (r *ipam) GenericMutatorWithPrefix(alloc *Allocation) []*Allocation {
 	newallocs := []*Allocation{}
 
 	// copy allocation
@@ -110,6 +111,7 @@ func (r *ipam) genericMutatorWithPrefix(alloc *Allocation) []*Allocation {
 
 	// NO GW allowed here
 	delete(newlabels, ipamv1alpha1.NephioGatewayKey)
+	delete(newlabels, ipamv1alpha1.NephioGatewayRoleKey)
 
 	newlabels[ipamv1alpha1.NephioIPPrefixNameKey] = alloc.GetName()
 	newlabels[ipamv1alpha1.NephioOriginKey] = string(ipamv1alpha1.OriginIPPrefix)
@@ -205,8 +207,11 @@ func (r *ipam) networkNetMutator(alloc *Allocation) *Allocation {
 	newlabels := newalloc.GetLabels()
 	// NO GW allowed here
 	delete(newlabels, ipamv1alpha1.NephioGatewayKey)
+	delete(newlabels, ipamv1alpha1.NephioGatewayRoleKey)
 	newlabels[ipamv1alpha1.NephioIPAllocactionNameKey] = strings.Join([]string{p.Masked().IP().String(), iputil.GetPrefixLength(p)}, "-")
 	newlabels[ipamv1alpha1.NephioOriginKey] = "system"
+	// the routes of the network are not released with the lease of the allocation
+	delete(newlabels, ipamv1alpha1.NephioLeaseExpiryKey)
 	newlabels[ipamv1alpha1.NephioIPPrefixNameKey] = "net"
 	newlabels[ipamv1alpha1.NephioNetworkKey] = p.Masked().IP().String()
 	newlabels[ipamv1alpha1.NephioPrefixLengthKey] = iputil.GetPrefixLength(p)
@@ -228,8 +233,11 @@ func (r *ipam) networkFirstMutator(alloc *Allocation) *Allocation {
 	newlabels := newalloc.GetLabels()
 	// NO GW allowed here
 	delete(newlabels, ipamv1alpha1.NephioGatewayKey)
+	delete(newlabels, ipamv1alpha1.NephioGatewayRoleKey)
 	newlabels[ipamv1alpha1.NephioIPAllocactionNameKey] = p.Masked().IP().String()
 	newlabels[ipamv1alpha1.NephioOriginKey] = "system"
+	// the routes of the network are not released with the lease of the allocation
+	delete(newlabels, ipamv1alpha1.NephioLeaseExpiryKey)
 	newlabels[ipamv1alpha1.NephioIPPrefixNameKey] = "net"
 	newlabels[ipamv1alpha1.NephioNetworkKey] = p.Masked().IP().String()
 	newlabels[ipamv1alpha1.NephioPrefixLengthKey] = iputil.GetAddressPrefixLength(p)
@@ -0,0 +1,177 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"context"
+
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+)
+
+// Handlers are the generic mutators and insertors of the ipam; custom prefix
+// kinds can use them as is or wrap them
+type Handlers interface {
+	NopMutator(alloc *Allocation) []*Allocation
+	GenericMutatorWithPrefix(alloc *Allocation) []*Allocation
+	GenericMutatorWithoutPrefix(alloc *Allocation) []*Allocation
+	NopPrefixInsertor(ctx context.Context, alloc *Allocation) (*AllocatedPrefix, error)
+	GenericPrefixInsertor(ctx context.Context, alloc *Allocation) (*AllocatedPrefix, error)
+	NopPrefixAllocator(ctx context.Context, alloc *Allocation) (*AllocatedPrefix, error)
+	GenericPrefixAllocator(ctx context.Context, alloc *Allocation) (*AllocatedPrefix, error)
+}
+
+// PrefixKindConfig defines how the ipam validates, mutates and inserts the
+// allocations of a prefix kind
+type PrefixKindConfig struct {
+	// NestingRule is the default nesting rule of the prefix kind, the prefix kind
+	// is added to the child kinds of its parent kinds and to the parent kinds of
+	// its child kinds
+	NestingRule ipamv1alpha1.NestingRule
+	// WithPrefix handles the allocations with a prefix
+	WithPrefix UsageConfig
+	// WithoutPrefix handles the allocations without a prefix
+	WithoutPrefix UsageConfig
+}
+
+// UsageConfig holds the functions handling the allocations of a prefix kind
+// with or without a prefix; nil functions fall back to the generic ones
+type UsageConfig struct {
+	// Validation of the allocation; for allocations with a prefix the address
+	// and nesting functions follow the nesting rule
+	Validation *ValidationConfig
+	Mutator    MutatorFn
+	Insertor   InsertorFn
+}
+
+// PrefixKindFn returns the config of a prefix kind, given the generic handlers
+// of the ipam
+type PrefixKindFn func(h Handlers) *PrefixKindConfig
+
+// WithPrefixKind registers a prefix kind, or replaces the handling of an
+// existing prefix kind
+func WithPrefixKind(kind ipamv1alpha1.PrefixKind, fn PrefixKindFn) Option {
+	return func(r *ipam) {
+		r.registerPrefixKind(kind, fn(r))
+	}
+}
+
+// WithPriorityKey sets the label that orders the prefixes a dynamic allocation
+// is allocated from, the default is nephio.org/priority
+func WithPriorityKey(key string) Option {
+	return func(r *ipam) {
+		r.priorityKey = key
+	}
+}
+
+// WithAllocationStrategy sets how a dynamic allocation selects the free block
+// it is allocated from, the default is first-fit
+func WithAllocationStrategy(strategy ipamv1alpha1.AllocationStrategy) Option {
+	return func(r *ipam) {
+		r.strategy = strategy
+	}
+}
+
+func (r *ipam) registerPrefixKind(kind ipamv1alpha1.PrefixKind, cfg *PrefixKindConfig) {
+	withPrefix := ipamUsage{PrefixKind: kind, HasPrefix: true}
+	withoutPrefix := ipamUsage{PrefixKind: kind, HasPrefix: false}
+
+	r.vm.Lock()
+	r.validator[withPrefix] = validationConfigWithDefaults(cfg.WithPrefix.Validation)
+	r.validator[withoutPrefix] = validationConfigWithDefaults(cfg.WithoutPrefix.Validation)
+	r.vm.Unlock()
+
+	r.mm.Lock()
+	r.mutator[withPrefix] = cfg.WithPrefix.Mutator
+	if r.mutator[withPrefix] == nil {
+		r.mutator[withPrefix] = r.GenericMutatorWithPrefix
+	}
+	r.mutator[withoutPrefix] = cfg.WithoutPrefix.Mutator
+	if r.mutator[withoutPrefix] == nil {
+		r.mutator[withoutPrefix] = r.GenericMutatorWithoutPrefix
+	}
+	r.mm.Unlock()
+
+	r.im.Lock()
+	r.insertor[withPrefix] = cfg.WithPrefix.Insertor
+	if r.insertor[withPrefix] == nil {
+		r.insertor[withPrefix] = r.GenericPrefixInsertor
+	}
+	r.insertor[withoutPrefix] = cfg.WithoutPrefix.Insertor
+	if r.insertor[withoutPrefix] == nil {
+		r.insertor[withoutPrefix] = r.GenericPrefixAllocator
+	}
+	r.im.Unlock()
+
+	rule := *cfg.NestingRule.DeepCopy()
+	rule.PrefixKind = string(kind)
+	r.rules = setNestingRule(r.rules, rule)
+}
+
+// validationConfigWithDefaults completes the validation config with the
+// functions that do not validate or validate generically
+func validationConfigWithDefaults(fnc *ValidationConfig) *ValidationConfig {
+	c := &ValidationConfig{}
+	if fnc != nil {
+		*c = *fnc
+	}
+	if c.ValidateInputFn == nil {
+		c.ValidateInputFn = ValidateInputNopFn
+	}
+	if c.IsAddressFn == nil {
+		c.IsAddressFn = IsAddressGenericFn
+	}
+	if c.IsAddressInNetFn == nil {
+		c.IsAddressInNetFn = IsAddressInNetGenericFn
+	}
+	if c.GatewayValidationFn == nil {
+		c.GatewayValidationFn = GatewayValidationNopFn
+	}
+	if c.ExactPrefixMatchFn == nil {
+		c.ExactPrefixMatchFn = ExactPrefixMatchGenericFn
+	}
+	if c.FinalValidationFn == nil {
+		c.FinalValidationFn = FinalValidationNopFn
+	}
+	return c
+}
+
+// setNestingRule adds or replaces the rule and links the prefix kind of the
+// rule with its parent and child kinds
+func setNestingRule(rules []ipamv1alpha1.NestingRule, rule ipamv1alpha1.NestingRule) []ipamv1alpha1.NestingRule {
+	found := false
+	for i := range rules {
+		if rules[i].PrefixKind == rule.PrefixKind {
+			rules[i] = rule
+			found = true
+		}
+	}
+	if !found {
+		rules = append(rules, rule)
+	}
+	for i := range rules {
+		if rules[i].PrefixKind == rule.PrefixKind {
+			continue
+		}
+		if hasPrefixKind(rule.ParentKinds, rules[i].PrefixKind) && !hasPrefixKind(rules[i].ChildKinds, rule.PrefixKind) {
+			rules[i].ChildKinds = append(rules[i].ChildKinds, rule.PrefixKind)
+		}
+		if hasPrefixKind(rule.ChildKinds, rules[i].PrefixKind) && !hasPrefixKind(rules[i].ParentKinds, rule.PrefixKind) {
+			rules[i].ParentKinds = append(rules[i].ParentKinds, rule.PrefixKind)
+		}
+	}
+	return rules
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"fmt"
+
+	"github.com/hansthienpondt/goipam/pkg/table"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/internal/utils/iputil"
+)
+
+// DefaultNestingRules are the nesting rules documented in the README; they
+// apply to every prefix kind the nesting policy does not define a rule for
+var DefaultNestingRules = []ipamv1alpha1.NestingRule{
+	{
+		PrefixKind:  string(ipamv1alpha1.PrefixKindAggregate),
+		ParentKinds: []string{string(ipamv1alpha1.PrefixKindAggregate)},
+		ChildKinds: []string{
+			string(ipamv1alpha1.PrefixKindAggregate),
+			string(ipamv1alpha1.PrefixKindNetwork),
+			string(ipamv1alpha1.PrefixKindPool),
+			string(ipamv1alpha1.PrefixKindLoopback),
+		},
+	},
+	{
+		PrefixKind:        string(ipamv1alpha1.PrefixKindNetwork),
+		ParentKinds:       []string{string(ipamv1alpha1.PrefixKindAggregate)},
+		ChildKinds:        []string{string(ipamv1alpha1.PrefixKindNetwork)},
+		AllowAddressInNet: true,
+	},
+	{
+		PrefixKind:  string(ipamv1alpha1.PrefixKindPool),
+		ParentKinds: []string{string(ipamv1alpha1.PrefixKindAggregate), string(ipamv1alpha1.PrefixKindPool)},
+		ChildKinds:  []string{string(ipamv1alpha1.PrefixKindPool)},
+	},
+	{
+		PrefixKind:        string(ipamv1alpha1.PrefixKindLoopback),
+		ParentKinds:       []string{string(ipamv1alpha1.PrefixKindAggregate), string(ipamv1alpha1.PrefixKindLoopback)},
+		ChildKinds:        []string{string(ipamv1alpha1.PrefixKindLoopback)},
+		AllowAddress:      true,
+		NestedAddressOnly: true,
+	},
+}
+
+// nestingPolicy holds the nesting rule per prefix kind
+type nestingPolicy map[ipamv1alpha1.PrefixKind]ipamv1alpha1.NestingRule
+
+// newNestingPolicy returns the default rules overruled by the rules provided
+func newNestingPolicy(defaults, rules []ipamv1alpha1.NestingRule) (nestingPolicy, error) {
+	p := nestingPolicy{}
+	for _, rule := range defaults {
+		p[ipamv1alpha1.PrefixKind(rule.PrefixKind)] = rule
+	}
+	defined := map[string]struct{}{}
+	for _, rule := range rules {
+		if _, ok := defined[rule.PrefixKind]; ok {
+			return nil, fmt.Errorf("duplicate nesting rule for prefix kind %s", rule.PrefixKind)
+		}
+		defined[rule.PrefixKind] = struct{}{}
+		if _, ok := p[ipamv1alpha1.PrefixKind(rule.PrefixKind)]; !ok {
+			return nil, fmt.Errorf("unknown prefix kind %s in nesting rule", rule.PrefixKind)
+		}
+		p[ipamv1alpha1.PrefixKind(rule.PrefixKind)] = rule
+	}
+	for kind, rule := range p {
+		for _, k := range append(append([]string{}, rule.ParentKinds...), rule.ChildKinds...) {
+			if _, ok := p[ipamv1alpha1.PrefixKind(k)]; !ok {
+				return nil, fmt.Errorf("unknown prefix kind %s in nesting rule of prefix kind %s", k, kind)
+			}
+		}
+	}
+	return p, nil
+}
+
+// validationConfig returns the validation config of a prefix kind with a
+// prefix, completing the kind specific functions with the nesting rules
+func (p nestingPolicy) validationConfig(kind ipamv1alpha1.PrefixKind, fnc *ValidationConfig) *ValidationConfig {
+	rule := p[kind]
+	c := *fnc
+	c.IsAddressFn = IsAddressGenericFn
+	if rule.AllowAddress {
+		c.IsAddressFn = IsAddressNopFn
+	}
+	c.IsAddressInNetFn = IsAddressInNetGenericFn
+	if rule.AllowAddressInNet {
+		c.IsAddressInNetFn = IsAddressInNetNopFn
+	}
+	c.ChildrenExistFn = p.childrenExistFn(kind)
+	c.ParentExistFn = p.parentExistFn(kind)
+	return &c
+}
+
+// parentExistFn validates the prefixes a prefix of the kind would be nested in,
+// both the rule of the kind and the rule of the parent kind need to allow it
+func (p nestingPolicy) parentExistFn(kind ipamv1alpha1.PrefixKind) ParentExistFn {
+	rule := p[kind]
+	return func(alloc *Allocation, routes table.Routes) string {
+		depth := 0
+		for _, route := range routes {
+			parentKind := route.GetLabels().Get(ipamv1alpha1.NephioPrefixKindKey)
+			if !hasPrefixKind(rule.ParentKinds, parentKind) ||
+				!hasPrefixKind(p[ipamv1alpha1.PrefixKind(parentKind)].ChildKinds, string(kind)) {
+				return fmt.Sprintf("nesting %s prefixes in a %s prefix is not allowed, prefix nested with %s",
+					kind,
+					parentKind,
+					route.GetLabels().Get(ipamv1alpha1.NephioIPAllocactionNameKey))
+			}
+			if parentKind == string(kind) {
+				depth++
+			}
+		}
+		if depth > 0 && rule.NestedAddressOnly && !iputil.IsAddress(alloc.GetIPPrefix()) {
+			return fmt.Sprintf("nesting %s prefixes only possible with address (/32, /128) based prefixes, got %s",
+				kind, alloc.GetIPPrefix().String())
+		}
+		if rule.MaxNestingDepth > 0 && depth > int(rule.MaxNestingDepth) {
+			return fmt.Sprintf("%s prefixes can be nested at most %d deep, got %d", kind, rule.MaxNestingDepth, depth)
+		}
+		return ""
+	}
+}
+
+// childrenExistFn validates the prefixes that would be nested in a prefix of the
+// kind, both the rule of the kind and the rule of the child kind need to allow it
+func (p nestingPolicy) childrenExistFn(kind ipamv1alpha1.PrefixKind) ChildrenExistFn {
+	rule := p[kind]
+	return func(alloc *Allocation, routes table.Routes, dryrunrt *table.RouteTable) string {
+		for _, route := range routes {
+			childKind := route.GetLabels().Get(ipamv1alpha1.NephioPrefixKindKey)
+			if !hasPrefixKind(rule.ChildKinds, childKind) ||
+				!hasPrefixKind(p[ipamv1alpha1.PrefixKind(childKind)].ParentKinds, string(kind)) {
+				return fmt.Sprintf("a more specific prefix was already allocated %s, nesting %s prefixes in a %s prefix is not allowed",
+					route.GetLabels().Get(ipamv1alpha1.NephioIPAllocactionNameKey),
+					childKind,
+					kind)
+			}
+			if childKind != string(kind) {
+				continue
+			}
+			if rule.NestedAddressOnly && !iputil.IsAddress(route.IPPrefix()) {
+				return fmt.Sprintf("a more specific prefix was already allocated %s, nesting %s prefixes only possible with address (/32, /128) based prefixes",
+					route.GetLabels().Get(ipamv1alpha1.NephioIPAllocactionNameKey),
+					kind)
+			}
+			if rule.MaxNestingDepth > 0 {
+				depth := 0
+				for _, parent := range route.GetParents(dryrunrt) {
+					if parent.GetLabels().Get(ipamv1alpha1.NephioPrefixKindKey) == string(kind) {
+						depth++
+					}
+				}
+				if depth > int(rule.MaxNestingDepth) {
+					return fmt.Sprintf("a more specific prefix was already allocated %s, %s prefixes can be nested at most %d deep",
+						route.GetLabels().Get(ipamv1alpha1.NephioIPAllocactionNameKey),
+						kind,
+						rule.MaxNestingDepth)
+				}
+			}
+		}
+		return ""
+	}
+}
+
+func hasPrefixKind(kinds []string, kind string) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,298 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"testing"
+
+	"github.com/hansthienpondt/goipam/pkg/table"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"inet.af/netaddr"
+)
+
+var testPrefixKinds = []ipamv1alpha1.PrefixKind{
+	ipamv1alpha1.PrefixKindAggregate,
+	ipamv1alpha1.PrefixKindNetwork,
+	ipamv1alpha1.PrefixKindPool,
+	ipamv1alpha1.PrefixKindLoopback,
+	ipamv1alpha1.PrefixKindLink,
+}
+
+// newTestNestingPolicy returns the policy of an ipam with the default prefix
+// kinds, overruled by the rules provided
+func newTestNestingPolicy(t *testing.T, rules ...ipamv1alpha1.NestingRule) nestingPolicy {
+	t.Helper()
+	defaults := []ipamv1alpha1.NestingRule{}
+	for _, rule := range DefaultNestingRules {
+		defaults = append(defaults, *rule.DeepCopy())
+	}
+	link := *(&ipam{}).linkPrefixKindConfig().NestingRule.DeepCopy()
+	link.PrefixKind = string(ipamv1alpha1.PrefixKindLink)
+	defaults = setNestingRule(defaults, link)
+
+	p, err := newNestingPolicy(defaults, rules)
+	if err != nil {
+		t.Fatalf("cannot create nesting policy: %v", err)
+	}
+	return p
+}
+
+// newTestRoute returns a route of the prefix kind, as inserted by the mutators
+func newTestRoute(prefix string, kind ipamv1alpha1.PrefixKind) *table.Route {
+	route := table.NewRoute(netaddr.MustParseIPPrefix(prefix))
+	route.UpdateLabel(map[string]string{
+		ipamv1alpha1.NephioPrefixKindKey:        string(kind),
+		ipamv1alpha1.NephioIPAllocactionNameKey: prefix,
+	})
+	return route
+}
+
+// newTestNestedRouteTable returns a table with the routes, from the least to
+// the most specific, and the routes as they are in the table
+func newTestNestedRouteTable(t *testing.T, routes ...*table.Route) (*table.RouteTable, table.Routes) {
+	t.Helper()
+	rt := table.NewRouteTable()
+	for _, route := range routes {
+		if err := rt.Add(route); err != nil {
+			t.Fatalf("cannot add route %s: %v", route.IPPrefix().String(), err)
+		}
+	}
+	return rt, routes
+}
+
+func TestNestingPolicyDefaultRules(t *testing.T) {
+	// the prefix kinds that can be nested in a prefix kind with the default rules
+	cases := map[ipamv1alpha1.PrefixKind][]ipamv1alpha1.PrefixKind{
+		ipamv1alpha1.PrefixKindAggregate: {
+			ipamv1alpha1.PrefixKindAggregate,
+			ipamv1alpha1.PrefixKindNetwork,
+			ipamv1alpha1.PrefixKindPool,
+			ipamv1alpha1.PrefixKindLoopback,
+			ipamv1alpha1.PrefixKindLink,
+		},
+		// a network only nests the addresses of the network, which are not
+		// validated as prefixes
+		ipamv1alpha1.PrefixKindNetwork:  {},
+		ipamv1alpha1.PrefixKindPool:     {ipamv1alpha1.PrefixKindPool, ipamv1alpha1.PrefixKindLink},
+		ipamv1alpha1.PrefixKindLoopback: {ipamv1alpha1.PrefixKindLoopback},
+		ipamv1alpha1.PrefixKindLink:     {},
+	}
+
+	p := newTestNestingPolicy(t)
+	for parentKind, childKinds := range cases {
+		for _, childKind := range testPrefixKinds {
+			parentKind, childKind := parentKind, childKind
+			t.Run(string(parentKind)+"/"+string(childKind), func(t *testing.T) {
+				want := false
+				for _, k := range childKinds {
+					if k == childKind {
+						want = true
+					}
+				}
+				// the child is an address, which every kind that nests in its
+				// own kind accepts
+				parent := newTestRoute("10.0.0.0/24", parentKind)
+				child := newTestRoute("10.0.0.0/32", childKind)
+				rt, _ := newTestNestedRouteTable(t, parent, child)
+
+				alloc := &Allocation{PrefixKind: childKind, Prefix: "10.0.0.0/32"}
+				if got := p.parentExistFn(childKind)(alloc, table.Routes{parent}) == ""; got != want {
+					t.Errorf("parentExistFn: got allowed %t, want %t", got, want)
+				}
+				alloc = &Allocation{PrefixKind: parentKind, Prefix: "10.0.0.0/24"}
+				if got := p.childrenExistFn(parentKind)(alloc, table.Routes{child}, rt) == ""; got != want {
+					t.Errorf("childrenExistFn: got allowed %t, want %t", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestNestingPolicyNestedAddressOnly(t *testing.T) {
+	cases := map[string]struct {
+		parentKind ipamv1alpha1.PrefixKind
+		prefix     string
+		wantErr    bool
+	}{
+		"AddressInLoopback": {
+			parentKind: ipamv1alpha1.PrefixKindLoopback,
+			prefix:     "10.0.0.1/32",
+			wantErr:    false,
+		},
+		"PrefixInLoopback": {
+			parentKind: ipamv1alpha1.PrefixKindLoopback,
+			prefix:     "10.0.0.0/26",
+			wantErr:    true,
+		},
+		"PrefixInAggregate": {
+			parentKind: ipamv1alpha1.PrefixKindAggregate,
+			prefix:     "10.0.0.0/26",
+			wantErr:    false,
+		},
+	}
+
+	p := newTestNestingPolicy(t)
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			parent := newTestRoute("10.0.0.0/24", tc.parentKind)
+			child := newTestRoute(tc.prefix, ipamv1alpha1.PrefixKindLoopback)
+			rt, _ := newTestNestedRouteTable(t, parent, child)
+
+			alloc := &Allocation{PrefixKind: ipamv1alpha1.PrefixKindLoopback, Prefix: tc.prefix}
+			if msg := p.parentExistFn(ipamv1alpha1.PrefixKindLoopback)(alloc, table.Routes{parent}); (msg != "") != tc.wantErr {
+				t.Errorf("parentExistFn: got %q, want error %t", msg, tc.wantErr)
+			}
+			alloc = &Allocation{PrefixKind: tc.parentKind, Prefix: "10.0.0.0/24"}
+			if msg := p.childrenExistFn(tc.parentKind)(alloc, table.Routes{child}, rt); (msg != "") != tc.wantErr {
+				t.Errorf("childrenExistFn: got %q, want error %t", msg, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestNestingPolicyMaxNestingDepth(t *testing.T) {
+	cases := map[string]struct {
+		maxNestingDepth uint32
+		prefixes        []string
+		wantErr         bool
+	}{
+		"NoLimit": {
+			maxNestingDepth: 0,
+			prefixes:        []string{"10.0.0.0/16", "10.0.0.0/20", "10.0.0.0/24"},
+			wantErr:         false,
+		},
+		"WithinLimit": {
+			maxNestingDepth: 2,
+			prefixes:        []string{"10.0.0.0/16", "10.0.0.0/20", "10.0.0.0/24"},
+			wantErr:         false,
+		},
+		"ExceedsLimit": {
+			maxNestingDepth: 1,
+			prefixes:        []string{"10.0.0.0/16", "10.0.0.0/20", "10.0.0.0/24"},
+			wantErr:         true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := newTestNestingPolicy(t, ipamv1alpha1.NestingRule{
+				PrefixKind:      string(ipamv1alpha1.PrefixKindPool),
+				ParentKinds:     []string{string(ipamv1alpha1.PrefixKindAggregate), string(ipamv1alpha1.PrefixKindPool)},
+				ChildKinds:      []string{string(ipamv1alpha1.PrefixKindPool)},
+				MaxNestingDepth: tc.maxNestingDepth,
+			})
+			routes := []*table.Route{newTestRoute("10.0.0.0/8", ipamv1alpha1.PrefixKindAggregate)}
+			for _, prefix := range tc.prefixes {
+				routes = append(routes, newTestRoute(prefix, ipamv1alpha1.PrefixKindPool))
+			}
+			rt, routes := newTestNestedRouteTable(t, routes...)
+
+			// the most specific pool is validated against its parents
+			last := routes[len(routes)-1]
+			alloc := &Allocation{PrefixKind: ipamv1alpha1.PrefixKindPool, Prefix: last.IPPrefix().String()}
+			if msg := p.parentExistFn(ipamv1alpha1.PrefixKindPool)(alloc, routes[:len(routes)-1]); (msg != "") != tc.wantErr {
+				t.Errorf("parentExistFn: got %q, want error %t", msg, tc.wantErr)
+			}
+			// the least specific pool is validated against its children
+			alloc = &Allocation{PrefixKind: ipamv1alpha1.PrefixKindPool, Prefix: routes[1].IPPrefix().String()}
+			if msg := p.childrenExistFn(ipamv1alpha1.PrefixKindPool)(alloc, routes[2:], rt); (msg != "") != tc.wantErr {
+				t.Errorf("childrenExistFn: got %q, want error %t", msg, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewNestingPolicy(t *testing.T) {
+	cases := map[string]struct {
+		rules      []ipamv1alpha1.NestingRule
+		parentKind ipamv1alpha1.PrefixKind
+		childKind  ipamv1alpha1.PrefixKind
+		wantErr    bool
+		wantNested bool
+	}{
+		"Defaults": {
+			parentKind: ipamv1alpha1.PrefixKindPool,
+			childKind:  ipamv1alpha1.PrefixKindLoopback,
+			wantNested: false,
+		},
+		"OverrideBothKinds": {
+			rules: []ipamv1alpha1.NestingRule{
+				{
+					PrefixKind:  string(ipamv1alpha1.PrefixKindPool),
+					ParentKinds: []string{string(ipamv1alpha1.PrefixKindAggregate), string(ipamv1alpha1.PrefixKindPool)},
+					ChildKinds:  []string{string(ipamv1alpha1.PrefixKindPool), string(ipamv1alpha1.PrefixKindLoopback)},
+				},
+				{
+					PrefixKind:   string(ipamv1alpha1.PrefixKindLoopback),
+					ParentKinds:  []string{string(ipamv1alpha1.PrefixKindAggregate), string(ipamv1alpha1.PrefixKindPool)},
+					AllowAddress: true,
+				},
+			},
+			parentKind: ipamv1alpha1.PrefixKindPool,
+			childKind:  ipamv1alpha1.PrefixKindLoopback,
+			wantNested: true,
+		},
+		"OverrideParentKindOnly": {
+			rules: []ipamv1alpha1.NestingRule{
+				{
+					PrefixKind:  string(ipamv1alpha1.PrefixKindPool),
+					ParentKinds: []string{string(ipamv1alpha1.PrefixKindAggregate), string(ipamv1alpha1.PrefixKindPool)},
+					ChildKinds:  []string{string(ipamv1alpha1.PrefixKindPool), string(ipamv1alpha1.PrefixKindLoopback)},
+				},
+			},
+			parentKind: ipamv1alpha1.PrefixKindPool,
+			childKind:  ipamv1alpha1.PrefixKindLoopback,
+			wantNested: false,
+		},
+		"DuplicateRule": {
+			rules: []ipamv1alpha1.NestingRule{
+				{PrefixKind: string(ipamv1alpha1.PrefixKindPool)},
+				{PrefixKind: string(ipamv1alpha1.PrefixKindPool)},
+			},
+			wantErr: true,
+		},
+		"UnknownPrefixKind": {
+			rules: []ipamv1alpha1.NestingRule{
+				{PrefixKind: "vip"},
+			},
+			wantErr: true,
+		},
+		"UnknownChildKind": {
+			rules: []ipamv1alpha1.NestingRule{
+				{PrefixKind: string(ipamv1alpha1.PrefixKindPool), ChildKinds: []string{"vip"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p, err := newNestingPolicy(DefaultNestingRules, tc.rules)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			parent := newTestRoute("10.0.0.0/24", tc.parentKind)
+			alloc := &Allocation{PrefixKind: tc.childKind, Prefix: "10.0.0.1/32"}
+			if got := p.parentExistFn(tc.childKind)(alloc, table.Routes{parent}) == ""; got != tc.wantNested {
+				t.Errorf("got nested %t, want %t", got, tc.wantNested)
+			}
+		})
+	}
+}
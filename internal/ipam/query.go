@@ -0,0 +1,104 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hansthienpondt/goipam/pkg/table"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/internal/utils/iputil"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// GetAllocatedPrefix returns the prefix and gateway that are allocated in the
+// ipam for the allocation, without changing the routing table
+func (r *ipam) GetAllocatedPrefix(ctx context.Context, alloc *Allocation) (*AllocatedPrefix, error) {
+	r.l = log.FromContext(ctx)
+	r.l.Info("get allocated prefix", "alloc", alloc)
+
+	rt, err := r.getRoutingTable(alloc, false)
+	if err != nil {
+		return nil, err
+	}
+
+	allocSelector, err := alloc.GetAllocSelector()
+	if err != nil {
+		return nil, err
+	}
+	routes := rt.GetByLabel(allocSelector)
+	if len(routes) == 0 {
+		return nil, newError(ErrNotFound, fmt.Sprintf("allocation %s in network-instance %s", alloc.GetName(), alloc.GetNetworkInstance()))
+	}
+	if routes[0].GetLabels().Get(ipamv1alpha1.NephioPrefixKindKey) == string(ipamv1alpha1.PrefixKindLink) {
+		return getAllocatedLink(rt, routes)
+	}
+	// there should only be 1 route with this name in the route table
+	route := routes[0]
+	prefix := route.IPPrefix().String()
+	if parentPrefixLength := route.GetLabels().Get(ipamv1alpha1.NephioParentPrefixLengthKey); parentPrefixLength != "" {
+		n := strings.Split(prefix, "/")
+		prefix = strings.Join([]string{n[0], parentPrefixLength}, "/")
+	}
+	allocatedPrefix := &AllocatedPrefix{
+		AllocatedPrefix: prefix,
+		ParentPrefix:    getParentPrefix(rt, route),
+		LeaseExpiry:     getLeaseExpiry(route),
+	}
+
+	if route.GetLabels().Get(ipamv1alpha1.NephioPrefixKindKey) == string(ipamv1alpha1.PrefixKindNetwork) {
+		gatewaySelector, err := getGatewaySelectorFromRoute(route)
+		if err != nil {
+			return nil, err
+		}
+		setGateways(allocatedPrefix, rt.GetByLabel(gatewaySelector), iputil.GetAddressFamily(route.IPPrefix()))
+	}
+	return allocatedPrefix, nil
+}
+
+// GetRoutes returns the routes of the network instance that match the selector
+func (r *ipam) GetRoutes(niName string, selector labels.Selector) (table.Routes, error) {
+	rt, ok := r.get(niName)
+	if !ok {
+		return nil, newError(ErrNetworkInstanceNotReady, fmt.Sprintf("network-instance %s", niName))
+	}
+	if selector == nil {
+		return rt.GetTable(), nil
+	}
+	return rt.GetByLabel(selector), nil
+}
+
+func getGatewaySelectorFromRoute(route *table.Route) (labels.Selector, error) {
+	l := map[string]string{
+		ipamv1alpha1.NephioGatewayKey:         "true",
+		ipamv1alpha1.NephioNetworkNameKey:     route.GetLabels().Get(ipamv1alpha1.NephioNetworkNameKey),
+		ipamv1alpha1.NephioNetworkInstanceKey: route.GetLabels().Get(ipamv1alpha1.NephioNetworkInstanceKey),
+	}
+	fullselector := labels.NewSelector()
+	for k, v := range l {
+		req, err := labels.NewRequirement(k, selection.In, []string{v})
+		if err != nil {
+			return nil, err
+		}
+		fullselector = fullselector.Add(*req)
+	}
+	return fullselector, nil
+}
@@ -0,0 +1,243 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hansthienpondt/goipam/pkg/table"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/pkg/errors"
+	"inet.af/netaddr"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// quota caps the number of allocations a tenant holds in a network instance
+type quota struct {
+	name   string
+	spec   ipamv1alpha1.IPQuotaSpec
+	prefix *netaddr.IPPrefix
+}
+
+func newQuota(cr *ipamv1alpha1.IPQuota) (*quota, error) {
+	q := &quota{
+		name: types.NamespacedName{Namespace: cr.GetNamespace(), Name: cr.GetName()}.String(),
+		spec: cr.Spec,
+	}
+	switch cr.Spec.TenantKind {
+	case ipamv1alpha1.QuotaTenantKindNamespace, ipamv1alpha1.QuotaTenantKindClient:
+	case ipamv1alpha1.QuotaTenantKindLabel:
+		if cr.Spec.LabelKey == "" {
+			return nil, errors.New("a quota per label needs a label key")
+		}
+	default:
+		return nil, fmt.Errorf("unknown tenant kind %s", cr.Spec.TenantKind)
+	}
+	if cr.Spec.Prefix != "" {
+		p, err := netaddr.ParseIPPrefix(cr.Spec.Prefix)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot parse quota prefix")
+		}
+		p = p.Masked()
+		q.prefix = &p
+	}
+	return q, nil
+}
+
+// getTenant returns the tenant identified by the labels, empty when the labels
+// do not identify a tenant of the quota
+func (q *quota) getTenant(l map[string]string) string {
+	var tenant string
+	switch q.spec.TenantKind {
+	case ipamv1alpha1.QuotaTenantKindNamespace:
+		tenant = l[ipamv1alpha1.NephioNamespaceKey]
+	case ipamv1alpha1.QuotaTenantKindLabel:
+		tenant = l[q.spec.LabelKey]
+	case ipamv1alpha1.QuotaTenantKindClient:
+		tenant = l[ipamv1alpha1.NephioClientKey]
+	}
+	if q.spec.Tenant != "" && q.spec.Tenant != tenant {
+		return ""
+	}
+	return tenant
+}
+
+// contains returns true if the prefix is in the scope of the quota
+func (q *quota) contains(p netaddr.IPPrefix) bool {
+	return q.prefix == nil || (q.prefix.Bits() <= p.Bits() && q.prefix.Contains(p.IP()))
+}
+
+// getUsage returns the number of allocations per tenant of each quota, keyed
+// by the name of the quota, in a single pass over the routing table; routes the
+// ipam adds and routes of other network instances are not counted
+func getUsage(rt *table.RouteTable, quotas ...*quota) map[string]map[string]int {
+	allocs := make(map[string]map[string]map[string]struct{}, len(quotas))
+	for _, q := range quotas {
+		allocs[q.name] = map[string]map[string]struct{}{}
+	}
+	for _, route := range rt.GetTable() {
+		l := route.GetLabels()
+		if l.Get(ipamv1alpha1.NephioOriginKey) == string(ipamv1alpha1.OriginIPSystem) ||
+			l.Get(ipamv1alpha1.NephioSourceNIKey) != "" {
+			continue
+		}
+		for _, q := range quotas {
+			if !q.contains(route.IPPrefix()) {
+				continue
+			}
+			tenant := q.getTenant(*l)
+			if tenant == "" {
+				continue
+			}
+			if allocs[q.name][tenant] == nil {
+				allocs[q.name][tenant] = map[string]struct{}{}
+			}
+			allocs[q.name][tenant][l.Get(ipamv1alpha1.NephioIPAllocactionNameKey)] = struct{}{}
+		}
+	}
+	usage := make(map[string]map[string]int, len(allocs))
+	for name, tenants := range allocs {
+		usage[name] = make(map[string]int, len(tenants))
+		for tenant, names := range tenants {
+			usage[name][tenant] = len(names)
+		}
+	}
+	return usage
+}
+
+// SetQuota applies the quota to the allocations of its network instance
+func (r *ipam) SetQuota(cr *ipamv1alpha1.IPQuota) error {
+	q, err := newQuota(cr)
+	if err != nil {
+		return err
+	}
+	r.qm.Lock()
+	defer r.qm.Unlock()
+	r.quotas[q.name] = q
+	return nil
+}
+
+// DeleteQuota removes the quota
+func (r *ipam) DeleteQuota(name types.NamespacedName) {
+	r.qm.Lock()
+	defer r.qm.Unlock()
+	delete(r.quotas, name.String())
+}
+
+// GetQuotaUsage returns the number of allocations per tenant of the quota
+func (r *ipam) GetQuotaUsage(cr *ipamv1alpha1.IPQuota) ([]ipamv1alpha1.QuotaUsage, error) {
+	q, err := newQuota(cr)
+	if err != nil {
+		return nil, err
+	}
+	rt, err := r.getRoutingTable(&Allocation{NetworkInstance: cr.Spec.NetworkInstance}, false)
+	if err != nil {
+		return nil, err
+	}
+	usage := []ipamv1alpha1.QuotaUsage{}
+	for tenant, n := range getUsage(rt, q)[q.name] {
+		usage = append(usage, ipamv1alpha1.QuotaUsage{Tenant: tenant, Allocations: int32(n)})
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Tenant < usage[j].Tenant
+	})
+	return usage, nil
+}
+
+// checkQuotas returns ErrQuotaExceeded when a new allocation would exceed a
+// quota of the tenant of the allocation, existing allocations are refreshed.
+// The allocation lock is held until the allocation is inserted, such that the
+// usage cannot change in between.
+func (r *ipam) checkQuotas(alloc *Allocation) error {
+	r.qm.RLock()
+	defer r.qm.RUnlock()
+	if len(r.quotas) == 0 {
+		return nil
+	}
+
+	rt, err := r.getRoutingTable(alloc, false)
+	if err != nil {
+		return err
+	}
+	allocSelector, err := alloc.GetAllocSelector()
+	if err != nil {
+		return err
+	}
+	if len(rt.GetByLabel(allocSelector)) > 0 {
+		return nil
+	}
+
+	// the quotas of the tenant of the allocation, their usage is counted in a
+	// single pass over the routing table
+	quotas := []*quota{}
+	tenants := map[string]string{}
+	for _, name := range sortedKeys(r.quotas) {
+		q := r.quotas[name]
+		if q.spec.NetworkInstance != alloc.GetNetworkInstance() {
+			continue
+		}
+		tenant := q.getTenant(alloc.GetFullLabels())
+		if tenant == "" {
+			continue
+		}
+		applies, err := r.isInQuotaScope(rt, q, alloc)
+		if err != nil {
+			return err
+		}
+		if !applies {
+			continue
+		}
+		quotas = append(quotas, q)
+		tenants[q.name] = tenant
+	}
+	if len(quotas) == 0 {
+		return nil
+	}
+
+	usage := getUsage(rt, quotas...)
+	for _, q := range quotas {
+		tenant := tenants[q.name]
+		if n := usage[q.name][tenant]; n >= int(q.spec.MaxAllocations) {
+			return errors.Wrapf(ErrQuotaExceeded, "%s %s holds %d of %d allocations allowed by quota %s",
+				q.spec.TenantKind, tenant, n, q.spec.MaxAllocations, q.name)
+		}
+	}
+	return nil
+}
+
+// isInQuotaScope returns true if the allocation is counted by the quota; a
+// dynamic allocation is counted when it can be allocated from a prefix in the
+// scope of the quota
+func (r *ipam) isInQuotaScope(rt *table.RouteTable, q *quota, alloc *Allocation) (bool, error) {
+	if q.prefix == nil {
+		return true, nil
+	}
+	if alloc.Prefix != "" {
+		return q.contains(alloc.GetIPPrefix().Masked()), nil
+	}
+	labelSelector, err := alloc.GetLabelSelector()
+	if err != nil {
+		return false, err
+	}
+	for _, route := range rt.GetByLabel(labelSelector) {
+		if route.IPPrefix().Overlaps(*q.prefix) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
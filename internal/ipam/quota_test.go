@@ -0,0 +1,160 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hansthienpondt/goipam/pkg/table"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"inet.af/netaddr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestQuotaGetUsage(t *testing.T) {
+	// routes with the namespace and the name of their allocation
+	routes := []struct {
+		prefix    string
+		namespace string
+		name      string
+		labels    map[string]string
+	}{
+		{prefix: "10.0.0.0/24", namespace: "ns-1", name: "pool-1"},
+		{prefix: "10.0.1.0/24", namespace: "ns-1", name: "pool-2"},
+		// a network allocation is counted once
+		{prefix: "10.1.0.0/24", namespace: "ns-2", name: "net-1"},
+		{prefix: "10.1.0.1/32", namespace: "ns-2", name: "net-1"},
+		{prefix: "10.2.0.0/24", namespace: "ns-2", name: "pool-3",
+			labels: map[string]string{ipamv1alpha1.NephioOriginKey: string(ipamv1alpha1.OriginIPSystem)}},
+		{prefix: "10.3.0.0/24", namespace: "ns-2", name: "pool-4",
+			labels: map[string]string{ipamv1alpha1.NephioSourceNIKey: "vpc-shared"}},
+	}
+	cases := map[string]struct {
+		spec ipamv1alpha1.IPQuotaSpec
+		want map[string]int
+	}{
+		"Namespace": {
+			spec: ipamv1alpha1.IPQuotaSpec{TenantKind: ipamv1alpha1.QuotaTenantKindNamespace},
+			want: map[string]int{"ns-1": 2, "ns-2": 1},
+		},
+		"Tenant": {
+			spec: ipamv1alpha1.IPQuotaSpec{TenantKind: ipamv1alpha1.QuotaTenantKindNamespace, Tenant: "ns-1"},
+			want: map[string]int{"ns-1": 2},
+		},
+		"Prefix": {
+			spec: ipamv1alpha1.IPQuotaSpec{TenantKind: ipamv1alpha1.QuotaTenantKindNamespace, Prefix: "10.0.1.0/24"},
+			want: map[string]int{"ns-1": 1},
+		},
+		"Label": {
+			spec: ipamv1alpha1.IPQuotaSpec{TenantKind: ipamv1alpha1.QuotaTenantKindLabel, LabelKey: "team"},
+			want: map[string]int{},
+		},
+	}
+
+	rt := table.NewRouteTable()
+	for _, r := range routes {
+		route := table.NewRoute(netaddr.MustParseIPPrefix(r.prefix))
+		l := map[string]string{
+			ipamv1alpha1.NephioNamespaceKey:         r.namespace,
+			ipamv1alpha1.NephioIPAllocactionNameKey: r.name,
+		}
+		for k, v := range r.labels {
+			l[k] = v
+		}
+		route.UpdateLabel(l)
+		if err := rt.Add(route); err != nil {
+			t.Fatalf("cannot add route %s: %v", r.prefix, err)
+		}
+	}
+	// the usage of all quotas is counted in a single pass
+	quotas := []*quota{}
+	for name, tc := range cases {
+		q, err := newQuota(&ipamv1alpha1.IPQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       tc.spec,
+		})
+		if err != nil {
+			t.Fatalf("cannot create quota %s: %v", name, err)
+		}
+		quotas = append(quotas, q)
+	}
+	usage := getUsage(rt, quotas...)
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := usage[types.NamespacedName{Namespace: "default", Name: name}.String()]
+			if len(got) != len(tc.want) {
+				t.Fatalf("got usage %v, want %v", got, tc.want)
+			}
+			for tenant, n := range tc.want {
+				if got[tenant] != n {
+					t.Errorf("got usage %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckQuotasConcurrent(t *testing.T) {
+	r := newTestIpam(t, "vpc-1", "10.0.0.0/16")
+	if err := r.SetQuota(&ipamv1alpha1.IPQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "default"},
+		Spec: ipamv1alpha1.IPQuotaSpec{
+			NetworkInstance: "vpc-1",
+			TenantKind:      ipamv1alpha1.QuotaTenantKindNamespace,
+			MaxAllocations:  1,
+		},
+	}); err != nil {
+		t.Fatalf("cannot set quota: %v", err)
+	}
+
+	// concurrent allocations of a tenant cannot exceed the quota
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = r.AllocateIPPrefix(context.Background(), &Allocation{
+				NamespacedName:  types.NamespacedName{Namespace: "ns-1", Name: fmt.Sprintf("pool-%d", i)},
+				Origin:          ipamv1alpha1.OriginIPAllocation,
+				NetworkInstance: "vpc-1",
+				PrefixKind:      ipamv1alpha1.PrefixKindPool,
+				PrefixLength:    24,
+				SelectorLabels:  map[string]string{ipamv1alpha1.NephioNetworkInstanceKey: "vpc-1"},
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	allocated := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			allocated++
+		case !errors.Is(err, ErrQuotaExceeded):
+			t.Errorf("got error %v, want %v", err, ErrQuotaExceeded)
+		}
+	}
+	if allocated != 1 {
+		t.Errorf("got %d allocations, want 1", allocated)
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hansthienpondt/goipam/pkg/table"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DependentValidation is the outcome of the validation of a dependent of a
+// prefix that changed
+type DependentValidation struct {
+	ipamv1alpha1.PrefixDependent
+	// Message explains why the dependent is no longer valid, empty when valid
+	Message string
+	// Migrated indicates the dynamic allocation is released, such that it is
+	// allocated again in the prefixes it selects
+	Migrated bool
+}
+
+// RevalidateDependents validates the dependents of a prefix again after the
+// prefix changed. A dependent that is no longer covered by the prefix, or that
+// violates the nesting rules, stays allocated but fails every allocation until
+// a later change of the prefix makes it valid again. With migrate a dynamic
+// allocation that is no longer covered is released instead.
+func (r *ipam) RevalidateDependents(ctx context.Context, alloc *Allocation, dependents []ipamv1alpha1.PrefixDependent, migrate bool) ([]DependentValidation, error) {
+	r.l = log.FromContext(ctx)
+
+	rt, err := r.getRoutingTable(alloc, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// migrated dependents are deleted under the allocation lock, such that an
+	// allocation does not see them partially deleted
+	r.am.Lock()
+	result, err := r.revalidateDependents(rt, alloc, dependents, migrate)
+	r.am.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.updateNetworkInstanceStatus(ctx, &Allocation{NetworkInstance: alloc.GetNetworkInstance()}); err != nil {
+		return nil, err
+	}
+	if _, err := r.syncImportsIfNeeded(ctx); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *ipam) revalidateDependents(rt *table.RouteTable, alloc *Allocation, dependents []ipamv1alpha1.PrefixDependent, migrate bool) ([]DependentValidation, error) {
+	p := alloc.GetIPPrefix().Masked()
+
+	result := make([]DependentValidation, 0, len(dependents))
+	for _, d := range dependents {
+		// dependents mirrored from another network instance are validated there
+		if d.NetworkInstance != alloc.GetNetworkInstance() {
+			continue
+		}
+		routes, err := getDependentRoutes(rt, d)
+		if err != nil {
+			return nil, err
+		}
+		if len(routes) == 0 {
+			// the dependent got released in the meantime
+			r.setInvalid(alloc.GetNetworkInstance(), d, "")
+			continue
+		}
+		route := routes[0]
+
+		v := DependentValidation{PrefixDependent: d}
+		if route.IPPrefix().Bits() < p.Bits() || !p.Contains(route.IPPrefix().IP()) {
+			v.Message = fmt.Sprintf("parent prefix %s no longer covers %s", alloc.GetName(), route.IPPrefix().String())
+			if migrate && route.GetLabels().Get(ipamv1alpha1.NephioDynamicKey) == "true" {
+				for _, route := range routes {
+					if _, _, err := rt.Delete(route); err != nil {
+						return nil, err
+					}
+				}
+				v.Migrated = true
+				v.Message = fmt.Sprintf("parent prefix %s no longer covers %s, migrating", alloc.GetName(), route.IPPrefix().String())
+			}
+		} else {
+			v.Message = r.validateDependentNesting(rt, route)
+		}
+		r.l.Info("revalidate dependent", "name", d.Name, "prefix", route.IPPrefix().String(), "msg", v.Message, "migrated", v.Migrated)
+
+		if v.Migrated {
+			r.setInvalid(alloc.GetNetworkInstance(), d, "")
+		} else {
+			r.setInvalid(alloc.GetNetworkInstance(), d, v.Message)
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+// validateDependentNesting validates the prefixes the route of a dependent is
+// nested in against the nesting rules of its prefix kind; the routes the ipam
+// adds itself, like the net route of a network, are not considered
+func (r *ipam) validateDependentNesting(rt *table.RouteTable, route *table.Route) string {
+	kind := ipamv1alpha1.PrefixKind(route.GetLabels().Get(ipamv1alpha1.NephioPrefixKindKey))
+	r.vm.RLock()
+	fnc := r.validator[ipamUsage{PrefixKind: kind, HasPrefix: true}]
+	r.vm.RUnlock()
+	if fnc == nil || fnc.ParentExistFn == nil {
+		return ""
+	}
+	parents := table.Routes{}
+	for _, parent := range route.GetParents(rt) {
+		if parent.GetLabels().Get(ipamv1alpha1.NephioOriginKey) == string(ipamv1alpha1.OriginIPSystem) {
+			continue
+		}
+		parents = append(parents, parent)
+	}
+	return fnc.ParentExistFn(&Allocation{
+		PrefixKind: kind,
+		Prefix:     route.IPPrefix().String(),
+	}, parents)
+}
+
+// getDependentRoutes returns the routes of a dependent, ordered like the table
+func getDependentRoutes(rt *table.RouteTable, d ipamv1alpha1.PrefixDependent) (table.Routes, error) {
+	l := map[string]string{ipamv1alpha1.NephioIPAllocactionNameKey: d.Name}
+	if d.Namespace != "" {
+		l[ipamv1alpha1.NephioNamespaceKey] = d.Namespace
+	}
+	selector := labels.SelectorFromSet(l)
+	req, err := labels.NewRequirement(ipamv1alpha1.NephioSourceNIKey, selection.DoesNotExist, nil)
+	if err != nil {
+		return nil, err
+	}
+	return rt.GetByLabel(selector.Add(*req)), nil
+}
+
+// setInvalid records why a dependent is no longer valid, an empty message
+// removes the record
+func (r *ipam) setInvalid(niName string, d ipamv1alpha1.PrefixDependent, msg string) {
+	key := types.NamespacedName{Namespace: d.Namespace, Name: d.Name}.String()
+	r.invm.Lock()
+	defer r.invm.Unlock()
+	if msg == "" {
+		delete(r.invalid[niName], key)
+		return
+	}
+	if _, ok := r.invalid[niName]; !ok {
+		r.invalid[niName] = map[string]string{}
+	}
+	r.invalid[niName][key] = msg
+}
+
+// getInvalid returns why the allocation is no longer valid, empty when valid
+func (r *ipam) getInvalid(alloc *Allocation) string {
+	r.invm.RLock()
+	defer r.invm.RUnlock()
+	return r.invalid[alloc.GetNetworkInstance()][alloc.NamespacedName.String()]
+}
+
+// deleteInvalid removes the record of the allocation once it is released
+func (r *ipam) deleteInvalid(alloc *Allocation) {
+	r.invm.Lock()
+	defer r.invm.Unlock()
+	delete(r.invalid[alloc.GetNetworkInstance()], alloc.NamespacedName.String())
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"fmt"
+
+	"github.com/hansthienpondt/goipam/pkg/table"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/internal/utils/iputil"
+)
+
+// setSpec applies the configuration of the network instance to its allocations
+func (r *ipam) setSpec(cr *ipamv1alpha1.NetworkInstance) error {
+	for _, af := range cr.Spec.AddressFamilies {
+		if af != ipamv1alpha1.AddressFamilyIpv4 && af != ipamv1alpha1.AddressFamilyIpv6 {
+			return fmt.Errorf("unknown address family %s", string(af))
+		}
+	}
+	for _, d := range cr.Spec.DefaultPrefixLengths {
+		if !r.hasDefaultPrefixLength(d.PrefixKind) {
+			return fmt.Errorf("prefix kind %s cannot have a default prefix length", string(d.PrefixKind))
+		}
+	}
+	r.sm.Lock()
+	defer r.sm.Unlock()
+	r.specs[cr.GetName()] = cr.Spec.DeepCopy()
+	return nil
+}
+
+func (r *ipam) deleteSpec(niName string) {
+	r.sm.Lock()
+	defer r.sm.Unlock()
+	delete(r.specs, niName)
+}
+
+func (r *ipam) getSpec(niName string) *ipamv1alpha1.NetworkInstanceSpec {
+	r.sm.RLock()
+	defer r.sm.RUnlock()
+	if spec, ok := r.specs[niName]; ok {
+		return spec
+	}
+	return &ipamv1alpha1.NetworkInstanceSpec{}
+}
+
+// getAllocationStrategy returns the allocation strategy of the network
+// instance, the strategy of the ipam when the network instance has none
+func (r *ipam) getAllocationStrategy(niName string) ipamv1alpha1.AllocationStrategy {
+	if strategy := r.getSpec(niName).AllocationStrategy; strategy != "" {
+		return strategy
+	}
+	return r.strategy
+}
+
+// getDefaultPrefixLength returns the prefix length of a dynamic allocation that
+// does not request one, 0 when the network instance has no default
+func (r *ipam) getDefaultPrefixLength(niName string, kind ipamv1alpha1.PrefixKind, af ipamv1alpha1.AddressFamily) uint8 {
+	for _, d := range r.getSpec(niName).DefaultPrefixLengths {
+		if d.PrefixKind == kind && d.AddressFamily == af {
+			return d.PrefixLength
+		}
+	}
+	return 0
+}
+
+// hasDefaultPrefixLength returns true when the dynamic allocations of the
+// prefix kind can get their prefix length from the network instance; the
+// kind must be registered and allocated without a prefix, networks, links
+// and aggregates size their prefixes themselves
+func (r *ipam) hasDefaultPrefixLength(kind ipamv1alpha1.PrefixKind) bool {
+	switch kind {
+	case ipamv1alpha1.PrefixKindNetwork, ipamv1alpha1.PrefixKindLink, ipamv1alpha1.PrefixKindAggregate:
+		return false
+	}
+	r.im.RLock()
+	defer r.im.RUnlock()
+	_, ok := r.insertor[ipamUsage{PrefixKind: kind, HasPrefix: false}]
+	return ok
+}
+
+// getAllowedRoutes returns the routes of the address families the network
+// instance allows
+func (r *ipam) getAllowedRoutes(niName string, routes table.Routes) table.Routes {
+	spec := r.getSpec(niName)
+	if len(spec.AddressFamilies) == 0 {
+		return routes
+	}
+	allowed := make(table.Routes, 0, len(routes))
+	for _, route := range routes {
+		if isAddressFamilyAllowed(spec, iputil.GetAddressFamily(route.IPPrefix())) {
+			allowed = append(allowed, route)
+		}
+	}
+	return allowed
+}
+
+// validateSpec validates the allocation against the address families and the
+// maximum number of allocations of the network instance
+func (r *ipam) validateSpec(alloc *Allocation) error {
+	spec := r.getSpec(alloc.GetNetworkInstance())
+
+	af := alloc.GetAddressFamily()
+	if alloc.Prefix != "" {
+		af = iputil.GetAddressFamily(alloc.GetIPPrefix())
+	}
+	if af != "" && !isAddressFamilyAllowed(spec, af) {
+		return newError(ErrValidationFailed, fmt.Sprintf("address family %s is not allowed in network instance %s, allowed address families: %v",
+			string(af), alloc.GetNetworkInstance(), spec.AddressFamilies))
+	}
+
+	if spec.MaxAllocations == nil {
+		return nil
+	}
+	rt, err := r.getRoutingTable(alloc, false)
+	if err != nil {
+		return err
+	}
+	allocSelector, err := alloc.GetAllocSelector()
+	if err != nil {
+		return err
+	}
+	// an existing allocation is refreshed and does not count against the maximum
+	if len(rt.GetByLabel(allocSelector)) > 0 {
+		return nil
+	}
+	if n := countAllocations(rt); n >= int(*spec.MaxAllocations) {
+		return newError(ErrQuotaExceeded, fmt.Sprintf("network instance %s reached the maximum number of allocations: %d",
+			alloc.GetNetworkInstance(), *spec.MaxAllocations))
+	}
+	return nil
+}
+
+func isAddressFamilyAllowed(spec *ipamv1alpha1.NetworkInstanceSpec, af ipamv1alpha1.AddressFamily) bool {
+	if len(spec.AddressFamilies) == 0 {
+		return true
+	}
+	for _, allowed := range spec.AddressFamilies {
+		if allowed == af {
+			return true
+		}
+	}
+	return false
+}
+
+// countAllocations returns the number of prefixes and allocations in the
+// routing table, routes the ipam adds and routes of other network instances
+// are not counted
+func countAllocations(rt *table.RouteTable) int {
+	names := map[string]struct{}{}
+	for _, route := range rt.GetTable() {
+		l := route.GetLabels()
+		if l.Get(ipamv1alpha1.NephioOriginKey) == string(ipamv1alpha1.OriginIPSystem) ||
+			l.Get(ipamv1alpha1.NephioSourceNIKey) != "" {
+			continue
+		}
+		names[l.Get(ipamv1alpha1.NephioIPAllocactionNameKey)] = struct{}{}
+	}
+	return len(names)
+}
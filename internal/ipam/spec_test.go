@@ -0,0 +1,75 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"testing"
+
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetSpecDefaultPrefixLength(t *testing.T) {
+	vip := ipamv1alpha1.PrefixKind("vip")
+	cases := map[string]struct {
+		opts    []Option
+		kind    ipamv1alpha1.PrefixKind
+		wantErr bool
+	}{
+		"Pool": {
+			kind: ipamv1alpha1.PrefixKindPool,
+		},
+		"Loopback": {
+			kind: ipamv1alpha1.PrefixKindLoopback,
+		},
+		"Network": {
+			kind:    ipamv1alpha1.PrefixKindNetwork,
+			wantErr: true,
+		},
+		"Aggregate": {
+			kind:    ipamv1alpha1.PrefixKindAggregate,
+			wantErr: true,
+		},
+		"Unregistered": {
+			kind:    vip,
+			wantErr: true,
+		},
+		"Registered": {
+			opts: []Option{WithPrefixKind(vip, func(h Handlers) *PrefixKindConfig {
+				return &PrefixKindConfig{}
+			})},
+			kind: vip,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := New(nil, tc.opts...).(*ipam)
+			err := r.setSpec(&ipamv1alpha1.NetworkInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "vpc-1", Namespace: "default"},
+				Spec: ipamv1alpha1.NetworkInstanceSpec{
+					DefaultPrefixLengths: []ipamv1alpha1.DefaultPrefixLength{
+						{PrefixKind: tc.kind, AddressFamily: ipamv1alpha1.AddressFamilyIpv4, PrefixLength: 28},
+					},
+				},
+			})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error %t", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"github.com/hansthienpondt/goipam/pkg/table"
+	"inet.af/netaddr"
+)
+
+// getChildren returns the routes nested in the prefix. RouteTable.Children
+// stops walking at the first route that shares the leading bytes of the
+// prefix without being nested in it, so it can miss routes.
+func getChildren(rt *table.RouteTable, p netaddr.IPPrefix) table.Routes {
+	routes := table.Routes{}
+	for _, route := range rt.GetTable() {
+		if route.IPPrefix().Bits() > p.Bits() && p.Contains(route.IPPrefix().IP()) {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// getFreeSet returns the addresses of the prefix that are not used by a nested route
+func getFreeSet(rt *table.RouteTable, p netaddr.IPPrefix) (*netaddr.IPSet, bool) {
+	var b netaddr.IPSetBuilder
+	b.AddPrefix(p)
+	for _, route := range getChildren(rt, p) {
+		b.RemovePrefix(route.IPPrefix())
+	}
+	s, err := b.IPSet()
+	if err != nil {
+		return nil, false
+	}
+	return s, true
+}
+
+// getFreePrefixes returns the free blocks of the prefix ordered by address
+func getFreePrefixes(rt *table.RouteTable, p netaddr.IPPrefix) ([]netaddr.IPPrefix, bool) {
+	s, ok := getFreeSet(rt, p)
+	if !ok {
+		return nil, false
+	}
+	return s.Prefixes(), true
+}
+
+// getFreePrefix returns a free prefix of the prefix length in the prefix
+func getFreePrefix(rt *table.RouteTable, p netaddr.IPPrefix, prefixLength uint8) (netaddr.IPPrefix, bool) {
+	s, ok := getFreeSet(rt, p)
+	if !ok {
+		return netaddr.IPPrefix{}, false
+	}
+	pfx, _, ok := s.RemoveFreePrefix(prefixLength)
+	return pfx, ok
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipam
+
+import (
+	"testing"
+
+	"github.com/hansthienpondt/goipam/pkg/table"
+	"inet.af/netaddr"
+)
+
+func newTestRouteTable(t *testing.T, prefixes ...string) *table.RouteTable {
+	t.Helper()
+	rt := table.NewRouteTable()
+	for _, p := range prefixes {
+		if err := rt.Add(table.NewRoute(netaddr.MustParseIPPrefix(p))); err != nil {
+			t.Fatalf("cannot add route %s: %v", p, err)
+		}
+	}
+	return rt
+}
+
+func TestGetFreePrefix(t *testing.T) {
+	cases := map[string]struct {
+		routes       []string
+		prefix       string
+		prefixLength uint8
+		wantChildren int
+		wantFree     []string
+		wantPrefix   string
+	}{
+		"Empty": {
+			routes:       []string{"10.0.0.0/24"},
+			prefix:       "10.0.0.0/24",
+			prefixLength: 26,
+			wantChildren: 0,
+			wantFree:     []string{"10.0.0.0/24"},
+			wantPrefix:   "10.0.0.0/26",
+		},
+		"Nested": {
+			routes:       []string{"10.0.0.0/24", "10.0.0.0/26"},
+			prefix:       "10.0.0.0/24",
+			prefixLength: 26,
+			wantChildren: 1,
+			wantFree:     []string{"10.0.0.64/26", "10.0.0.128/25"},
+			wantPrefix:   "10.0.0.64/26",
+		},
+		"Full": {
+			routes:       []string{"10.0.0.0/24", "10.0.0.0/25", "10.0.0.128/25"},
+			prefix:       "10.0.0.0/24",
+			prefixLength: 26,
+			wantChildren: 2,
+			wantFree:     []string{},
+		},
+		// RouteTable.Children misses 10.1.2.0/25 since the walk stops at
+		// 10.1.0.0/22, which shares the leading bytes of 10.1.2.0/23
+		"SiblingSharesLeadingBytes": {
+			routes:       []string{"10.1.0.0/22", "10.1.2.0/23", "10.1.2.0/25"},
+			prefix:       "10.1.2.0/23",
+			prefixLength: 25,
+			wantChildren: 1,
+			wantFree:     []string{"10.1.2.128/25", "10.1.3.0/24"},
+			wantPrefix:   "10.1.2.128/25",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rt := newTestRouteTable(t, tc.routes...)
+			p := netaddr.MustParseIPPrefix(tc.prefix)
+
+			if got := getChildren(rt, p); len(got) != tc.wantChildren {
+				t.Errorf("getChildren(%s): got %v, want %d routes", tc.prefix, got, tc.wantChildren)
+			}
+
+			free, ok := getFreePrefixes(rt, p)
+			if !ok {
+				t.Fatalf("getFreePrefixes(%s): not ok", tc.prefix)
+			}
+			if len(free) != len(tc.wantFree) {
+				t.Fatalf("getFreePrefixes(%s): got %v, want %v", tc.prefix, free, tc.wantFree)
+			}
+			for i := range free {
+				if free[i].String() != tc.wantFree[i] {
+					t.Errorf("getFreePrefixes(%s): got %v, want %v", tc.prefix, free, tc.wantFree)
+				}
+			}
+
+			got, ok := getFreePrefix(rt, p, tc.prefixLength)
+			if tc.wantPrefix == "" {
+				if ok {
+					t.Errorf("getFreePrefix(%s, %d): got %s, want none", tc.prefix, tc.prefixLength, got)
+				}
+				return
+			}
+			if !ok || got.String() != tc.wantPrefix {
+				t.Errorf("getFreePrefix(%s, %d): got %s, want %s", tc.prefix, tc.prefixLength, got, tc.wantPrefix)
+			}
+		})
+	}
+}
@@ -27,10 +27,16 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-func (r *ipam) validate(ctx context.Context, alloc *Allocation) (string, error) {
+func (r *ipam) validate(ctx context.Context, alloc *Allocation) error {
 	r.vm.Lock()
 	validateFnCfg := r.validator[ipamUsage{PrefixKind: ipamv1alpha1.PrefixKind(alloc.PrefixKind), HasPrefix: alloc.Prefix != ""}]
 	r.vm.Unlock()
+	if validateFnCfg == nil {
+		return newError(ErrValidationFailed, fmt.Sprintf("unknown prefix kind %s", alloc.PrefixKind))
+	}
+	if err := r.validateSpec(alloc); err != nil {
+		return err
+	}
 
 	if alloc.Prefix != "" {
 		return r.validatePrefix(ctx, alloc, validateFnCfg)
@@ -42,44 +48,51 @@ type ValidateInputFn func(alloc *Allocation) string
 type IsAddressFn func(alloc *Allocation) string
 type IsAddressInNetFn func(alloc *Allocation) string
 type ExactPrefixMatchFn func(alloc *Allocation, route *table.Route) string
-type ChildrenExistFn func(alloc *Allocation, route *table.Route) string
-type ParentExistFn func(alloc *Allocation, route *table.Route) string
+type ChildrenExistFn func(alloc *Allocation, routes table.Routes, dryrunrt *table.RouteTable) string
+type ParentExistFn func(alloc *Allocation, routes table.Routes) string
 type FinalValidationFn func(alloc *Allocation, dryrunrt *table.RouteTable) string
 
 type ValidationConfig struct {
-	ValidateInputFn    ValidateInputFn
-	IsAddressFn        IsAddressFn
-	IsAddressInNetFn   IsAddressInNetFn
-	ExactPrefixMatchFn ExactPrefixMatchFn
-	ChildrenExistFn    ChildrenExistFn
-	ParentExistFn      ParentExistFn
-	FinalValidationFn  FinalValidationFn
+	ValidateInputFn     ValidateInputFn
+	IsAddressFn         IsAddressFn
+	IsAddressInNetFn    IsAddressInNetFn
+	GatewayValidationFn GatewayValidationFn
+	ExactPrefixMatchFn  ExactPrefixMatchFn
+	ChildrenExistFn     ChildrenExistFn
+	ParentExistFn       ParentExistFn
+	FinalValidationFn   FinalValidationFn
 }
 
-func (r *ipam) validatePrefix(ctx context.Context, alloc *Allocation, fnc *ValidationConfig) (string, error) {
+func (r *ipam) validatePrefix(ctx context.Context, alloc *Allocation, fnc *ValidationConfig) error {
 	r.l = log.FromContext(ctx)
 	r.l.Info("validate prefix", "cr", alloc.GetName(), "prefix", alloc.GetPrefix())
 
 	dryrunrt, err := r.getRoutingTable(alloc, true)
 	if err != nil {
-		return "", err
+		return err
 	}
 
 	if msg := fnc.ValidateInputFn(alloc); msg != "" {
-		return msg, nil
+		return newError(ErrValidationFailed, msg)
 	}
 	if msg := fnc.IsAddressFn(alloc); msg != "" {
-		return msg, nil
+		return newError(ErrValidationFailed, msg)
 	}
 	if msg := fnc.IsAddressInNetFn(alloc); msg != "" {
-		return msg, nil
+		return newError(ErrValidationFailed, msg)
+	}
+	if msg := fnc.GatewayValidationFn(alloc, dryrunrt); msg != "" {
+		return newError(ErrConflict, msg)
+	}
+	if msg := validateImportOverlap(alloc, dryrunrt); msg != "" {
+		return newError(ErrOverlap, msg)
 	}
 	route, ok, err := dryrunrt.Get(alloc.GetIPPrefix())
 	if err != nil {
-		return "", err
+		return err
 	}
 	if ok {
-		return fnc.ExactPrefixMatchFn(alloc, route), nil
+		return newError(ErrConflict, fnc.ExactPrefixMatchFn(alloc, route))
 	}
 	// exact prefix does not exist, create it for validation
 	p := alloc.GetIPPrefix()
@@ -95,42 +108,39 @@ func (r *ipam) validatePrefix(ctx context.Context, alloc *Allocation, fnc *Valid
 		ipamv1alpha1.NephioNetworkKey:           p.Masked().IP().String(),
 	})
 	if err := dryrunrt.Add(route); err != nil {
-		return "", err
+		return err
 	}
 	// get the route again and check for children
 	route, _, err = dryrunrt.Get(p)
 	if err != nil {
-		return "", err
+		return err
 	}
-	routes := route.GetChildren(dryrunrt)
+	routes := getChildren(dryrunrt, route.IPPrefix())
 	if len(routes) > 0 {
 		r.l.Info("got children", "routes", routes)
 
-		if msg := fnc.ChildrenExistFn(alloc, routes[0]); msg != "" {
-			return msg, nil
+		if msg := fnc.ChildrenExistFn(alloc, routes, dryrunrt); msg != "" {
+			return newError(ErrInvalidNesting, msg)
 		}
 	}
-	routes = route.GetParents(dryrunrt)
-	for _, route := range routes {
-		if msg := fnc.ParentExistFn(alloc, route); msg != "" {
-			return msg, nil
-		}
+	if msg := fnc.ParentExistFn(alloc, route.GetParents(dryrunrt)); msg != "" {
+		return newError(ErrInvalidNesting, msg)
 	}
 	if msg := fnc.FinalValidationFn(alloc, dryrunrt); msg != "" {
-		return msg, nil
+		return newError(ErrOverlap, msg)
 	}
-	return "", nil
+	return nil
 }
 
-func (r *ipam) validateAlloc(ctx context.Context, alloc *Allocation, fnc *ValidationConfig) (string, error) {
+func (r *ipam) validateAlloc(ctx context.Context, alloc *Allocation, fnc *ValidationConfig) error {
 	r.l = log.FromContext(ctx)
 	r.l.Info("validate w/o prefix", "cr", alloc.GetName(), "prefix", alloc.GetPrefix())
 
 	if msg := fnc.ValidateInputFn(alloc); msg != "" {
-		return msg, nil
+		return newError(ErrValidationFailed, msg)
 	}
 
-	return "", nil
+	return nil
 }
 
 func ValidateInputNopFn(alloc *Allocation) string { return "" }
@@ -202,68 +212,6 @@ func ExactPrefixMatchGenericFn(alloc *Allocation, route *table.Route) string {
 	return ""
 }
 
-func ChildrenExistNopFn(alloc *Allocation, route *table.Route) string { return "" }
-
-func ChildrenExistLoopbackFn(alloc *Allocation, route *table.Route) string {
-	return fmt.Sprintf("a more specific prefix was already allocated %s, loopbacks need to be created in hierarchy",
-		route.GetLabels().Get(ipamv1alpha1.NephioIPAllocactionNameKey))
-}
-
-func ChildrenExistGenericFn(alloc *Allocation, route *table.Route) string {
-	return fmt.Sprintf("a more specific prefix was already allocated %s, nesting not allowed for %s",
-		route.GetLabels().Get(ipamv1alpha1.NephioIPAllocactionNameKey),
-		alloc.GetPrefixKind(),
-	)
-}
-
-func ParentExistNetworkFn(alloc *Allocation, route *table.Route) string {
-	// if the parent is not an aggregate we dont allow the prefix to eb create
-	if route.GetLabels().Get(ipamv1alpha1.NephioPrefixKindKey) != string(ipamv1alpha1.PrefixKindAggregate) {
-		return fmt.Sprintf("nesting network prefixes with anything other than an aggregate prefix is not allowed, prefix nested with %s of kind %s",
-			route.GetLabels().Get(ipamv1alpha1.NephioIPAllocactionNameKey),
-			route.GetLabels().Get(ipamv1alpha1.NephioPrefixKindKey),
-		)
-	}
-	return ""
-}
-
-func ParentExistLoopbackFn(alloc *Allocation, route *table.Route) string {
-	p := alloc.GetIPPrefix()
-	// if the parent is not an aggregate we dont allow the prefix to eb create
-	if route.GetLabels().Get(ipamv1alpha1.NephioPrefixKindKey) != string(ipamv1alpha1.PrefixKindAggregate) ||
-		route.GetLabels().Get(ipamv1alpha1.NephioPrefixKindKey) != string(ipamv1alpha1.PrefixKindLoopback) {
-		return fmt.Sprintf("nesting loopback prefixes with anything other than an aggregate/loopback prefix is not allowed, prefix nested with %s",
-			route.GetLabels().Get(ipamv1alpha1.NephioIPAllocactionNameKey))
-	}
-	if route.GetLabels().Get(ipamv1alpha1.NephioPrefixKindKey) != string(ipamv1alpha1.PrefixKindLoopback) && !iputil.IsAddress(p) {
-		return fmt.Sprintf("nesting loopback prefixes only possible with address (/32, /128) based prefixes, got %s", p.String())
-	}
-	return ""
-}
-
-func ParentExistPoolFn(alloc *Allocation, route *table.Route) string {
-	p := alloc.GetIPPrefix()
-	// if the parent is not an aggregate we dont allow the prefix to eb create
-	if route.GetLabels().Get(ipamv1alpha1.NephioPrefixKindKey) != string(ipamv1alpha1.PrefixKindAggregate) ||
-		route.GetLabels().Get(ipamv1alpha1.NephioPrefixKindKey) != string(ipamv1alpha1.PrefixKindPool) {
-		return fmt.Sprintf("nesting loopback prefixes with anything other than an aggregate/pool prefix is not allowed, prefix nested with %s",
-			route.GetLabels().Get(ipamv1alpha1.NephioIPAllocactionNameKey))
-	}
-	if route.GetLabels().Get(ipamv1alpha1.NephioPrefixKindKey) != string(ipamv1alpha1.PrefixKindLoopback) && !iputil.IsAddress(p) {
-		return fmt.Sprintf("nesting loopback prefixes only possible with address (/32, /128) based prefixes, got %s", p.String())
-	}
-	return ""
-}
-
-func ParentExistAggregateFn(alloc *Allocation, route *table.Route) string {
-	// if the parent is not an aggregate we dont allow the prefix to eb create
-	if route.GetLabels().Get(ipamv1alpha1.NephioPrefixKindKey) != string(ipamv1alpha1.PrefixKindAggregate) {
-		return fmt.Sprintf("nesting aggregate prefixes with anything other than an aggregate prefix is not allowed, prefix nested with %s",
-			route.GetLabels().Get(ipamv1alpha1.NephioIPAllocactionNameKey))
-	}
-	return ""
-}
-
 func FinalValidationNopFn(alloc *Allocation, dryrunrt *table.RouteTable) string { return "" }
 
 func FinalValidationNetworkFn(alloc *Allocation, dryrunrt *table.RouteTable) string {
@@ -273,6 +221,10 @@ func FinalValidationNetworkFn(alloc *Allocation, dryrunrt *table.RouteTable) str
 	}
 	routes := dryrunrt.GetByLabel(l)
 	for _, route := range routes {
+		// a network can span multiple prefixes, as long as they do not overlap
+		if !route.IPPrefix().Overlaps(alloc.GetIPPrefix().Masked()) {
+			continue
+		}
 		net := route.GetLabels().Get(ipamv1alpha1.NephioNetworkKey)
 		prefixLength := route.GetLabels().Get(ipamv1alpha1.NephioPrefixLengthKey)
 		if route.GetLabels().Get(ipamv1alpha1.NephioParentPrefixLengthKey) != "" {
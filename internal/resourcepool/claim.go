@@ -0,0 +1,78 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcepool
+
+import (
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/nokia/k8s-ipam/pkg/alloc/allocpb"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Claim is a request for an id of a resource pool
+type Claim struct {
+	NamespacedName types.NamespacedName      `json:"namespacedName,omitempty"`
+	Kind           ipamv1alpha1.ResourceKind `json:"kind,omitempty"`
+	ID             uint32                    `json:"id,omitempty"`
+	SelectorLabels map[string]string         `json:"selectorLabels,omitempty"`
+}
+
+type AllocatedID struct {
+	ID   uint32
+	Pool string
+}
+
+func (r *Claim) GetName() string {
+	return r.NamespacedName.Name
+}
+
+func (r *Claim) GetLabelSelector() (labels.Selector, error) {
+	fullselector := labels.NewSelector()
+	for k, v := range r.SelectorLabels {
+		req, err := labels.NewRequirement(k, selection.In, []string{v})
+		if err != nil {
+			return nil, err
+		}
+		fullselector = fullselector.Add(*req)
+	}
+	return fullselector, nil
+}
+
+func BuildClaimFromResourceClaim(cr *ipamv1alpha1.ResourceClaim) *Claim {
+	claim := &Claim{
+		NamespacedName: cr.GetNamespacedName(),
+		Kind:           ipamv1alpha1.ResourceKind(cr.Spec.Kind),
+		ID:             cr.Spec.ID,
+	}
+	if cr.Spec.Selector != nil {
+		claim.SelectorLabels = cr.Spec.Selector.MatchLabels
+	}
+	return claim
+}
+
+func BuildClaimFromGRPCAlloc(alloc *allocpb.Request) *Claim {
+	return &Claim{
+		NamespacedName: types.NamespacedName{
+			Name:      alloc.GetName(),
+			Namespace: alloc.GetNamespace(),
+		},
+		Kind:           ipamv1alpha1.ResourceKind(alloc.GetKind()),
+		ID:             alloc.GetSpec().GetId(),
+		SelectorLabels: alloc.GetSpec().GetSelector(),
+	}
+}
@@ -0,0 +1,319 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package resourcepool allocates integer resources, like vlan ids, vxlan
+// vnis and private asns, from label selected pools
+package resourcepool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var (
+	// ErrPoolExhausted is returned when the selected pools have no free id,
+	// or the requested id is not available
+	ErrPoolExhausted = errors.New("pool exhausted")
+	// ErrNotFound is returned when no pool matches the claim, or no id is
+	// allocated to the claim
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when the claim already has another id allocated
+	ErrConflict = errors.New("conflict")
+)
+
+type Pools interface {
+	// Init creates or updates the pool and restores its allocations
+	Init(ctx context.Context, cr *ipamv1alpha1.ResourcePool) error
+	// Delete the pool
+	Delete(name types.NamespacedName)
+	// Allocate allocates an id for the claim
+	Allocate(ctx context.Context, claim *Claim) (*AllocatedID, error)
+	// DeAllocate releases the id of the claim
+	DeAllocate(ctx context.Context, claim *Claim) error
+	// Get returns the id allocated to the claim
+	Get(ctx context.Context, claim *Claim) (*AllocatedID, error)
+}
+
+// kindRanges are the ids that can be used per resource kind, a pool must be
+// within one of the ranges of its kind
+var kindRanges = map[ipamv1alpha1.ResourceKind][][2]uint32{
+	ipamv1alpha1.ResourceKindVLAN: {{1, 4094}},
+	ipamv1alpha1.ResourceKindVNI:  {{1, 16777215}},
+	// private asns, RFC 6996
+	ipamv1alpha1.ResourceKindASN: {{64512, 65534}, {4200000000, 4294967294}},
+}
+
+func New(c client.Client) Pools {
+	return &pools{
+		c:     c,
+		pools: map[string]*pool{},
+	}
+}
+
+type pools struct {
+	c client.Client
+	m sync.Mutex
+	// pools are keyed by the namespaced name of the ResourcePool
+	pools map[string]*pool
+
+	l logr.Logger
+}
+
+type pool struct {
+	name        types.NamespacedName
+	kind        ipamv1alpha1.ResourceKind
+	start       uint32
+	end         uint32
+	labels      labels.Set
+	allocations map[uint32]string
+}
+
+// Init creates or updates the pool and restores its allocations
+func (r *pools) Init(ctx context.Context, cr *ipamv1alpha1.ResourcePool) error {
+	r.l = log.FromContext(ctx)
+	kind := ipamv1alpha1.ResourceKind(cr.Spec.Kind)
+	if err := validateRange(kind, cr.Spec.Start, cr.Spec.End); err != nil {
+		return err
+	}
+
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if p, ok := r.pools[cr.GetNamespacedName().String()]; ok {
+		for id, claim := range p.allocations {
+			if id < cr.Spec.Start || id > cr.Spec.End {
+				return fmt.Errorf("cannot change the range of pool %s, id %d is allocated to %s", cr.GetName(), id, claim)
+			}
+		}
+		p.start = cr.Spec.Start
+		p.end = cr.Spec.End
+		p.labels = labels.Set(cr.GetLabels())
+		return nil
+	}
+
+	r.l.Info("pool action", "action", "initialize", "name", cr.GetName())
+	p := &pool{
+		name:        cr.GetNamespacedName(),
+		kind:        kind,
+		start:       cr.Spec.Start,
+		end:         cr.Spec.End,
+		labels:      labels.Set(cr.GetLabels()),
+		allocations: map[uint32]string{},
+	}
+	// restore the allocations of the claims that still exist
+	claims := map[string]struct{}{}
+	if r.c != nil {
+		claimList := &ipamv1alpha1.ResourceClaimList{}
+		if err := r.c.List(ctx, claimList); err != nil {
+			return errors.Wrap(err, "cannot get resource claim list")
+		}
+		for _, claim := range claimList.Items {
+			claims[claim.GetNamespacedName().String()] = struct{}{}
+		}
+	}
+	for idStr, claim := range cr.Status.Allocations {
+		if _, ok := claims[claim]; !ok && r.c != nil {
+			continue
+		}
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			return errors.Wrapf(err, "invalid id %s in pool %s", idStr, cr.GetName())
+		}
+		p.allocations[uint32(id)] = claim
+	}
+	r.pools[cr.GetNamespacedName().String()] = p
+	return nil
+}
+
+// Delete the pool
+func (r *pools) Delete(name types.NamespacedName) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	delete(r.pools, name.String())
+}
+
+// Allocate allocates an id for the claim from the first selected pool
+// that has the requested id or a free id available
+func (r *pools) Allocate(ctx context.Context, claim *Claim) (*AllocatedID, error) {
+	r.l = log.FromContext(ctx)
+	r.l.Info("allocate id", "claim", claim)
+
+	r.m.Lock()
+	if p, id, ok := r.find(claim); ok {
+		r.m.Unlock()
+		if claim.ID != 0 && claim.ID != id {
+			return nil, fmt.Errorf("%w: claim %s already has id %d allocated from pool %s", ErrConflict, claim.GetName(), id, p.name.Name)
+		}
+		return &AllocatedID{ID: id, Pool: p.name.Name}, nil
+	}
+
+	selected, err := r.selectPools(claim)
+	if err != nil {
+		r.m.Unlock()
+		return nil, err
+	}
+	var allocated *AllocatedID
+	var p *pool
+	for _, p = range selected {
+		if id, ok := p.allocate(claim); ok {
+			allocated = &AllocatedID{ID: id, Pool: p.name.Name}
+			break
+		}
+	}
+	r.m.Unlock()
+	if allocated == nil {
+		if claim.ID != 0 {
+			return nil, fmt.Errorf("%w: %s %d is not available in the selected pools", ErrPoolExhausted, claim.Kind, claim.ID)
+		}
+		return nil, fmt.Errorf("%w: no free %s available in the selected pools", ErrPoolExhausted, claim.Kind)
+	}
+	return allocated, r.updatePoolStatus(ctx, p)
+}
+
+// DeAllocate releases the id of the claim
+func (r *pools) DeAllocate(ctx context.Context, claim *Claim) error {
+	r.l = log.FromContext(ctx)
+	r.l.Info("deallocate id", "claim", claim)
+
+	r.m.Lock()
+	p, id, ok := r.find(claim)
+	if !ok {
+		r.m.Unlock()
+		return nil
+	}
+	delete(p.allocations, id)
+	r.m.Unlock()
+	return r.updatePoolStatus(ctx, p)
+}
+
+// Get returns the id allocated to the claim
+func (r *pools) Get(ctx context.Context, claim *Claim) (*AllocatedID, error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	p, id, ok := r.find(claim)
+	if !ok {
+		return nil, fmt.Errorf("%w: no %s allocated for claim %s", ErrNotFound, claim.Kind, claim.GetName())
+	}
+	return &AllocatedID{ID: id, Pool: p.name.Name}, nil
+}
+
+// find returns the pool and id allocated to the claim, the caller must
+// hold the lock
+func (r *pools) find(claim *Claim) (*pool, uint32, bool) {
+	for _, p := range r.pools {
+		if p.kind != claim.Kind {
+			continue
+		}
+		for id, owner := range p.allocations {
+			if owner == claim.NamespacedName.String() {
+				return p, id, true
+			}
+		}
+	}
+	return nil, 0, false
+}
+
+// selectPools returns the pools of the claimed kind matching the selector
+// of the claim, ordered by namespace and name; the caller must hold the lock
+func (r *pools) selectPools(claim *Claim) ([]*pool, error) {
+	selector, err := claim.GetLabelSelector()
+	if err != nil {
+		return nil, err
+	}
+	selected := []*pool{}
+	for _, p := range r.pools {
+		if p.kind == claim.Kind && selector.Matches(p.labels) {
+			selected = append(selected, p)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("%w: no %s pool found for selector %q", ErrNotFound, claim.Kind, selector.String())
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].name.String() < selected[j].name.String()
+	})
+	return selected, nil
+}
+
+func (r *pools) updatePoolStatus(ctx context.Context, p *pool) error {
+	if r.c == nil {
+		return nil
+	}
+	cr := &ipamv1alpha1.ResourcePool{}
+	if err := r.c.Get(ctx, p.name, cr); err != nil {
+		return errors.Wrap(err, "cannot get resource pool")
+	}
+
+	// always reinitialize the allocations based on latest info
+	r.m.Lock()
+	cr.Status.Allocations = make(map[string]string, len(p.allocations))
+	for id, claim := range p.allocations {
+		cr.Status.Allocations[strconv.FormatUint(uint64(id), 10)] = claim
+	}
+	r.m.Unlock()
+	return errors.Wrap(r.c.Status().Update(ctx, cr), "cannot update resource pool status")
+}
+
+// allocate assigns the requested or the first free id of the pool
+func (p *pool) allocate(claim *Claim) (uint32, bool) {
+	if claim.ID != 0 {
+		if claim.ID < p.start || claim.ID > p.end {
+			return 0, false
+		}
+		if _, ok := p.allocations[claim.ID]; ok {
+			return 0, false
+		}
+		p.allocations[claim.ID] = claim.NamespacedName.String()
+		return claim.ID, true
+	}
+	for id := p.start; id <= p.end; id++ {
+		if _, ok := p.allocations[id]; !ok {
+			p.allocations[id] = claim.NamespacedName.String()
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+func validateRange(kind ipamv1alpha1.ResourceKind, start, end uint32) error {
+	ranges, ok := kindRanges[kind]
+	if !ok {
+		return fmt.Errorf("unsupported resource kind %s", kind)
+	}
+	if start > end {
+		return fmt.Errorf("start %d of the pool is larger than the end %d", start, end)
+	}
+	allowed := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		if start >= r[0] && end <= r[1] {
+			return nil
+		}
+		allowed = append(allowed, fmt.Sprintf("%d-%d", r[0], r[1]))
+	}
+	return fmt.Errorf("%s pool must be within %s, got %d-%d", kind, strings.Join(allowed, " or "), start, end)
+}
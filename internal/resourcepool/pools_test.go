@@ -0,0 +1,125 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcepool
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestValidateRange(t *testing.T) {
+	cases := map[string]struct {
+		kind       ipamv1alpha1.ResourceKind
+		start, end uint32
+		wantErr    bool
+	}{
+		"VLAN":              {kind: ipamv1alpha1.ResourceKindVLAN, start: 1, end: 4094},
+		"VLANReserved":      {kind: ipamv1alpha1.ResourceKindVLAN, start: 4000, end: 4095, wantErr: true},
+		"VLANZero":          {kind: ipamv1alpha1.ResourceKindVLAN, start: 0, end: 10, wantErr: true},
+		"VNI":               {kind: ipamv1alpha1.ResourceKindVNI, start: 1, end: 16777215},
+		"VNITooLarge":       {kind: ipamv1alpha1.ResourceKindVNI, start: 1, end: 16777216, wantErr: true},
+		"PrivateASN":        {kind: ipamv1alpha1.ResourceKindASN, start: 64512, end: 65534},
+		"PrivateFourByteAS": {kind: ipamv1alpha1.ResourceKindASN, start: 4200000000, end: 4294967294},
+		"PublicASN":         {kind: ipamv1alpha1.ResourceKindASN, start: 64000, end: 64600, wantErr: true},
+		"AcrossASNRanges":   {kind: ipamv1alpha1.ResourceKindASN, start: 65000, end: 4200000000, wantErr: true},
+		"StartAfterEnd":     {kind: ipamv1alpha1.ResourceKindVLAN, start: 10, end: 1, wantErr: true},
+		"UnknownKind":       {kind: "mpls", start: 1, end: 10, wantErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if err := validateRange(tc.kind, tc.start, tc.end); (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	type claim struct {
+		name     string
+		id       uint32
+		selector map[string]string
+		wantID   uint32
+		wantPool string
+		wantErr  error
+	}
+	cases := map[string][]claim{
+		"SpillOver": {
+			{name: "claim-1", wantID: 100, wantPool: "pool-a"},
+			{name: "claim-2", wantID: 101, wantPool: "pool-a"},
+			{name: "claim-3", wantID: 200, wantPool: "pool-b"},
+			{name: "claim-4", wantID: 201, wantPool: "pool-b"},
+			{name: "claim-5", wantErr: ErrPoolExhausted},
+		},
+		"Existing": {
+			{name: "claim-1", wantID: 100, wantPool: "pool-a"},
+			{name: "claim-1", wantID: 100, wantPool: "pool-a"},
+			{name: "claim-1", id: 101, wantErr: ErrConflict},
+		},
+		"RequestedID": {
+			{name: "claim-1", id: 201, wantID: 201, wantPool: "pool-b"},
+			{name: "claim-2", id: 201, wantErr: ErrPoolExhausted},
+			{name: "claim-3", id: 300, wantErr: ErrPoolExhausted},
+		},
+		"Selector": {
+			{name: "claim-1", selector: map[string]string{"pool": "b"}, wantID: 200, wantPool: "pool-b"},
+			{name: "claim-2", selector: map[string]string{"pool": "c"}, wantErr: ErrNotFound},
+		},
+	}
+
+	for name, claims := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			r := New(nil)
+			for _, p := range []struct {
+				name       string
+				label      string
+				start, end uint32
+			}{{"pool-a", "a", 100, 101}, {"pool-b", "b", 200, 201}} {
+				if err := r.Init(ctx, &ipamv1alpha1.ResourcePool{
+					ObjectMeta: metav1.ObjectMeta{Name: p.name, Namespace: "default", Labels: map[string]string{"pool": p.label}},
+					Spec:       ipamv1alpha1.ResourcePoolSpec{Kind: string(ipamv1alpha1.ResourceKindVLAN), Start: p.start, End: p.end},
+				}); err != nil {
+					t.Fatalf("cannot init pool %s: %v", p.name, err)
+				}
+			}
+			for _, c := range claims {
+				allocated, err := r.Allocate(ctx, &Claim{
+					NamespacedName: types.NamespacedName{Namespace: "default", Name: c.name},
+					Kind:           ipamv1alpha1.ResourceKindVLAN,
+					ID:             c.id,
+					SelectorLabels: c.selector,
+				})
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("%s: got error %v, want %v", c.name, err, c.wantErr)
+				}
+				if err != nil {
+					continue
+				}
+				if allocated.ID != c.wantID || allocated.Pool != c.wantPool {
+					t.Errorf("%s: got id %d from %s, want id %d from %s", c.name, allocated.ID, allocated.Pool, c.wantID, c.wantPool)
+				}
+			}
+		})
+	}
+}
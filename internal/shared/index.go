@@ -0,0 +1,72 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	"context"
+
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NetworkInstanceIndex indexes the IPPrefix and IPAllocation resources on the
+// name of their network instance
+const NetworkInstanceIndex = "spec.networkInstance"
+
+// SetupIndexes registers the field indexes the controllers list the resources
+// of a network instance with
+func SetupIndexes(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &ipamv1alpha1.IPPrefix{}, NetworkInstanceIndex, func(o client.Object) []string {
+		cr, ok := o.(*ipamv1alpha1.IPPrefix)
+		if !ok {
+			return nil
+		}
+		return []string{cr.Spec.NetworkInstance}
+	}); err != nil {
+		return err
+	}
+	return indexer.IndexField(ctx, &ipamv1alpha1.IPAllocation{}, NetworkInstanceIndex, func(o client.Object) []string {
+		cr, ok := o.(*ipamv1alpha1.IPAllocation)
+		if !ok || cr.Spec.Selector == nil {
+			return nil
+		}
+		niName, ok := cr.Spec.Selector.MatchLabels[ipamv1alpha1.NephioNetworkInstanceKey]
+		if !ok {
+			return nil
+		}
+		return []string{niName}
+	})
+}
+
+// Conditioned is a resource with conditions
+type Conditioned interface {
+	GetCondition(ck ipamv1alpha1.ConditionKind) ipamv1alpha1.Condition
+}
+
+// IsReady returns true when the Ready condition of the resource is true
+func IsReady(o Conditioned) bool {
+	return o.GetCondition(ipamv1alpha1.ConditionKindReady).Status == corev1.ConditionTrue
+}
+
+// ReadyChanged returns true when the status or reason of the Ready condition
+// differs between the old and the new resource
+func ReadyChanged(oldObj, newObj Conditioned) bool {
+	oc := oldObj.GetCondition(ipamv1alpha1.ConditionKindReady)
+	nc := newObj.GetCondition(ipamv1alpha1.ConditionKindReady)
+	return oc.Status != nc.Status || oc.Reason != nc.Reason
+}
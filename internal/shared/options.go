@@ -21,6 +21,8 @@ import (
 
 	"github.com/nokia/k8s-ipam/internal/injectors"
 	"github.com/nokia/k8s-ipam/internal/ipam"
+	"github.com/nokia/k8s-ipam/internal/resourcepool"
+	"github.com/nokia/k8s-ipam/internal/vrf"
 	"github.com/nokia/k8s-ipam/pkg/alloc/allocpb"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -32,5 +34,7 @@ type Options struct {
 	Poll        time.Duration
 	Copts       controller.Options
 	Ipam        ipam.Ipam
+	Pools       resourcepool.Pools
+	Vrf         vrf.Allocator
 	Injectors   injectors.Injectors
 }
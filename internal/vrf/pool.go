@@ -0,0 +1,81 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vrf
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"inet.af/netaddr"
+)
+
+// pool is a parsed route distinguisher or route target pool
+type pool struct {
+	administrator string
+	start         uint32
+	end           uint32
+}
+
+// parsePool validates the pool against the rfc4364 encodings: a 2-byte asn
+// carries a 4-byte assigned number, a 4-byte asn or an ipv4 address a 2-byte
+// assigned number
+func parsePool(p *ipamv1alpha1.VPNIdentifierPool) (*pool, error) {
+	var administrator string
+	max := uint64(math.MaxUint16)
+	if ip, err := netaddr.ParseIP(p.Administrator); err == nil {
+		if !ip.Is4() {
+			return nil, fmt.Errorf("administrator %s must be an asn or an ipv4 address", p.Administrator)
+		}
+		administrator = ip.String()
+	} else {
+		asn, err := strconv.ParseUint(p.Administrator, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("administrator %s must be an asn or an ipv4 address", p.Administrator)
+		}
+		if asn <= math.MaxUint16 {
+			max = math.MaxUint32
+		}
+		administrator = strconv.FormatUint(asn, 10)
+	}
+	if p.Start == 0 || p.Start > p.End || uint64(p.End) > max {
+		return nil, fmt.Errorf("assigned numbers of %s must be within 1-%d, got %d-%d", administrator, max, p.Start, p.End)
+	}
+	return &pool{
+		administrator: administrator,
+		start:         p.Start,
+		end:           p.End,
+	}, nil
+}
+
+func (r *pool) value(n uint32) string {
+	return fmt.Sprintf("%s:%d", r.administrator, n)
+}
+
+func (r *pool) contains(value string) bool {
+	i := strings.LastIndex(value, ":")
+	if i < 0 || value[:i] != r.administrator {
+		return false
+	}
+	n, err := strconv.ParseUint(value[i+1:], 10, 32)
+	if err != nil {
+		return false
+	}
+	return uint32(n) >= r.start && uint32(n) <= r.end
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vrf
+
+import (
+	"testing"
+
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+)
+
+func TestParsePool(t *testing.T) {
+	cases := map[string]struct {
+		pool              ipamv1alpha1.VPNIdentifierPool
+		wantErr           bool
+		wantAdministrator string
+	}{
+		"TwoByteASN": {
+			pool:              ipamv1alpha1.VPNIdentifierPool{Administrator: "65000", Start: 1, End: 4294967295},
+			wantAdministrator: "65000",
+		},
+		"FourByteASN": {
+			pool:              ipamv1alpha1.VPNIdentifierPool{Administrator: "4200000000", Start: 1, End: 65535},
+			wantAdministrator: "4200000000",
+		},
+		"FourByteASNAssignedNumberTooLarge": {
+			pool:    ipamv1alpha1.VPNIdentifierPool{Administrator: "4200000000", Start: 1, End: 65536},
+			wantErr: true,
+		},
+		"IPv4": {
+			pool:              ipamv1alpha1.VPNIdentifierPool{Administrator: "10.0.0.1", Start: 100, End: 200},
+			wantAdministrator: "10.0.0.1",
+		},
+		"IPv4AssignedNumberTooLarge": {
+			pool:    ipamv1alpha1.VPNIdentifierPool{Administrator: "10.0.0.1", Start: 1, End: 65536},
+			wantErr: true,
+		},
+		"IPv6": {
+			pool:    ipamv1alpha1.VPNIdentifierPool{Administrator: "2001:db8::1", Start: 1, End: 10},
+			wantErr: true,
+		},
+		"ASNTooLarge": {
+			pool:    ipamv1alpha1.VPNIdentifierPool{Administrator: "4294967296", Start: 1, End: 10},
+			wantErr: true,
+		},
+		"NoAdministrator": {
+			pool:    ipamv1alpha1.VPNIdentifierPool{Start: 1, End: 10},
+			wantErr: true,
+		},
+		"ZeroStart": {
+			pool:    ipamv1alpha1.VPNIdentifierPool{Administrator: "65000", Start: 0, End: 10},
+			wantErr: true,
+		},
+		"StartAfterEnd": {
+			pool:    ipamv1alpha1.VPNIdentifierPool{Administrator: "65000", Start: 10, End: 1},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p, err := parsePool(&tc.pool)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if p.administrator != tc.wantAdministrator {
+				t.Errorf("got administrator %s, want %s", p.administrator, tc.wantAdministrator)
+			}
+		})
+	}
+}
+
+func TestPoolContains(t *testing.T) {
+	p := &pool{administrator: "65000", start: 10, end: 20}
+	cases := map[string]struct {
+		value string
+		want  bool
+	}{
+		"Start":              {value: "65000:10", want: true},
+		"End":                {value: "65000:20", want: true},
+		"BeforeStart":        {value: "65000:9", want: false},
+		"AfterEnd":           {value: "65000:21", want: false},
+		"OtherAdministrator": {value: "65001:10", want: false},
+		"NoAssignedNumber":   {value: "65000", want: false},
+		"Invalid":            {value: "65000:a", want: false},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := p.contains(tc.value); got != tc.want {
+				t.Errorf("got %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
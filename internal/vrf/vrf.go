@@ -0,0 +1,262 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package vrf allocates the route distinguisher and route targets of the
+// network instances, keeping them unique across all network instances
+package vrf
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-logr/logr"
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+type Allocator interface {
+	// Allocate allocates the route distinguisher and route targets of the
+	// network instance and records them in the status of the network instance
+	Allocate(ctx context.Context, cr *ipamv1alpha1.NetworkInstance) (*Identifiers, error)
+	// Release releases the identifiers of the network instance
+	Release(niName string)
+	// Get returns the identifiers allocated to the network instance
+	Get(niName string) (*Identifiers, bool)
+}
+
+// Identifiers are the vpn identifiers allocated to a network instance
+type Identifiers struct {
+	RouteDistinguisher string
+	ImportRouteTargets []string
+	ExportRouteTargets []string
+}
+
+func New(c client.Client) Allocator {
+	return &allocator{
+		c:   c,
+		rds: newIDTable("route distinguisher"),
+		rts: newIDTable("route target"),
+	}
+}
+
+type allocator struct {
+	c        client.Client
+	m        sync.Mutex
+	restored bool
+	rds      *idTable
+	rts      *idTable
+
+	l logr.Logger
+}
+
+func (r *allocator) Allocate(ctx context.Context, cr *ipamv1alpha1.NetworkInstance) (*Identifiers, error) {
+	r.l = log.FromContext(ctx)
+
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	// the identifiers of all network instances are restored before the first
+	// allocation, so that a restart never hands out an identifier twice
+	if !r.restored {
+		if err := r.restore(ctx); err != nil {
+			return nil, err
+		}
+		r.restored = true
+	}
+
+	importOwner, exportOwner := getRouteTargetOwners(cr.GetName())
+	prevRD := r.rds.owners[cr.GetName()]
+	prevImportRT := r.rts.owners[importOwner]
+	prevExportRT := r.rts.owners[exportOwner]
+	// rollback restores the identifiers held before the allocation, so a
+	// failed allocation holds nothing new
+	rollback := func() {
+		restoreID(r.rds, cr.GetName(), prevRD)
+		restoreID(r.rts, importOwner, prevImportRT)
+		restoreID(r.rts, exportOwner, prevExportRT)
+	}
+
+	rd, err := r.rds.allocate(cr.GetName(), cr.Spec.RouteDistinguisher, cr.Status.RouteDistinguisher)
+	if err != nil {
+		return nil, err
+	}
+	// the import and export route targets are allocated separately from the
+	// route target pool
+	exportRT, err := r.rts.allocate(exportOwner, cr.Spec.RouteTarget, first(cr.Status.ExportRouteTargets))
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+	importRT, err := r.rts.allocate(importOwner, cr.Spec.RouteTarget, first(cr.Status.ImportRouteTargets))
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+	r.l.Info("vrf action", "action", "allocate", "name", cr.GetName(), "rd", rd, "importRT", importRT, "exportRT", exportRT)
+
+	ids := newIdentifiers(rd, importRT, exportRT)
+	cr.Status.RouteDistinguisher = ids.RouteDistinguisher
+	cr.Status.ImportRouteTargets = ids.ImportRouteTargets
+	cr.Status.ExportRouteTargets = ids.ExportRouteTargets
+	return ids, nil
+}
+
+func (r *allocator) Release(niName string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	importOwner, exportOwner := getRouteTargetOwners(niName)
+	r.rds.release(niName)
+	r.rts.release(importOwner)
+	r.rts.release(exportOwner)
+}
+
+func (r *allocator) Get(niName string) (*Identifiers, bool) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	importOwner, exportOwner := getRouteTargetOwners(niName)
+	rd := r.rds.owners[niName]
+	importRT := r.rts.owners[importOwner]
+	exportRT := r.rts.owners[exportOwner]
+	if rd == "" && importRT == "" && exportRT == "" {
+		return nil, false
+	}
+	return newIdentifiers(rd, importRT, exportRT), true
+}
+
+// restore claims the identifiers recorded in the status of the network instances
+func (r *allocator) restore(ctx context.Context) error {
+	if r.c == nil {
+		return nil
+	}
+	niList := &ipamv1alpha1.NetworkInstanceList{}
+	if err := r.c.List(ctx, niList); err != nil {
+		return errors.Wrap(err, "cannot get network instance list")
+	}
+	for _, ni := range niList.Items {
+		if ni.Status.RouteDistinguisher != "" {
+			r.rds.claim(ni.GetName(), ni.Status.RouteDistinguisher)
+		}
+		importOwner, exportOwner := getRouteTargetOwners(ni.GetName())
+		if len(ni.Status.ImportRouteTargets) > 0 {
+			r.rts.claim(importOwner, ni.Status.ImportRouteTargets[0])
+		}
+		if len(ni.Status.ExportRouteTargets) > 0 {
+			r.rts.claim(exportOwner, ni.Status.ExportRouteTargets[0])
+		}
+	}
+	return nil
+}
+
+// getRouteTargetOwners returns the owners of the import and export route
+// targets of the network instance in the route target table
+func getRouteTargetOwners(niName string) (string, string) {
+	return niName + "/import", niName + "/export"
+}
+
+// restoreID assigns the previous value to the owner again, or releases the
+// value of the owner when it had none
+func restoreID(t *idTable, owner, prev string) {
+	t.release(owner)
+	if prev != "" {
+		t.claim(owner, prev)
+	}
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// newIdentifiers returns the identifiers with the separately allocated
+// import and export route targets
+func newIdentifiers(rd, importRT, exportRT string) *Identifiers {
+	ids := &Identifiers{RouteDistinguisher: rd}
+	if importRT != "" {
+		ids.ImportRouteTargets = []string{importRT}
+	}
+	if exportRT != "" {
+		ids.ExportRouteTargets = []string{exportRT}
+	}
+	return ids
+}
+
+// idTable tracks the allocated values of one identifier type
+type idTable struct {
+	kind string
+	// values maps the allocated value to the network instance owning it
+	values map[string]string
+	// owners maps the network instance to its allocated value
+	owners map[string]string
+}
+
+func newIDTable(kind string) *idTable {
+	return &idTable{
+		kind:   kind,
+		values: map[string]string{},
+		owners: map[string]string{},
+	}
+}
+
+// claim assigns the value to the owner if the value is still free
+func (r *idTable) claim(owner, value string) bool {
+	if o, ok := r.values[value]; ok && o != owner {
+		return false
+	}
+	r.release(owner)
+	r.values[value] = owner
+	r.owners[owner] = value
+	return true
+}
+
+func (r *idTable) release(owner string) {
+	if value, ok := r.owners[owner]; ok {
+		delete(r.values, value)
+		delete(r.owners, owner)
+	}
+}
+
+// allocate returns the value of the owner from the pool; the value the owner
+// already holds or had recorded is kept as long as it is part of the pool
+func (r *idTable) allocate(owner string, spec *ipamv1alpha1.VPNIdentifierPool, current string) (string, error) {
+	if spec == nil {
+		r.release(owner)
+		return "", nil
+	}
+	p, err := parsePool(spec)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid %s pool", r.kind)
+	}
+
+	if value, ok := r.owners[owner]; ok && p.contains(value) {
+		return value, nil
+	}
+	if current != "" && p.contains(current) && r.claim(owner, current) {
+		return current, nil
+	}
+	for n := uint64(p.start); n <= uint64(p.end); n++ {
+		value := p.value(uint32(n))
+		if _, ok := r.values[value]; !ok {
+			r.claim(owner, value)
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("no free %s available in %s:%d-%d", r.kind, p.administrator, p.start, p.end)
+}
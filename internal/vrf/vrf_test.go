@@ -0,0 +1,90 @@
+/*
+Copyright 2022 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vrf
+
+import (
+	"context"
+	"testing"
+
+	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestNetworkInstance(name string, rtEnd uint32) *ipamv1alpha1.NetworkInstance {
+	return &ipamv1alpha1.NetworkInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: ipamv1alpha1.NetworkInstanceSpec{
+			RouteDistinguisher: &ipamv1alpha1.VPNIdentifierPool{Administrator: "65000", Start: 1, End: 10},
+			RouteTarget:        &ipamv1alpha1.VPNIdentifierPool{Administrator: "65000", Start: 1, End: rtEnd},
+		},
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	cases := map[string]struct {
+		// rtEnd is the last route target of the pool starting at 1
+		rtEnd   uint32
+		niNames []string
+		want    map[string]Identifiers
+		wantErr map[string]bool
+	}{
+		"SeparateRouteTargets": {
+			rtEnd:   10,
+			niNames: []string{"vpc-1", "vpc-2"},
+			want: map[string]Identifiers{
+				"vpc-1": {RouteDistinguisher: "65000:1", ImportRouteTargets: []string{"65000:2"}, ExportRouteTargets: []string{"65000:1"}},
+				"vpc-2": {RouteDistinguisher: "65000:2", ImportRouteTargets: []string{"65000:4"}, ExportRouteTargets: []string{"65000:3"}},
+			},
+		},
+		"RouteTargetsExhausted": {
+			rtEnd:   3,
+			niNames: []string{"vpc-1", "vpc-2"},
+			want: map[string]Identifiers{
+				"vpc-1": {RouteDistinguisher: "65000:1", ImportRouteTargets: []string{"65000:2"}, ExportRouteTargets: []string{"65000:1"}},
+			},
+			wantErr: map[string]bool{"vpc-2": true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := New(nil)
+			for _, niName := range tc.niNames {
+				ids, err := r.Allocate(context.Background(), newTestNetworkInstance(niName, tc.rtEnd))
+				if err != nil {
+					if !tc.wantErr[niName] {
+						t.Fatalf("%s: cannot allocate: %v", niName, err)
+					}
+					// a failed allocation holds no identifiers
+					if _, ok := r.Get(niName); ok {
+						t.Errorf("%s: got identifiers after a failed allocation", niName)
+					}
+					continue
+				}
+				if tc.wantErr[niName] {
+					t.Fatalf("%s: got %v, want error", niName, ids)
+				}
+				want := tc.want[niName]
+				if ids.RouteDistinguisher != want.RouteDistinguisher ||
+					first(ids.ImportRouteTargets) != first(want.ImportRouteTargets) ||
+					first(ids.ExportRouteTargets) != first(want.ExportRouteTargets) {
+					t.Errorf("%s: got %v, want %v", niName, *ids, want)
+				}
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"os"
 	"strconv"
 	"time"
@@ -29,21 +30,28 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	porchv1alpha1 "github.com/GoogleContainerTools/kpt/porch/api/porch/v1alpha1"
 	"github.com/nephio-project/nephio-controller-poc/pkg/porch"
 	ipamv1alpha1 "github.com/nokia/k8s-ipam/apis/ipam/v1alpha1"
 	"github.com/nokia/k8s-ipam/controllers"
 	"github.com/nokia/k8s-ipam/internal/allochandler"
+	"github.com/nokia/k8s-ipam/internal/exporthandler"
 	"github.com/nokia/k8s-ipam/internal/grpcserver"
 	"github.com/nokia/k8s-ipam/internal/healthhandler"
+	"github.com/nokia/k8s-ipam/internal/injectors"
 	"github.com/nokia/k8s-ipam/internal/ipam"
+	"github.com/nokia/k8s-ipam/internal/resourcepool"
 	"github.com/nokia/k8s-ipam/internal/shared"
+	"github.com/nokia/k8s-ipam/internal/vrf"
 	"github.com/nokia/k8s-ipam/pkg/alloc/alloc"
 	//+kubebuilder:scaffold:imports
 )
@@ -65,11 +73,17 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var priorityKey string
+	var strategy string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&priorityKey, "priority-label", ipamv1alpha1.NephioPriorityKey,
+		"The label that orders the prefixes a dynamic allocation is allocated from, highest value first.")
+	flag.StringVar(&strategy, "allocation-strategy", string(ipamv1alpha1.AllocationStrategyFirstFit),
+		"How a dynamic allocation selects the free block it is allocated from: first-fit or best-fit.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -78,6 +92,13 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	switch ipamv1alpha1.AllocationStrategy(strategy) {
+	case ipamv1alpha1.AllocationStrategyFirstFit, ipamv1alpha1.AllocationStrategyBestFit:
+	default:
+		setupLog.Error(fmt.Errorf("unknown allocation strategy %s", strategy), "invalid flag")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
@@ -117,12 +138,20 @@ func main() {
 		os.Exit(1)
 	}
 
-	ipam := ipam.New(mgr.GetClient())
+	ipam.RegisterMetrics(metrics.Registry)
+	ipam := ipam.New(mgr.GetClient(),
+		ipam.WithPriorityKey(priorityKey),
+		ipam.WithAllocationStrategy(ipamv1alpha1.AllocationStrategy(strategy)))
+	pools := resourcepool.New(mgr.GetClient())
+	vrfs := vrf.New(mgr.GetClient())
 	// initialize controllers
 	if err := controllers.Setup(mgr, &shared.Options{
 		PorchClient: porchClient,
 		AllocClient: allocClient,
 		Ipam:        ipam,
+		Pools:       pools,
+		Vrf:         vrfs,
+		Injectors:   injectors.New(),
 		Poll:        5 * time.Second,
 		Copts: controller.Options{
 			MaxConcurrentReconciles: 1,
@@ -133,7 +162,9 @@ func main() {
 	}
 
 	ah := allochandler.New(&allochandler.Options{
-		Ipam: ipam,
+		Ipam:  ipam,
+		Pools: pools,
+		Vrf:   vrfs,
 	})
 	wh := healthhandler.New()
 
@@ -143,6 +174,13 @@ func main() {
 	},
 		grpcserver.WithAllocHandler(ah.Allocation),
 		grpcserver.WithDeAllocHandler(ah.DeAllocation),
+		grpcserver.WithGetAllocHandler(ah.GetAllocation),
+		grpcserver.WithListRoutesHandler(ah.ListRoutes),
+		grpcserver.WithExportHandler(ah.Export),
+		grpcserver.WithDefragHandler(ah.Defrag),
+		grpcserver.WithAllocV1alpha2Handler(ah.AllocationV1alpha2),
+		grpcserver.WithDeAllocV1alpha2Handler(ah.DeAllocationV1alpha2),
+		grpcserver.WithGetAllocV1alpha2Handler(ah.GetAllocationV1alpha2),
 		grpcserver.WithWatchHandler(wh.Watch),
 		grpcserver.WithCheckHandler(wh.Check),
 	)
@@ -154,6 +192,27 @@ func main() {
 		}
 	}()
 
+	// serve the prefix hierarchy export next to the metrics
+	if err := mgr.AddMetricsExtraHandler(exporthandler.Path, exporthandler.New(&exporthandler.Options{
+		Ipam: ipam,
+	})); err != nil {
+		setupLog.Error(err, "unable to set up export handler")
+		os.Exit(1)
+	}
+
+	// release the allocations whose lease expired
+	if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		wait.UntilWithContext(ctx, func(ctx context.Context) {
+			if err := ipam.ReleaseExpired(ctx); err != nil {
+				setupLog.Error(err, "cannot release expired allocations")
+			}
+		}, 10*time.Second)
+		return nil
+	})); err != nil {
+		setupLog.Error(err, "unable to set up lease expiry")
+		os.Exit(1)
+	}
+
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -19,23 +19,24 @@ package alloc
 import (
 	"context"
 	"crypto/tls"
-	"fmt"
 	"time"
 
 	"github.com/nokia/k8s-ipam/pkg/alloc/allocpb"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 )
 
 const (
 	defaultTimeout = 30 * time.Second
 	maxMsgSize     = 512 * 1024 * 1024
+	// ClientIDKey is the metadata key that identifies the client
+	ClientIDKey = "client-id"
 )
 
 func CreateClient(c *Config) (allocpb.AllocationClient, error) {
 	var opts []grpc.DialOption
-	fmt.Printf("grpc client config: %v\n", *c)
 	if c.Insecure {
 		//opts = append(opts, grpc.WithInsecure())
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
@@ -46,6 +47,9 @@ func CreateClient(c *Config) (allocpb.AllocationClient, error) {
 		}
 		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
 	}
+	if c.ClientID != "" {
+		opts = append(opts, grpc.WithUnaryInterceptor(clientIDInterceptor(c.ClientID)))
+	}
 	timeoutCtx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
 	defer cancel()
 
@@ -59,6 +63,14 @@ func CreateClient(c *Config) (allocpb.AllocationClient, error) {
 	return client, nil
 }
 
+// clientIDInterceptor adds the client id to the metadata of every request
+func clientIDInterceptor(clientID string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx = metadata.AppendToOutgoingContext(ctx, ClientIDKey, clientID)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
 // newTLS sets up a new TLS profile
 func newTLS(c *Config) (*tls.Config, error) {
 	tlsConfig := &tls.Config{
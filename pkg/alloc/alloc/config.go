@@ -28,4 +28,7 @@ type Config struct {
 	SkipVerify bool
 	Insecure   bool
 	MaxMsgSize int
+	// ClientID identifies the client towards the server, e.g. to count the
+	// allocations of the client against a quota
+	ClientID string
 }
@@ -24,6 +24,10 @@ const _ = grpc.SupportPackageIsVersion7
 type AllocationClient interface {
 	Allocation(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
 	DeAllocation(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	GetAllocation(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	ListRoutes(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (*ExportResponse, error)
+	Defrag(ctx context.Context, in *DefragRequest, opts ...grpc.CallOption) (*DefragResponse, error)
 }
 
 type allocationClient struct {
@@ -52,12 +56,52 @@ func (c *allocationClient) DeAllocation(ctx context.Context, in *Request, opts .
 	return out, nil
 }
 
+func (c *allocationClient) GetAllocation(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/alloc.Allocation/GetAllocation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *allocationClient) ListRoutes(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, "/alloc.Allocation/ListRoutes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *allocationClient) Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (*ExportResponse, error) {
+	out := new(ExportResponse)
+	err := c.cc.Invoke(ctx, "/alloc.Allocation/Export", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *allocationClient) Defrag(ctx context.Context, in *DefragRequest, opts ...grpc.CallOption) (*DefragResponse, error) {
+	out := new(DefragResponse)
+	err := c.cc.Invoke(ctx, "/alloc.Allocation/Defrag", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AllocationServer is the server API for Allocation service.
 // All implementations must embed UnimplementedAllocationServer
 // for forward compatibility
 type AllocationServer interface {
 	Allocation(context.Context, *Request) (*Response, error)
 	DeAllocation(context.Context, *Request) (*Response, error)
+	GetAllocation(context.Context, *Request) (*Response, error)
+	ListRoutes(context.Context, *ListRequest) (*ListResponse, error)
+	Export(context.Context, *ExportRequest) (*ExportResponse, error)
+	Defrag(context.Context, *DefragRequest) (*DefragResponse, error)
 	mustEmbedUnimplementedAllocationServer()
 }
 
@@ -71,6 +115,18 @@ func (UnimplementedAllocationServer) Allocation(context.Context, *Request) (*Res
 func (UnimplementedAllocationServer) DeAllocation(context.Context, *Request) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeAllocation not implemented")
 }
+func (UnimplementedAllocationServer) GetAllocation(context.Context, *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAllocation not implemented")
+}
+func (UnimplementedAllocationServer) ListRoutes(context.Context, *ListRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRoutes not implemented")
+}
+func (UnimplementedAllocationServer) Export(context.Context, *ExportRequest) (*ExportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Export not implemented")
+}
+func (UnimplementedAllocationServer) Defrag(context.Context, *DefragRequest) (*DefragResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Defrag not implemented")
+}
 func (UnimplementedAllocationServer) mustEmbedUnimplementedAllocationServer() {}
 
 // UnsafeAllocationServer may be embedded to opt out of forward compatibility for this service.
@@ -120,6 +176,78 @@ func _Allocation_DeAllocation_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Allocation_GetAllocation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AllocationServer).GetAllocation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/alloc.Allocation/GetAllocation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AllocationServer).GetAllocation(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Allocation_ListRoutes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AllocationServer).ListRoutes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/alloc.Allocation/ListRoutes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AllocationServer).ListRoutes(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Allocation_Export_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AllocationServer).Export(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/alloc.Allocation/Export",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AllocationServer).Export(ctx, req.(*ExportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Allocation_Defrag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DefragRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AllocationServer).Defrag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/alloc.Allocation/Defrag",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AllocationServer).Defrag(ctx, req.(*DefragRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Allocation_ServiceDesc is the grpc.ServiceDesc for Allocation service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -135,6 +263,22 @@ var Allocation_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeAllocation",
 			Handler:    _Allocation_DeAllocation_Handler,
 		},
+		{
+			MethodName: "GetAllocation",
+			Handler:    _Allocation_GetAllocation_Handler,
+		},
+		{
+			MethodName: "ListRoutes",
+			Handler:    _Allocation_ListRoutes_Handler,
+		},
+		{
+			MethodName: "Export",
+			Handler:    _Allocation_Export_Handler,
+		},
+		{
+			MethodName: "Defrag",
+			Handler:    _Allocation_Defrag_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/alloc/allocpb/alloc.proto",